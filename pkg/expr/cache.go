@@ -0,0 +1,209 @@
+package expr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+var queryCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana",
+	Subsystem: "expressions",
+	Name:      "query_cache_requests_total",
+	Help:      "Number of datasource queries made by expressions, by whether they were served from the cache (hit), shared with an identical concurrent query (shared) or executed (miss).",
+}, []string{"result"})
+
+// queryCache caches the responses of the datasource queries made by DSNodes,
+// and makes sure that identical queries that run at the same time are only
+// executed once.
+//
+// Queries are identical when they are for the same org, datasource, query model
+// and time range, where the time range is rounded down to the cache ttl so that
+// alert rules that are evaluated at slightly different times share a response.
+type queryCache struct {
+	ttl   time.Duration
+	cache *localcache.CacheService
+	group singleflight.Group
+}
+
+func newQueryCache(ttl time.Duration) *queryCache {
+	qc := &queryCache{ttl: ttl}
+	if ttl > 0 {
+		qc.cache = localcache.New(ttl, 2*ttl)
+	}
+	return qc
+}
+
+// sharedQueryTimeout is the maximum time of a query that is shared by identical queries. The
+// query is not cancelled when one of its callers is, so that it does not fail for the others.
+const sharedQueryTimeout = 5 * time.Minute
+
+// cachedResponse is a copy of a backend.QueryDataResponse with the frames
+// encoded, so that every reader gets its own copy of the frames.
+type cachedResponse struct {
+	// refID is the refID of the query that made the response. The refIDs are not part of
+	// the cache key, so the response is given the refID of each reader.
+	refID     string
+	responses map[string]cachedDataResponse
+}
+
+type cachedDataResponse struct {
+	frames [][]byte
+	err    error
+}
+
+// queryData returns the response of the datasource query in req made by dn, from
+// the cache if possible.
+func (s *Service) queryData(ctx context.Context, dn *DSNode, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if s.queryCache == nil {
+		return s.dataService.QueryData(ctx, req)
+	}
+	qc := s.queryCache
+
+	key, err := qc.key(dn)
+	if err != nil {
+		return nil, err
+	}
+
+	if qc.cache != nil {
+		if cached, ok := qc.cache.Get(key); ok {
+			queryCacheRequests.WithLabelValues("hit").Inc()
+			return cached.(*cachedResponse).decode(dn.refID)
+		}
+	}
+
+	ch := qc.group.DoChan(key, func() (interface{}, error) {
+		// The query is shared by the callers that join it, so it must not be cancelled
+		// with the caller that started it.
+		queryCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, sharedQueryTimeout)
+		defer cancel()
+		resp, err := s.dataService.QueryData(queryCtx, req)
+		if err != nil {
+			return nil, err
+		}
+		cached, err := encodeResponse(dn.refID, resp)
+		if err != nil {
+			return nil, err
+		}
+		if qc.cache != nil && cached.cacheable() {
+			qc.cache.Set(key, cached, qc.ttl)
+		}
+		return cached, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			queryCacheRequests.WithLabelValues("shared").Inc()
+		} else {
+			queryCacheRequests.WithLabelValues("miss").Inc()
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*cachedResponse).decode(dn.refID)
+	}
+}
+
+// detachedContext is a context with the values of its parent, but that is never cancelled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// key returns the cache key for the query of dn.
+func (qc *queryCache) key(dn *DSNode) (string, error) {
+	model := make(map[string]interface{})
+	if err := json.Unmarshal(dn.query, &model); err != nil {
+		return "", err
+	}
+	// The refId of a query does not change its result.
+	delete(model, "refId")
+	normalizedQuery, err := json.Marshal(model)
+	if err != nil {
+		return "", err
+	}
+
+	from, to := dn.timeRange.From, dn.timeRange.To
+	if qc.ttl > 0 {
+		from, to = from.Truncate(qc.ttl), to.Truncate(qc.ttl)
+	}
+
+	headers := make([]string, 0, len(dn.request.Headers))
+	for k, v := range dn.request.Headers {
+		headers = append(headers, k+"="+v)
+	}
+	sort.Strings(headers)
+
+	h := sha256.New()
+	_, err = fmt.Fprintf(h, "%d\n%s\n%d\n%s\n%s\n%d\n%d\n%d\n%d\n%v\n",
+		dn.orgID, dn.datasource.Uid, dn.datasource.Version, normalizedQuery, dn.queryType,
+		dn.intervalMS, dn.maxDP, from.UnixNano(), to.UnixNano(), headers)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func encodeResponse(refID string, resp *backend.QueryDataResponse) (*cachedResponse, error) {
+	cached := &cachedResponse{refID: refID, responses: make(map[string]cachedDataResponse, len(resp.Responses))}
+	for refID, dr := range resp.Responses {
+		frames, err := dr.Frames.MarshalArrow()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response of query %s: %w", refID, err)
+		}
+		cached.responses[refID] = cachedDataResponse{frames: frames, err: dr.Error}
+	}
+	return cached, nil
+}
+
+// cacheable returns false if any of the responses is an error.
+func (c *cachedResponse) cacheable() bool {
+	for _, dr := range c.responses {
+		if dr.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// decode returns a copy of the response for the query with the given refID.
+func (c *cachedResponse) decode(refID string) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for respRefID, cdr := range c.responses {
+		frames, err := data.UnmarshalArrowFrames(cdr.frames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response of query %s: %w", respRefID, err)
+		}
+		if respRefID == c.refID {
+			respRefID = refID
+		}
+		for _, frame := range frames {
+			if frame.RefID == c.refID {
+				frame.RefID = refID
+			}
+		}
+		resp.Responses[respRefID] = backend.DataResponse{Frames: frames, Error: cdr.err}
+	}
+	return resp, nil
+}
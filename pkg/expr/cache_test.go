@@ -0,0 +1,200 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type countingEndpoint struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (ce *countingEndpoint) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	atomic.AddInt32(&ce.calls, 1)
+	if ce.release != nil {
+		select {
+		case <-ce.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	refID := req.Queries[0].RefID
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", nil, []*float64{fp(2)}))
+	frame.RefID = refID
+	resp := backend.NewQueryDataResponse()
+	resp.Responses[refID] = backend.DataResponse{Frames: data.Frames{frame}}
+	return resp, nil
+}
+
+func newCacheTestService(t *testing.T, ttl time.Duration, endpoint backend.QueryDataHandler) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.ExpressionsQueryCacheTTL = ttl
+	return &Service{
+		cfg:            cfg,
+		dataService:    endpoint,
+		secretsService: secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()),
+		queryCache:     newQueryCache(ttl),
+	}
+}
+
+func cacheTestRequest(refID string, from time.Time) *Request {
+	return &Request{
+		Queries: []Query{
+			{
+				RefID: refID,
+				DataSource: &models.DataSource{
+					OrgId: 1,
+					Uid:   "test",
+					Type:  "test",
+				},
+				JSON:      json.RawMessage(`{ "datasource": { "uid": "test" }, "refId": "` + refID + `", "expr": "up" }`),
+				TimeRange: TimeRange{From: from, To: from.Add(time.Hour)},
+			},
+		},
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	now := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	execute := func(t *testing.T, s *Service, req *Request) *backend.QueryDataResponse {
+		t.Helper()
+		pl, err := s.BuildPipeline(req)
+		require.NoError(t, err)
+		res, err := s.ExecutePipeline(context.Background(), pl)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("identical queries in the same time bucket are served from the cache", func(t *testing.T) {
+		endpoint := &countingEndpoint{}
+		s := newCacheTestService(t, time.Minute, endpoint)
+
+		first := execute(t, s, cacheTestRequest("A", now))
+		second := execute(t, s, cacheTestRequest("B", now.Add(10*time.Second)))
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&endpoint.calls))
+		require.Equal(t, "A", first.Responses["A"].Frames[0].RefID)
+		require.Equal(t, "B", second.Responses["B"].Frames[0].RefID)
+	})
+
+	t.Run("queries in a different time bucket are not served from the cache", func(t *testing.T) {
+		endpoint := &countingEndpoint{}
+		s := newCacheTestService(t, time.Minute, endpoint)
+
+		execute(t, s, cacheTestRequest("A", now))
+		execute(t, s, cacheTestRequest("A", now.Add(time.Minute)))
+
+		require.Equal(t, int32(2), atomic.LoadInt32(&endpoint.calls))
+	})
+
+	t.Run("queries are not cached when the ttl is zero", func(t *testing.T) {
+		endpoint := &countingEndpoint{}
+		s := newCacheTestService(t, 0, endpoint)
+
+		execute(t, s, cacheTestRequest("A", now))
+		execute(t, s, cacheTestRequest("A", now))
+
+		require.Equal(t, int32(2), atomic.LoadInt32(&endpoint.calls))
+	})
+
+	t.Run("concurrent identical queries are executed once", func(t *testing.T) {
+		endpoint := &countingEndpoint{release: make(chan struct{})}
+		s := newCacheTestService(t, 0, endpoint)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				execute(t, s, cacheTestRequest("A", now))
+			}()
+		}
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&endpoint.calls) == 1
+		}, time.Second, 10*time.Millisecond)
+		// give the other goroutines time to join the in-flight query
+		time.Sleep(50 * time.Millisecond)
+		close(endpoint.release)
+		wg.Wait()
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&endpoint.calls))
+	})
+
+	t.Run("responses served from the cache have the refID of the query", func(t *testing.T) {
+		endpoint := &countingEndpoint{}
+		s := newCacheTestService(t, time.Minute, endpoint)
+
+		query := func(refID string) *backend.QueryDataResponse {
+			pl, err := s.BuildPipeline(cacheTestRequest(refID, now))
+			require.NoError(t, err)
+			dn := pl[0].(*DSNode)
+			resp, err := s.queryData(context.Background(), dn, &backend.QueryDataRequest{
+				Queries: []backend.DataQuery{{RefID: refID, JSON: dn.query}},
+			})
+			require.NoError(t, err)
+			return resp
+		}
+
+		first := query("A")
+		second := query("B")
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&endpoint.calls))
+		require.Contains(t, first.Responses, "A")
+		require.Equal(t, "A", first.Responses["A"].Frames[0].RefID)
+		require.Len(t, second.Responses, 1)
+		require.Contains(t, second.Responses, "B")
+		require.Equal(t, "B", second.Responses["B"].Frames[0].RefID)
+	})
+
+	t.Run("shared queries are not cancelled with the caller that started them", func(t *testing.T) {
+		endpoint := &countingEndpoint{release: make(chan struct{})}
+		s := newCacheTestService(t, 0, endpoint)
+
+		pl, err := s.BuildPipeline(cacheTestRequest("A", now))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := s.ExecutePipeline(ctx, pl)
+			firstErr <- err
+		}()
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&endpoint.calls) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		second := make(chan *backend.QueryDataResponse, 1)
+		go func() {
+			second <- execute(t, s, cacheTestRequest("A", now))
+		}()
+		// give the second query time to join the in-flight query
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-firstErr, context.Canceled)
+
+		close(endpoint.release)
+		res := <-second
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		require.Equal(t, int32(1), atomic.LoadInt32(&endpoint.calls))
+	})
+}
@@ -45,7 +45,7 @@ type ConditionEvalJSON struct {
 type condition struct {
 	QueryRefID string
 	Reducer    classicReducer
-	Evaluator  evaluator
+	Evaluator  Evaluator
 	Operator   string
 }
 
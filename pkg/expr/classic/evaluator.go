@@ -6,7 +6,8 @@ import (
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
-type evaluator interface {
+// Evaluator evaluates a reduced value, returning true when the condition is met.
+type Evaluator interface {
 	Eval(mathexp.Number) bool
 }
 
@@ -23,9 +24,15 @@ type rangedEvaluator struct {
 	Upper float64
 }
 
+// NewEvaluator returns an Evaluator for the evaluation operator in model.
+// It allows other expression commands to reuse the classic condition evaluators.
+func NewEvaluator(model ConditionEvalJSON) (Evaluator, error) {
+	return newAlertEvaluator(model)
+}
+
 // newAlertEvaluator is a factory function for returning
 // an AlertEvaluator depending on evaluation operator.
-func newAlertEvaluator(model ConditionEvalJSON) (evaluator, error) {
+func newAlertEvaluator(model ConditionEvalJSON) (Evaluator, error) {
 	switch model.Type {
 	case "gt", "lt":
 		return newThresholdEvaluator(model)
@@ -11,7 +11,7 @@ import (
 func TestThresholdEvaluator(t *testing.T) {
 	var tests = []struct {
 		name        string
-		evaluator   evaluator
+		evaluator   Evaluator
 		inputNumber mathexp.Number
 		expected    bool
 	}{
@@ -51,7 +51,7 @@ func TestThresholdEvaluator(t *testing.T) {
 func TestRangedEvaluator(t *testing.T) {
 	var tests = []struct {
 		name        string
-		evaluator   evaluator
+		evaluator   Evaluator
 		inputNumber mathexp.Number
 		expected    bool
 	}{
@@ -117,7 +117,7 @@ func TestRangedEvaluator(t *testing.T) {
 func TestNoValueEvaluator(t *testing.T) {
 	var tests = []struct {
 		name        string
-		evaluator   evaluator
+		evaluator   Evaluator
 		inputNumber mathexp.Number
 		expected    bool
 	}{
@@ -262,6 +262,85 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// AggregateCommand is an expression command that combines the series or numbers
+// that have the same labels for a set of labels, such as a sum by cluster.
+type AggregateCommand struct {
+	Aggregator     string
+	By             []string
+	VarToAggregate string
+	refID          string
+}
+
+// NewAggregateCommand creates a new AggregateCommand.
+func NewAggregateCommand(refID, aggregator string, by []string, varToAggregate string) (*AggregateCommand, error) {
+	_, err := mathexp.GetAggregateFunc(aggregator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AggregateCommand{
+		Aggregator:     aggregator,
+		By:             by,
+		VarToAggregate: varToAggregate,
+		refID:          refID,
+	}, nil
+}
+
+// UnmarshalAggregateCommand creates an AggregateCommand from Grafana's frontend query.
+func UnmarshalAggregateCommand(rn *rawNode) (*AggregateCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to aggregate for refId %v", rn.RefID)
+	}
+	varToAggregate, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected aggregate variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToAggregate = strings.TrimPrefix(varToAggregate, "$")
+
+	rawAggregator, ok := rn.Query["aggregator"]
+	if !ok {
+		return nil, fmt.Errorf("no aggregator specified for refId %v", rn.RefID)
+	}
+	aggregator, ok := rawAggregator.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected aggregator to be a string, got %T for refId %v", rawAggregator, rn.RefID)
+	}
+
+	var by []string
+	if rawBy, ok := rn.Query["by"]; ok && rawBy != nil {
+		labels, ok := rawBy.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate by to be a list of labels, got %T for refId %v", rawBy, rn.RefID)
+		}
+		for _, l := range labels {
+			label, ok := l.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected aggregate by label to be a string, got %T for refId %v", l, rn.RefID)
+			}
+			by = append(by, label)
+		}
+	}
+
+	return NewAggregateCommand(rn.RefID, aggregator, by, varToAggregate)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ga *AggregateCommand) NeedsVars() []string {
+	return []string{ga.VarToAggregate}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ga *AggregateCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	vals, err := mathexp.Aggregate(ga.refID, ga.Aggregator, ga.By, vars[ga.VarToAggregate].Values)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -276,6 +355,12 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for checking if a threshold has been crossed.
+	TypeThreshold
+	// TypeHysteresis is the CMDType for checking if a threshold has been crossed, with a separate threshold for recovery.
+	TypeHysteresis
+	// TypeAggregate is the CMDType for an aggregation across series, such as a sum by a label.
+	TypeAggregate
 )
 
 func (gt CommandType) String() string {
@@ -288,6 +373,12 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeHysteresis:
+		return "hysteresis"
+	case TypeAggregate:
+		return "aggregate"
 	default:
 		return "unknown"
 	}
@@ -304,6 +395,12 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
+	case "hysteresis":
+		return TypeHysteresis, nil
+	case "aggregate":
+		return TypeAggregate, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -89,3 +89,104 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 		})
 	}
 }
+
+func Test_UnmarshalReduceCommand_Reducer(t *testing.T) {
+	var tests = []struct {
+		reducer string
+		isError bool
+	}{
+		{reducer: "median"},
+		{reducer: "p95"},
+		{reducer: "p99.9"},
+		{reducer: "stddev"},
+		{reducer: "first"},
+		{reducer: "diff"},
+		{reducer: "diff_abs"},
+		{reducer: "percent_diff"},
+		{reducer: "count_non_null"},
+		{reducer: "p101", isError: true},
+		{reducer: "pxx", isError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.reducer, func(t *testing.T) {
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID: "A",
+				Query: map[string]interface{}{"expression": "$B", "reducer": test.reducer},
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.reducer, cmd.Reducer)
+		})
+	}
+}
+
+func Test_UnmarshalAggregateCommand(t *testing.T) {
+	var tests = []struct {
+		name        string
+		query       string
+		isError     bool
+		expectedCmd *AggregateCommand
+	}{
+		{
+			name:  "sum by labels",
+			query: `{ "expression": "$A", "aggregator": "sum", "by": ["cluster", "region"] }`,
+			expectedCmd: &AggregateCommand{
+				Aggregator:     "sum",
+				By:             []string{"cluster", "region"},
+				VarToAggregate: "A",
+				refID:          "B",
+			},
+		},
+		{
+			name:  "count without labels",
+			query: `{ "expression": "$A", "aggregator": "count" }`,
+			expectedCmd: &AggregateCommand{
+				Aggregator:     "count",
+				VarToAggregate: "A",
+				refID:          "B",
+			},
+		},
+		{
+			name:    "unknown aggregator",
+			query:   `{ "expression": "$A", "aggregator": "last" }`,
+			isError: true,
+		},
+		{
+			name:    "by is not a list",
+			query:   `{ "expression": "$A", "aggregator": "sum", "by": "cluster" }`,
+			isError: true,
+		},
+		{
+			name:    "no aggregator",
+			query:   `{ "expression": "$A" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalAggregateCommand(&rawNode{
+				RefID: "B",
+				Query: qmap,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedCmd, cmd)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// Explanation describes how each node of a DataPipeline was executed.
+type Explanation struct {
+	Nodes []NodeExplanation `json:"nodes"`
+}
+
+// NodeExplanation describes the execution of a single node of a DataPipeline.
+type NodeExplanation struct {
+	RefID string `json:"refId"`
+	// NodeType is either Datasource or Expression.
+	NodeType string `json:"nodeType"`
+	// CommandType is the type of the expression command, such as math or reduce.
+	CommandType string `json:"commandType,omitempty"`
+	// Order is the position of the node in the execution order, starting at 0.
+	Order int `json:"order"`
+	// Dependencies are the refIds of the nodes that the node needs.
+	Dependencies []string `json:"dependencies"`
+	// InputTypes is the type of the result of each dependency.
+	InputTypes map[string]string `json:"inputTypes,omitempty"`
+	// OutputType is the type of the result of the node: Number, Series, Scalar, Table or NoData.
+	OutputType string `json:"outputType,omitempty"`
+	// SeriesCount is the number of values, such as series or numbers, in the result of the node.
+	SeriesCount int `json:"seriesCount"`
+	// Labels are the labels of each value in the result of the node.
+	Labels []data.Labels `json:"labels,omitempty"`
+	// DurationMs is the time it took to execute the node, in milliseconds.
+	DurationMs float64 `json:"durationMs"`
+	// Error is the error of the node, if it failed or could not be executed.
+	Error string `json:"error,omitempty"`
+}
+
+// Explain builds the pipeline of req and executes it, describing the execution
+// of every node. Unlike TransformData, the execution does not stop at the first
+// error: nodes that depend on a failed node are reported as not executed.
+func (s *Service) Explain(ctx context.Context, req *Request) (*Explanation, error) {
+	if s.isDisabled() {
+		return nil, fmt.Errorf("server side expressions are disabled")
+	}
+
+	pipeline, err := s.BuildPipeline(req)
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.explain(ctx, s), nil
+}
+
+// explain runs the nodes of the pipeline one at a time, in pipeline order, so
+// that the duration of each node is not affected by other nodes.
+func (dp *DataPipeline) explain(ctx context.Context, s *Service) *Explanation {
+	vars := make(mathexp.Vars, len(*dp))
+	failed := make(map[string]bool)
+	explanation := &Explanation{Nodes: make([]NodeExplanation, 0, len(*dp))}
+
+	for i, node := range *dp {
+		ne := NodeExplanation{
+			RefID:        node.RefID(),
+			NodeType:     node.NodeType().String(),
+			Order:        i,
+			Dependencies: []string{},
+		}
+		if cmdNode, ok := node.(*CMDNode); ok {
+			ne.CommandType = cmdNode.CMDType.String()
+			if deps := cmdNode.Command.NeedsVars(); deps != nil {
+				ne.Dependencies = deps
+			}
+		}
+
+		var failedDeps []string
+		nodeVars := make(mathexp.Vars, len(ne.Dependencies))
+		for _, dep := range ne.Dependencies {
+			if failed[dep] {
+				failedDeps = append(failedDeps, dep)
+				continue
+			}
+			if ne.InputTypes == nil {
+				ne.InputTypes = make(map[string]string, len(ne.Dependencies))
+			}
+			ne.InputTypes[dep] = resultsType(vars[dep])
+			nodeVars[dep] = vars[dep]
+		}
+		if len(failedDeps) > 0 {
+			failed[node.RefID()] = true
+			ne.Error = fmt.Sprintf("not executed because dependencies failed: %s", strings.Join(failedDeps, ", "))
+			explanation.Nodes = append(explanation.Nodes, ne)
+			continue
+		}
+
+		start := time.Now()
+		res, err := node.Execute(ctx, nodeVars, s)
+		ne.DurationMs = float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond)
+		if err != nil {
+			failed[node.RefID()] = true
+			ne.Error = err.Error()
+			explanation.Nodes = append(explanation.Nodes, ne)
+			continue
+		}
+
+		vars[node.RefID()] = res
+		ne.OutputType = resultsType(res)
+		ne.SeriesCount = len(res.Values)
+		for _, val := range res.Values {
+			ne.Labels = append(ne.Labels, val.GetLabels())
+		}
+		explanation.Nodes = append(explanation.Nodes, ne)
+	}
+	return explanation
+}
+
+// resultsType returns the types of the values of res, separated by commas, or
+// NoData if res has no values.
+func resultsType(res mathexp.Results) string {
+	if len(res.Values) == 0 {
+		return "NoData"
+	}
+	seen := make(map[string]bool)
+	var types []string
+	for _, val := range res.Values {
+		var t string
+		switch val.(type) {
+		case mathexp.Number:
+			t = "Number"
+		case mathexp.Series:
+			t = "Series"
+		case mathexp.Scalar:
+			t = "Scalar"
+		case mathexp.Table:
+			t = "Table"
+		default:
+			t = val.Type().String()
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return strings.Join(types, ",")
+}
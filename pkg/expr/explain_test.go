@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestServiceExplain(t *testing.T) {
+	me := &mockEndpoint{
+		Frames: []*data.Frame{
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+				data.NewField("value", data.Labels{"host": "a"}, []*float64{fp(2), fp(4)})),
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+				data.NewField("value", data.Labels{"host": "b"}, []*float64{fp(6), fp(8)})),
+		},
+	}
+
+	cfg := setting.NewCfg()
+	cfg.ExpressionsEnabled = true
+	s := Service{
+		cfg:            cfg,
+		dataService:    me,
+		secretsService: secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()),
+	}
+
+	expression := func(refID, model string) Query {
+		return Query{
+			RefID:      refID,
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(model),
+		}
+	}
+	req := &Request{Queries: []Query{
+		{
+			RefID: "A",
+			DataSource: &models.DataSource{
+				OrgId: 1,
+				Uid:   "test",
+				Type:  "test",
+			},
+			JSON: json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		expression("B", `{ "type": "reduce", "expression": "$A", "reducer": "last" }`),
+		expression("C", `{ "type": "math", "expression": "$B > 5" }`),
+		expression("D", `{ "type": "resample", "expression": "$C", "window": "1m", "downsampler": "last", "upsampler": "pad" }`),
+		expression("E", `{ "type": "math", "expression": "$D * 2" }`),
+	}}
+
+	explanation, err := s.Explain(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, explanation.Nodes, 5)
+
+	nodes := make(map[string]NodeExplanation, len(explanation.Nodes))
+	for i, ne := range explanation.Nodes {
+		require.Equal(t, i, ne.Order)
+		nodes[ne.RefID] = ne
+	}
+
+	a := nodes["A"]
+	require.Equal(t, "Datasource", a.NodeType)
+	require.Empty(t, a.Dependencies)
+	require.Equal(t, "Series", a.OutputType)
+	require.Equal(t, 2, a.SeriesCount)
+	require.Equal(t, []data.Labels{{"host": "a"}, {"host": "b"}}, a.Labels)
+	require.Empty(t, a.Error)
+
+	b := nodes["B"]
+	require.Equal(t, "Expression", b.NodeType)
+	require.Equal(t, "reduce", b.CommandType)
+	require.Equal(t, []string{"A"}, b.Dependencies)
+	require.Equal(t, map[string]string{"A": "Series"}, b.InputTypes)
+	require.Equal(t, "Number", b.OutputType)
+	require.Equal(t, 2, b.SeriesCount)
+
+	c := nodes["C"]
+	require.Equal(t, map[string]string{"B": "Number"}, c.InputTypes)
+	require.Equal(t, "Number", c.OutputType)
+
+	// resample fails on numbers, and the node that needs it is not executed.
+	d := nodes["D"]
+	require.NotEmpty(t, d.Error)
+	require.Empty(t, d.OutputType)
+
+	e := nodes["E"]
+	require.Equal(t, "not executed because dependencies failed: D", e.Error)
+	require.Zero(t, e.DurationMs)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 
@@ -44,19 +46,99 @@ type Node interface {
 // DataPipeline is an ordered set of nodes returned from DPGraph processing.
 type DataPipeline []Node
 
+// nodeResult is the outcome of executing a single node of a DataPipeline.
+type nodeResult struct {
+	node     Node
+	results  mathexp.Results
+	duration time.Duration
+	err      error
+}
+
 // execute runs all the command/datasource requests in the pipeline return a
-// map of the refId of the of each command
-func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, error) {
-	vars := make(mathexp.Vars)
-	for _, node := range *dp {
-		res, err := node.Execute(c, vars, s)
-		if err != nil {
-			return nil, err
+// map of the refId of the of each command, and a map of the refId to the
+// time it took to execute each command.
+//
+// Nodes are started in pipeline order as soon as the nodes they depend on have
+// finished, with at most maxConcurrentNodes nodes running at the same time.
+func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, map[string]time.Duration, error) {
+	nodes := *dp
+	vars := make(mathexp.Vars, len(nodes))
+	durations := make(map[string]time.Duration, len(nodes))
+
+	order := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		order[node.RefID()] = i
+	}
+
+	needs := make(map[string][]string, len(nodes))
+	pending := make(map[string]int, len(nodes))
+	dependents := make(map[string][]Node, len(nodes))
+	var ready []Node
+	for _, node := range nodes {
+		if cmdNode, ok := node.(*CMDNode); ok {
+			needs[node.RefID()] = cmdNode.Command.NeedsVars()
+		}
+		for _, neededVar := range needs[node.RefID()] {
+			dependents[neededVar] = append(dependents[neededVar], node)
+			pending[node.RefID()]++
+		}
+		if pending[node.RefID()] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	limit := s.maxConcurrentNodes()
+	// buffered so that nodes still running after an error do not block
+	results := make(chan nodeResult, len(nodes))
+	running := 0
+	for done := 0; done < len(nodes); done++ {
+		for len(ready) > 0 && running < limit {
+			node := ready[0]
+			ready = ready[1:]
+
+			// each node gets its own vars with only the results it needs,
+			// so that vars is never read and written at the same time.
+			nodeVars := make(mathexp.Vars, len(needs[node.RefID()]))
+			for _, neededVar := range needs[node.RefID()] {
+				nodeVars[neededVar] = vars[neededVar]
+			}
+
+			running++
+			go func(node Node, nodeVars mathexp.Vars) {
+				start := time.Now()
+				res, err := node.Execute(ctx, nodeVars, s)
+				results <- nodeResult{node: node, results: res, duration: time.Since(start), err: err}
+			}(node, nodeVars)
+		}
+
+		if running == 0 {
+			return nil, nil, fmt.Errorf("unable to execute pipeline: no executable nodes left")
 		}
 
-		vars[node.RefID()] = res
+		r := <-results
+		running--
+		if r.err != nil {
+			return nil, nil, r.err
+		}
+
+		refID := r.node.RefID()
+		vars[refID] = r.results
+		durations[refID] = r.duration
+
+		for _, dependent := range dependents[refID] {
+			pending[dependent.RefID()]--
+			if pending[dependent.RefID()] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		sort.SliceStable(ready, func(i, j int) bool {
+			return order[ready[i].RefID()] < order[ready[j].RefID()]
+		})
 	}
-	return vars, nil
+	return vars, durations, nil
 }
 
 // BuildPipeline builds a graph of the nodes, and returns the nodes in an
@@ -208,6 +290,16 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 
 			dp.SetEdge(edge)
 		}
+
+		// The results of data source queries are only returned as tables when a table function
+		// uses them, as the other commands do not accept tables.
+		if mathCmd, ok := cmdNode.Command.(*MathCommand); ok {
+			for _, tableVar := range mathCmd.Expression.TableVars() {
+				if dsNode, ok := registry[tableVar].(*DSNode); ok {
+					dsNode.tables = true
+				}
+			}
+		}
 	}
 	return nil
 }
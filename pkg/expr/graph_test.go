@@ -1,10 +1,16 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
 )
 
@@ -248,3 +254,89 @@ func getRefIDOrder(nodes []Node) []string {
 	}
 	return ids
 }
+
+type trackingCommand struct {
+	needs   []string
+	tracker *concurrencyTracker
+}
+
+func (tc *trackingCommand) NeedsVars() []string {
+	return tc.needs
+}
+
+func (tc *trackingCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	for _, v := range tc.needs {
+		if _, ok := vars[v]; !ok {
+			return mathexp.Results{}, fmt.Errorf("missing var %s", v)
+		}
+	}
+	tc.tracker.enter()
+	defer tc.tracker.exit()
+	time.Sleep(20 * time.Millisecond)
+	return mathexp.NewScalarResults("", fp(1)), nil
+}
+
+type concurrencyTracker struct {
+	mtx     sync.Mutex
+	current int
+	max     int
+}
+
+func (ct *concurrencyTracker) enter() {
+	ct.mtx.Lock()
+	defer ct.mtx.Unlock()
+	ct.current++
+	if ct.current > ct.max {
+		ct.max = ct.current
+	}
+}
+
+func (ct *concurrencyTracker) exit() {
+	ct.mtx.Lock()
+	defer ct.mtx.Unlock()
+	ct.current--
+}
+
+func TestDataPipelineExecute(t *testing.T) {
+	newPipeline := func(tracker *concurrencyTracker) DataPipeline {
+		node := func(id int64, refID string, needs ...string) *CMDNode {
+			return &CMDNode{
+				baseNode: baseNode{id: id, refID: refID},
+				CMDType:  TypeMath,
+				Command:  &trackingCommand{needs: needs, tracker: tracker},
+			}
+		}
+		return DataPipeline{
+			node(0, "A"),
+			node(1, "B"),
+			node(2, "C"),
+			node(3, "D"),
+			node(4, "E", "A", "B", "C", "D"),
+		}
+	}
+
+	var tests = []struct {
+		name          string
+		limit         int
+		expectedLimit int
+	}{
+		{name: "nodes run one at a time with a limit of 1", limit: 1, expectedLimit: 1},
+		{name: "independent nodes run concurrently up to the limit", limit: 3, expectedLimit: 3},
+		{name: "nodes run one at a time without a valid limit", limit: 0, expectedLimit: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &concurrencyTracker{}
+			s := &Service{cfg: &setting.Cfg{ExpressionsMaxConcurrentNodes: tt.limit}}
+			pipeline := newPipeline(tracker)
+
+			vars, durations, err := pipeline.execute(context.Background(), s)
+			require.NoError(t, err)
+			require.Len(t, vars, 5)
+			require.Len(t, durations, 5)
+			require.GreaterOrEqual(t, durations["E"], 20*time.Millisecond)
+			require.Equal(t, tt.expectedLimit, tracker.max)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// HysteresisLoadedDimensionsKey is the key of the query model that holds the
+// labels of the dimensions that are currently firing. It is set by the caller,
+// such as the alerting scheduler, before the expression is executed.
+const HysteresisLoadedDimensionsKey = "loadedDimensions"
+
+// HysteresisCommand is an expression command that works like a ThresholdCommand
+// but uses a different threshold to stop firing than to start firing. Dimensions
+// that are not loaded return 1 when the firing threshold is met. Loaded
+// dimensions, that is dimensions that are already firing, keep returning 1 until
+// the recovery threshold is met.
+type HysteresisCommand struct {
+	RefID             string
+	ReferenceVar      string
+	FiringThreshold   *ThresholdCommand
+	RecoveryThreshold *ThresholdCommand
+	LoadedDimensions  []data.Labels
+}
+
+// HysteresisConditionJSON is the JSON model for a single hysteresis condition.
+type HysteresisConditionJSON struct {
+	ThresholdConditionJSON
+	RecoveryEvaluator *classic.ConditionEvalJSON `json:"recoveryEvaluator"`
+}
+
+// NewHysteresisCommand creates a new HysteresisCommand.
+func NewHysteresisCommand(refID, referenceVar string, firing, recovery classic.ConditionEvalJSON, loadedDimensions []data.Labels) (*HysteresisCommand, error) {
+	firingThreshold, err := NewThresholdCommand(refID, referenceVar, firing.Type, firing.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid firing threshold: %w", err)
+	}
+	recoveryThreshold, err := NewThresholdCommand(refID, referenceVar, recovery.Type, recovery.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recovery threshold: %w", err)
+	}
+	return &HysteresisCommand{
+		RefID:             refID,
+		ReferenceVar:      referenceVar,
+		FiringThreshold:   firingThreshold,
+		RecoveryThreshold: recoveryThreshold,
+		LoadedDimensions:  loadedDimensions,
+	}, nil
+}
+
+// UnmarshalHysteresisCommand creates a HysteresisCommand from Grafana's frontend query.
+func UnmarshalHysteresisCommand(rn *rawNode) (*HysteresisCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected hysteresis variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	jsonFromM, err := json.Marshal(rn.Query["conditions"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal hysteresis expression body: %w", err)
+	}
+	var conditions []HysteresisConditionJSON
+	if err = json.Unmarshal(jsonFromM, &conditions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled hysteresis expression body: %w", err)
+	}
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("hysteresis expression for refId %v requires exactly one condition, got %d", rn.RefID, len(conditions))
+	}
+	condition := conditions[0]
+	if condition.RecoveryEvaluator == nil {
+		return nil, fmt.Errorf("hysteresis expression for refId %v requires a recovery evaluator", rn.RefID)
+	}
+
+	var loadedDimensions []data.Labels
+	if rawLoaded, ok := rn.Query[HysteresisLoadedDimensionsKey]; ok && rawLoaded != nil {
+		jsonFromM, err := json.Marshal(rawLoaded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal loaded dimensions of hysteresis expression: %w", err)
+		}
+		if err = json.Unmarshal(jsonFromM, &loadedDimensions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loaded dimensions of hysteresis expression for refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	return NewHysteresisCommand(rn.RefID, referenceVar, condition.Evaluator, *condition.RecoveryEvaluator, loadedDimensions)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (hc *HysteresisCommand) NeedsVars() []string {
+	return []string{hc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (hc *HysteresisCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	firing, err := hc.FiringThreshold.Execute(ctx, vars)
+	if err != nil {
+		return firing, err
+	}
+	if len(hc.LoadedDimensions) == 0 {
+		return firing, nil
+	}
+	recovery, err := hc.RecoveryThreshold.Execute(ctx, vars)
+	if err != nil {
+		return recovery, err
+	}
+
+	// Both thresholds return a Number per input value, in the order of the input.
+	for i, val := range firing.Values {
+		if !hc.isLoaded(val.GetLabels()) {
+			continue
+		}
+		rec := recovery.Values[i].(mathexp.Number)
+		if rec.GetFloat64Value() == nil {
+			firing.Values[i] = rec
+			continue
+		}
+		// A loaded dimension keeps firing until the recovery threshold is met.
+		var v float64
+		if *rec.GetFloat64Value() == 0 {
+			v = 1
+		}
+		rec.SetValue(&v)
+		firing.Values[i] = rec
+	}
+	return firing, nil
+}
+
+// isLoaded returns true if l is contained in any of the loaded dimensions. The
+// loaded dimensions can have more labels than the results of the expression,
+// for example the labels of the alert rule.
+func (hc *HysteresisCommand) isLoaded(l data.Labels) bool {
+	for _, loaded := range hc.LoadedDimensions {
+		if loaded.Contains(l) {
+			return true
+		}
+	}
+	return false
+}
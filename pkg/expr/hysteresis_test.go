@@ -0,0 +1,111 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func classicEval(thresholdFunc string, params ...float64) classic.ConditionEvalJSON {
+	return classic.ConditionEvalJSON{Type: thresholdFunc, Params: params}
+}
+
+func TestUnmarshalHysteresisCommand(t *testing.T) {
+	q := `{
+		"expression" : "$A",
+		"type": "hysteresis",
+		"conditions": [{
+			"evaluator": { "type": "gt", "params": [80] },
+			"recoveryEvaluator": { "type": "lt", "params": [70] }
+		}],
+		"loadedDimensions": [{ "host": "a", "team": "ops" }]
+	}`
+	var qmap = make(map[string]interface{})
+	require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+
+	cmd, err := UnmarshalHysteresisCommand(&rawNode{
+		RefID: "B",
+		Query: qmap,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "A", cmd.ReferenceVar)
+	require.Equal(t, []string{"A"}, cmd.NeedsVars())
+	require.Equal(t, ThresholdIsAbove, cmd.FiringThreshold.ThresholdFunc)
+	require.Equal(t, []float64{80}, cmd.FiringThreshold.Conditions)
+	require.Equal(t, ThresholdIsBelow, cmd.RecoveryThreshold.ThresholdFunc)
+	require.Equal(t, []float64{70}, cmd.RecoveryThreshold.Conditions)
+	require.Equal(t, []data.Labels{{"host": "a", "team": "ops"}}, cmd.LoadedDimensions)
+
+	delete(qmap["conditions"].([]interface{})[0].(map[string]interface{}), "recoveryEvaluator")
+	_, err = UnmarshalHysteresisCommand(&rawNode{
+		RefID: "B",
+		Query: qmap,
+	})
+	require.Error(t, err)
+}
+
+func TestHysteresisCommand_Execute(t *testing.T) {
+	number := func(host string, v *float64) mathexp.Number {
+		n := mathexp.NewNumber("A", data.Labels{"host": host})
+		n.SetValue(v)
+		return n
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			number("above-firing", fp(90)),
+			number("between", fp(75)),
+			number("below-recovery", fp(60)),
+			number("no-value", nil),
+		}},
+	}
+
+	var tests = []struct {
+		name     string
+		loaded   []data.Labels
+		expected []*float64
+	}{
+		{
+			name:     "dimensions that are not loaded use the firing threshold",
+			expected: []*float64{fp(1), fp(0), fp(0), nil},
+		},
+		{
+			name: "loaded dimensions use the recovery threshold",
+			loaded: []data.Labels{
+				{"host": "above-firing", "rule": "label"},
+				{"host": "between", "rule": "label"},
+				{"host": "below-recovery", "rule": "label"},
+				{"host": "no-value", "rule": "label"},
+			},
+			expected: []*float64{fp(1), fp(1), fp(0), nil},
+		},
+		{
+			name:     "dimensions are loaded only if all their labels match",
+			loaded:   []data.Labels{{"host": "other"}},
+			expected: []*float64{fp(1), fp(0), fp(0), nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := NewHysteresisCommand("B", "A",
+				classicEval(ThresholdIsAbove, 80), classicEval(ThresholdIsBelow, 70), test.loaded)
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(test.expected))
+			for i, expected := range test.expected {
+				num, ok := res.Values[i].(mathexp.Number)
+				require.True(t, ok)
+				require.Equal(t, vars["A"].Values[i].GetLabels(), num.GetLabels())
+				require.Equal(t, expected, num.GetFloat64Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package mathexp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// aggregators are the reducers that can be used to combine the values of
+// several series or numbers.
+var aggregators = map[string]ReducerFunc{
+	"sum":   Sum,
+	"avg":   Avg,
+	"min":   Min,
+	"max":   Max,
+	"count": Count,
+}
+
+// GetAggregateFunc returns the ReducerFunc of the aggregator aggFunc.
+func GetAggregateFunc(aggFunc string) (ReducerFunc, error) {
+	f, ok := aggregators[aggFunc]
+	if !ok {
+		names := make([]string, 0, len(aggregators))
+		for name := range aggregators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("aggregation function '%s' is not implemented, expected one of [%s]", aggFunc, strings.Join(names, ","))
+	}
+	return f, nil
+}
+
+// aggregateGroup holds the values that have the same labels for the labels
+// that are aggregated by.
+type aggregateGroup struct {
+	labels data.Labels
+	values Values
+}
+
+// Aggregate combines the Numbers or Series in vals that have the same values for
+// the labels in by, using the aggregator aggFunc. The result has a Number or Series
+// per group, labelled with the labels in by. When by is empty, all values are
+// combined in a single Number or Series without labels.
+//
+// Series are combined point by point, for each time of any of the Series in the group.
+// Null values are ignored: the result is null when all the values are null, or 0 for count.
+func Aggregate(refID, aggFunc string, by []string, vals Values) (Values, error) {
+	reducer, err := GetAggregateFunc(aggFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*aggregateGroup
+	groupsByKey := make(map[string]*aggregateGroup)
+	for _, val := range vals {
+		if val.Type() != vals[0].Type() {
+			return nil, fmt.Errorf("can not aggregate values of type %v and %v", vals[0].Type(), val.Type())
+		}
+		labels := groupLabels(val.GetLabels(), by)
+		key := labels.String()
+		g, ok := groupsByKey[key]
+		if !ok {
+			g = &aggregateGroup{labels: labels}
+			groupsByKey[key] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, val)
+	}
+
+	newVals := make(Values, 0, len(groups))
+	for _, g := range groups {
+		switch g.values[0].(type) {
+		case Number:
+			newVals = append(newVals, aggregateNumbers(refID, reducer, aggFunc, g))
+		case Series:
+			newVals = append(newVals, aggregateSeries(refID, reducer, aggFunc, g))
+		default:
+			return nil, fmt.Errorf("can only aggregate type series or number, got type %v", g.values[0].Type())
+		}
+	}
+	return newVals, nil
+}
+
+// groupLabels returns the labels of l that are in by.
+func groupLabels(l data.Labels, by []string) data.Labels {
+	if len(by) == 0 {
+		return nil
+	}
+	labels := data.Labels{}
+	for _, name := range by {
+		if v, ok := l[name]; ok {
+			labels[name] = v
+		}
+	}
+	return labels
+}
+
+func aggregateNumbers(refID string, reducer ReducerFunc, aggFunc string, g *aggregateGroup) Number {
+	values := make([]*float64, 0, len(g.values))
+	for _, val := range g.values {
+		values = append(values, val.(Number).GetFloat64Value())
+	}
+	n := NewNumber(refID, g.labels)
+	n.SetValue(aggregateValues(reducer, aggFunc, values))
+	return n
+}
+
+func aggregateSeries(refID string, reducer ReducerFunc, aggFunc string, g *aggregateGroup) Series {
+	var times []time.Time
+	valuesByTime := make(map[time.Time][]*float64)
+	for _, val := range g.values {
+		s := val.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			t = t.UTC()
+			if _, ok := valuesByTime[t]; !ok {
+				times = append(times, t)
+			}
+			valuesByTime[t] = append(valuesByTime[t], f)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	newSeries := NewSeries(refID, g.labels, len(times))
+	for i, t := range times {
+		newSeries.SetPoint(i, t, aggregateValues(reducer, aggFunc, valuesByTime[t]))
+	}
+	return newSeries
+}
+
+// aggregateValues returns the result of reducer for the values that are not null.
+func aggregateValues(reducer ReducerFunc, aggFunc string, values []*float64) *float64 {
+	nonNull := make([]*float64, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			nonNull = append(nonNull, v)
+		}
+	}
+	if len(nonNull) == 0 && aggFunc != "count" {
+		return nil
+	}
+	field := Float64Field(*data.NewField("", nil, nonNull))
+	return reducer(&field)
+}
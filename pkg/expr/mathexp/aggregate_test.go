@@ -0,0 +1,121 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	numbers := Values{
+		makeNumber("", data.Labels{"cluster": "a", "host": "1"}, float64Pointer(1)),
+		makeNumber("", data.Labels{"cluster": "a", "host": "2"}, float64Pointer(3)),
+		makeNumber("", data.Labels{"cluster": "b", "host": "3"}, float64Pointer(5)),
+		makeNumber("", data.Labels{"cluster": "b", "host": "4"}, nil),
+		makeNumber("", data.Labels{"cluster": "c", "host": "5"}, nil),
+	}
+
+	var tests = []struct {
+		name       string
+		aggregator string
+		by         []string
+		vals       Values
+		errIs      require.ErrorAssertionFunc
+		expected   Values
+	}{
+		{
+			name:       "sum by cluster",
+			aggregator: "sum",
+			by:         []string{"cluster"},
+			vals:       numbers,
+			errIs:      require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{"cluster": "a"}, float64Pointer(4)),
+				makeNumber("B", data.Labels{"cluster": "b"}, float64Pointer(5)),
+				makeNumber("B", data.Labels{"cluster": "c"}, nil),
+			},
+		},
+		{
+			name:       "count by cluster ignores null values",
+			aggregator: "count",
+			by:         []string{"cluster"},
+			vals:       numbers,
+			errIs:      require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{"cluster": "a"}, float64Pointer(2)),
+				makeNumber("B", data.Labels{"cluster": "b"}, float64Pointer(1)),
+				makeNumber("B", data.Labels{"cluster": "c"}, float64Pointer(0)),
+			},
+		},
+		{
+			name:       "avg without labels combines all values",
+			aggregator: "avg",
+			vals:       numbers,
+			errIs:      require.NoError,
+			expected: Values{
+				makeNumber("B", nil, float64Pointer(3)),
+			},
+		},
+		{
+			name:       "max by a missing label",
+			aggregator: "max",
+			by:         []string{"region"},
+			vals:       numbers,
+			errIs:      require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{}, float64Pointer(5)),
+			},
+		},
+		{
+			name:       "min of series by time",
+			aggregator: "min",
+			by:         []string{"cluster"},
+			vals: Values{
+				makeSeries("", data.Labels{"cluster": "a", "host": "1"},
+					tp{time.Unix(5, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(4)},
+				),
+				makeSeries("", data.Labels{"cluster": "a", "host": "2"},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(15, 0), nil},
+				),
+			},
+			errIs: require.NoError,
+			expected: Values{
+				makeSeries("B", data.Labels{"cluster": "a"},
+					tp{time.Unix(5, 0).UTC(), float64Pointer(2)},
+					tp{time.Unix(10, 0).UTC(), float64Pointer(3)},
+					tp{time.Unix(15, 0).UTC(), nil},
+				),
+			},
+		},
+		{
+			name:       "mixed types",
+			aggregator: "sum",
+			vals: Values{
+				makeNumber("", nil, float64Pointer(1)),
+				makeSeries("", nil, tp{time.Unix(5, 0), float64Pointer(2)}),
+			},
+			errIs: require.Error,
+		},
+		{
+			name:       "unknown aggregator",
+			aggregator: "median",
+			vals:       numbers,
+			errIs:      require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Aggregate("B", tt.aggregator, tt.by, tt.vals)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
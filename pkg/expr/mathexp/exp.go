@@ -5,6 +5,7 @@ import (
 	"math"
 	"reflect"
 	"runtime"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -42,6 +43,24 @@ func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
 	return e, nil
 }
 
+// TableVars returns the names of the variables that the expression uses as the table of a table
+// function, such as A in column($A, "cpu").
+func (e *Expr) TableVars() []string {
+	var vars []string
+	parse.Walk(e.Tree.Root, func(n parse.Node) {
+		fn, ok := n.(*parse.FuncNode)
+		if !ok || !tableFuncs[fn.Name] || len(fn.Args) == 0 {
+			return
+		}
+		parse.Walk(fn.Args[0], func(n parse.Node) {
+			if v, ok := n.(*parse.VarNode); ok {
+				vars = append(vars, v.Name)
+			}
+		})
+	})
+	return vars
+}
+
 // Execute applies a parse expression to the context and executes it
 func (e *Expr) Execute(refID string, vars Vars) (r Results, err error) {
 	s := &State{
@@ -117,6 +136,14 @@ func (e *State) walkUnary(node *parse.UnaryNode) (Results, error) {
 			newVal, err = e.unaryNumber(rt, node.OpStr)
 		case Series:
 			newVal, err = e.unarySeries(rt, node.OpStr)
+		case Table:
+			newVal, err = rt.MapValues(e.RefID, func(f *float64) (*float64, error) {
+				if f == nil {
+					return nil, nil
+				}
+				newF, err := unaryOp(node.OpStr, *f)
+				return &newF, err
+			})
 		default:
 			return newResults, fmt.Errorf("can not perform a unary operation on type %v", rt.Type())
 		}
@@ -238,6 +265,125 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchUnion creates Union objects by matching the labels of each Series or Number
+// of aResults and bResults according to the vector matching modifiers of a binary
+// operation, such as on(host) or ignoring(job) group_left. Scalars match every value
+// on the other side. Unlike union, any value that has no match on the other side
+// results in an error.
+func matchUnion(aResults, bResults Results, vm *parse.VectorMatching) ([]*Union, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+	if isScalarResults(aResults) || isScalarResults(bResults) {
+		return union(aResults, bResults), nil
+	}
+
+	// the "many" side is A for one-to-one and group_left, B for group_right.
+	many, one := aResults.Values, bResults.Values
+	manySide, oneSide := "left", "right"
+	if vm.Card == parse.CardOneToMany {
+		many, one = one, many
+		manySide, oneSide = oneSide, manySide
+	}
+
+	oneBySignature := make(map[string]Value, len(one))
+	for _, v := range one {
+		sig := matchingSignature(v.GetLabels(), vm)
+		if _, ok := oneBySignature[sig]; ok {
+			return nil, fmt.Errorf("found duplicate series on the %s side of the operation for the match group {%s}, use group_left or group_right for many-to-one matching", oneSide, sig)
+		}
+		oneBySignature[sig] = v
+	}
+
+	matchedOne := make(map[string]bool, len(one))
+	seenMany := make(map[string]bool, len(many))
+	var unmatched []string
+	for _, v := range many {
+		sig := matchingSignature(v.GetLabels(), vm)
+		o, ok := oneBySignature[sig]
+		if !ok {
+			unmatched = append(unmatched, fmt.Sprintf("%s side {%s}", manySide, v.GetLabels()))
+			continue
+		}
+		if vm.Card == parse.CardOneToOne {
+			if seenMany[sig] {
+				return nil, fmt.Errorf("found duplicate series on the %s side of the operation for the match group {%s}, use group_left or group_right for many-to-one matching", manySide, sig)
+			}
+			seenMany[sig] = true
+		}
+		matchedOne[sig] = true
+
+		u := &Union{Labels: matchedLabels(v.GetLabels(), o.GetLabels(), vm), A: v, B: o}
+		if vm.Card == parse.CardOneToMany {
+			u.A, u.B = o, v
+		}
+		unions = append(unions, u)
+	}
+	for _, v := range one {
+		if !matchedOne[matchingSignature(v.GetLabels(), vm)] {
+			unmatched = append(unmatched, fmt.Sprintf("%s side {%s}", oneSide, v.GetLabels()))
+		}
+	}
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("no matching series found for %s", strings.Join(unmatched, ", "))
+	}
+	return unions, nil
+}
+
+// isScalarResults returns true if results holds a single Scalar.
+func isScalarResults(res Results) bool {
+	return len(res.Values) == 1 && res.Values[0].Type() == parse.TypeScalar
+}
+
+// matchingSignature returns a string of the labels that are used to match values of
+// the two sides of a binary operation with vector matching.
+func matchingSignature(labels data.Labels, vm *parse.VectorMatching) string {
+	return matchingLabels(labels, vm).String()
+}
+
+// matchingLabels returns the subset of labels that is used for matching, which are the listed
+// labels for on() and all but the listed labels for ignoring().
+func matchingLabels(labels data.Labels, vm *parse.VectorMatching) data.Labels {
+	res := data.Labels{}
+	if vm.On {
+		for _, name := range vm.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				res[name] = v
+			}
+		}
+		return res
+	}
+	for k, v := range labels {
+		res[k] = v
+	}
+	for _, name := range vm.MatchingLabels {
+		delete(res, name)
+	}
+	return res
+}
+
+// matchedLabels returns the labels of the result of a binary operation with vector matching.
+// For one-to-one matching these are the matching labels, otherwise they are the labels of the
+// "many" side plus the labels listed in the group modifier taken from the "one" side.
+func matchedLabels(manyLabels, oneLabels data.Labels, vm *parse.VectorMatching) data.Labels {
+	if vm.Card == parse.CardOneToOne {
+		return matchingLabels(manyLabels, vm)
+	}
+	res := manyLabels.Copy()
+	if res == nil {
+		res = data.Labels{}
+	}
+	for _, name := range vm.Include {
+		if v, ok := oneLabels[name]; ok {
+			res[name] = v
+		} else {
+			delete(res, name)
+		}
+	}
+	return res
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -248,7 +394,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.VectorMatching != nil {
+		unions, err = matchUnion(ar, br, node.VectorMatching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -276,6 +430,9 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 			// Scalar op Series
 			case Series:
 				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+			// Scalar op Table
+			case Table:
+				value, err = e.biTableNumber(node.OpStr, bt, aFloat, false)
 			default:
 				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
 			}
@@ -306,6 +463,22 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
 			case Series:
 				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+			case Table:
+				value, err = e.biTableNumber(node.OpStr, bt, aFloat, false)
+			default:
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+			}
+		case Table:
+			switch bt := uni.B.(type) {
+			// Table op Scalar
+			case Scalar:
+				value, err = e.biTableNumber(node.OpStr, at, bt.GetFloat64Value(), true)
+			// Table op Number
+			case Number:
+				value, err = e.biTableNumber(node.OpStr, at, bt.GetFloat64Value(), true)
+			// Table op Table
+			case Table:
+				value, err = e.biTableTable(node.OpStr, at, bt)
 			default:
 				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
 			}
@@ -479,6 +652,61 @@ func (e *State) biSeriesSeries(labels data.Labels, op string, aSeries, bSeries S
 	return newSeries, nil
 }
 
+// biTableNumber performs the binary operation between every value of the numeric
+// columns of the table and the number.
+func (e *State) biTableNumber(op string, t Table, scalarVal *float64, tableFirst bool) (Table, error) {
+	return t.MapValues(e.RefID, func(f *float64) (*float64, error) {
+		if f == nil || scalarVal == nil {
+			return nil, nil
+		}
+		var nF float64
+		var err error
+		if tableFirst {
+			nF, err = binaryOp(op, *f, *scalarVal)
+		} else {
+			nF, err = binaryOp(op, *scalarVal, *f)
+		}
+		return &nF, err
+	})
+}
+
+// biTableTable performs the binary operation column-wise between the numeric columns
+// of the two tables that have the same name, row by row. The tables must have the
+// same number of rows. The result has the non numeric columns of aTable and the
+// numeric columns that are in both tables.
+func (e *State) biTableTable(op string, aTable, bTable Table) (Table, error) {
+	if aTable.Len() != bTable.Len() {
+		return Table{}, fmt.Errorf("can not perform binary %v on tables with a different number of rows: %v and %v", op, aTable.Len(), bTable.Len())
+	}
+	newTable := Table{data.NewFrame(aTable.Frame.Name)}
+	newTable.Frame.RefID = e.RefID
+	for _, aField := range aTable.Frame.Fields {
+		if aField.Type() != data.FieldTypeNullableFloat64 {
+			newTable.Frame.Fields = append(newTable.Frame.Fields, copyField(aField))
+			continue
+		}
+		bField, err := bTable.valueField(aField.Name)
+		if err != nil {
+			continue
+		}
+		newField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, aField.Len())
+		newField.Name = aField.Name
+		for i := 0; i < aField.Len(); i++ {
+			aF, bF := aField.At(i).(*float64), bField.At(i).(*float64)
+			if aF == nil || bF == nil {
+				continue
+			}
+			nF, err := binaryOp(op, *aF, *bF)
+			if err != nil {
+				return newTable, err
+			}
+			newField.Set(i, &nF)
+		}
+		newTable.Frame.Fields = append(newTable.Frame.Fields, newField)
+	}
+	return newTable, nil
+}
+
 func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 	var res Results
 	var err error
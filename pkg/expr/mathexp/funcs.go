@@ -1,7 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,8 +73,71 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"timeshift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+	},
+	"stddev": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeNumberSet,
+		F:      stdDev,
+	},
+	"percentile": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeNumberSet,
+		F:      percentile,
+	},
+	"column": {
+		Args:   []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		Return: parse.TypeNumberSet,
+		F:      column,
+	},
+	"select": {
+		Args:   []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		Return: parse.TypeTableSet,
+		F:      selectColumns,
+	},
+	"filter": {
+		Args:   []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		Return: parse.TypeTableSet,
+		F:      filter,
+	},
 }
 
+// tableFuncs are the functions whose first argument is a table.
+var tableFuncs = map[string]bool{"column": true, "select": true, "filter": true}
+
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
 func abs(e *State, varSet Results) (Results, error) {
 	newRes := Results{}
@@ -229,6 +297,14 @@ func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error
 			newSeries.SetPoint(i, t, &nF)
 		}
 		newVal = newSeries
+	case parse.TypeTableSet:
+		return val.(Table).MapValues(e.RefID, func(f *float64) (*float64, error) {
+			nF := math.NaN()
+			if f != nil {
+				nF = floatF(*f)
+			}
+			return &nF, nil
+		})
 	default:
 		// TODO: Should we deal with TypeString, TypeVariantSet?
 	}
@@ -258,6 +334,10 @@ func perNullableFloat(e *State, val Value, floatF func(x *float64) *float64) (Va
 			newSeries.SetPoint(i, t, floatF(f))
 		}
 		newVal = newSeries
+	case parse.TypeTableSet:
+		return val.(Table).MapValues(e.RefID, func(f *float64) (*float64, error) {
+			return floatF(f), nil
+		})
 	default:
 		// TODO: Should we deal with TypeString, TypeVariantSet?
 	}
@@ -303,3 +383,287 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clampMin returns the greater of each value in NumberSet, SeriesSet, or Scalar and the scalar min.
+func clampMin(e *State, varSet Results, minRes Results) (Results, error) {
+	minVal, err := scalarArg("clamp_min", minRes)
+	if err != nil {
+		return Results{}, err
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(f, minVal)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// clampMax returns the lesser of each value in NumberSet, SeriesSet, or Scalar and the scalar max.
+func clampMax(e *State, varSet Results, maxRes Results) (Results, error) {
+	maxVal, err := scalarArg("clamp_max", maxRes)
+	if err != nil {
+		return Results{}, err
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Min(f, maxVal)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// rate returns the per-second rate of increase between consecutive points of each Series
+// in the SeriesSet. A decrease in value is treated as a counter reset. Consecutive points with
+// the same time have no rate, so the zero-width intervals are skipped.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) (Value, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < s.Len(); i++ {
+			prevT, prev := s.GetPoint(i - 1)
+			t, cur := s.GetPoint(i)
+			seconds := t.Sub(prevT).Seconds()
+			if seconds == 0 {
+				continue
+			}
+			nF := math.NaN()
+			if prev != nil && cur != nil {
+				nF = counterIncrease(*prev, *cur) / seconds
+			}
+			newSeries.AppendPoint(t, &nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// delta returns the difference between consecutive points of each Series in the SeriesSet.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) (Value, error) {
+		return perPointPair(e, s, func(_, _ time.Time, prev, cur float64) float64 {
+			return cur - prev
+		}), nil
+	})
+}
+
+// increase returns the increase between consecutive points of each Series in the SeriesSet.
+// A decrease in value is treated as a counter reset.
+func increase(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "increase", varSet, func(s Series) (Value, error) {
+		return perPointPair(e, s, func(_, _ time.Time, prev, cur float64) float64 {
+			return counterIncrease(prev, cur)
+		}), nil
+	})
+}
+
+// movingAvg returns, for each point of each Series in the SeriesSet, the average of that point
+// and the preceding points within a window of the given number of points. Null and NaN values
+// are ignored, if the window has no other values the point is NaN.
+func movingAvg(e *State, varSet Results, windowRes Results) (Results, error) {
+	window, err := scalarArg("moving_avg", windowRes)
+	if err != nil {
+		return Results{}, err
+	}
+	if window < 1 || window != math.Trunc(window) {
+		return Results{}, fmt.Errorf("moving_avg window must be a positive integer number of points, got %v", window)
+	}
+	size := int(window)
+	return perSeries(e, "moving_avg", varSet, func(s Series) (Value, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			var sum float64
+			var count int
+			for j := i; j >= 0 && j > i-size; j-- {
+				if f := s.GetValue(j); f != nil && !math.IsNaN(*f) {
+					sum += *f
+					count++
+				}
+			}
+			avg := math.NaN()
+			if count > 0 {
+				avg = sum / float64(count)
+			}
+			newSeries.SetPoint(i, s.GetTime(i), &avg)
+		}
+		return newSeries, nil
+	})
+}
+
+// timeShift moves each point of each Series in the SeriesSet forward in time by the duration.
+// A negative duration moves the points backwards.
+func timeShift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("failed to parse timeshift duration %q: %w", rawDuration, err)
+	}
+	return perSeries(e, "timeshift", varSet, func(s Series) (Value, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries, nil
+	})
+}
+
+// stdDev returns the population standard deviation of each Series in the SeriesSet as a Number.
+func stdDev(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "stddev", varSet, func(s Series) (Value, error) {
+		return reduceSeries(e, s, StdDev), nil
+	})
+}
+
+// percentile returns the q-th percentile of each Series in the SeriesSet as a Number.
+func percentile(e *State, varSet Results, qRes Results) (Results, error) {
+	q, err := scalarArg("percentile", qRes)
+	if err != nil {
+		return Results{}, err
+	}
+	if q < 0 || q > 100 {
+		return Results{}, fmt.Errorf("percentile must be between 0 and 100, got %v", q)
+	}
+	return perSeries(e, "percentile", varSet, func(s Series) (Value, error) {
+		return reduceSeries(e, s, Percentile(q)), nil
+	})
+}
+
+// scalarArg returns the value of a Scalar function argument.
+func scalarArg(funcName string, res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s expects a single scalar argument", funcName)
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s expects a non-null scalar argument", funcName)
+	}
+	return *f, nil
+}
+
+// perSeries passes each Series in varSet to seriesF. It returns an error if varSet holds a value
+// that is not a Series.
+func perSeries(e *State, funcName string, varSet Results, seriesF func(s Series) (Value, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		s, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("%s can only be applied to type series, got type %v", funcName, res.Type())
+		}
+		newVal, err := seriesF(s)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// column returns a Number for every row of each Table in varSet, holding the value of the numeric
+// column name and labelled with the string columns of the row.
+func column(e *State, varSet Results, name string) (Results, error) {
+	return perTable(e, "column", varSet, func(t Table) (Results, error) {
+		numbers, err := t.Column(e.RefID, name)
+		if err != nil {
+			return Results{}, err
+		}
+		res := Results{}
+		for _, n := range numbers {
+			res.Values = append(res.Values, n)
+		}
+		return res, nil
+	})
+}
+
+// selectColumns returns each Table in varSet with only the comma separated columns in names.
+func selectColumns(e *State, varSet Results, names string) (Results, error) {
+	return perTable(e, "select", varSet, func(t Table) (Results, error) {
+		newTable, err := t.Select(e.RefID, strings.Split(names, ","))
+		if err != nil {
+			return Results{}, err
+		}
+		return Results{Values: Values{newTable}}, nil
+	})
+}
+
+// filter returns each Table in varSet with only the rows that have the same labels as a
+// Number of condition that is neither 0 nor null, such as filter($A, column($A, "cpu") > 80).
+func filter(e *State, varSet Results, condition Results) (Results, error) {
+	keep := make(map[string]bool, len(condition.Values))
+	for _, val := range condition.Values {
+		n, ok := val.(Number)
+		if !ok {
+			return Results{}, fmt.Errorf("filter condition must be of type number, got type %v", val.Type())
+		}
+		if f := n.GetFloat64Value(); f != nil && *f != 0 && !math.IsNaN(*f) {
+			keep[n.GetLabels().String()] = true
+		}
+	}
+	return perTable(e, "filter", varSet, func(t Table) (Results, error) {
+		newTable := t.Filter(e.RefID, func(idx int) bool {
+			return keep[t.RowLabels(idx).String()]
+		})
+		return Results{Values: Values{newTable}}, nil
+	})
+}
+
+// perTable passes each Table in varSet to tableF and combines the results. It returns an error if
+// varSet holds a value that is not a Table.
+func perTable(e *State, funcName string, varSet Results, tableF func(t Table) (Results, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		t, ok := res.(Table)
+		if !ok {
+			return newRes, fmt.Errorf("%s can only be applied to type table, got type %v", funcName, res.Type())
+		}
+		tableRes, err := tableF(t)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, tableRes.Values...)
+	}
+	return newRes, nil
+}
+
+// perPointPair returns a Series with a point for every point of s but the first, holding the result
+// of pairF applied to that point and the point before it. If either of the values is null the point is NaN.
+func perPointPair(e *State, s Series, pairF func(prevT, t time.Time, prev, cur float64) float64) Series {
+	size := s.Len() - 1
+	if size < 0 {
+		size = 0
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), size)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		nF := math.NaN()
+		if prev != nil && cur != nil {
+			nF = pairF(prevT, t, *prev, *cur)
+		}
+		newSeries.SetPoint(i-1, t, &nF)
+	}
+	return newSeries
+}
+
+// counterIncrease returns the increase from prev to cur, treating a decrease as a counter reset.
+func counterIncrease(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// reduceSeries returns a Number with the labels of s that holds the result of reduceF.
+func reduceSeries(e *State, s Series, reduceF ReducerFunc) Number {
+	n := NewNumber(e.RefID, s.GetLabels())
+	fVec := s.Frame.Fields[seriesTypeValIdx]
+	floatField := Float64Field(*fVec)
+	n.SetValue(reduceF(&floatField))
+	return n
+}
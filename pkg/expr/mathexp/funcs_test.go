@@ -159,3 +159,224 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesFuncs(t *testing.T) {
+	counter := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(30)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(30, 0), nil}),
+			},
+		},
+	}
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "rate on series handles counter resets",
+			expr:      "rate($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(0.5)},
+					tp{time.Unix(30, 0), float64Pointer(math.NaN())}),
+			}},
+		},
+		{
+			name: "rate on series skips points with the same time",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(0, 0), float64Pointer(10)},
+							tp{time.Unix(10, 0), float64Pointer(30)},
+							tp{time.Unix(10, 0), float64Pointer(40)},
+							tp{time.Unix(20, 0), float64Pointer(60)}),
+					},
+				},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(2)}),
+			}},
+		},
+		{
+			name:      "increase on series handles counter resets",
+			expr:      "increase($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(30, 0), float64Pointer(math.NaN())}),
+			}},
+		},
+		{
+			name:      "delta on series",
+			expr:      "delta($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(-25)},
+					tp{time.Unix(30, 0), float64Pointer(math.NaN())}),
+			}},
+		},
+		{
+			name:      "moving_avg on series ignores null values",
+			expr:      "moving_avg($A, 2)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(17.5)},
+					tp{time.Unix(30, 0), float64Pointer(5)}),
+			}},
+		},
+		{
+			name:      "moving_avg with invalid window",
+			expr:      "moving_avg($A, 0.5)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "timeshift on series",
+			expr:      `timeshift($A, "1m")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(60, 0), float64Pointer(10)},
+					tp{time.Unix(70, 0), float64Pointer(30)},
+					tp{time.Unix(80, 0), float64Pointer(5)},
+					tp{time.Unix(90, 0), nil}),
+			}},
+		},
+		{
+			name:      "clamp_min on number",
+			expr:      "clamp_min($A, 0)",
+			vars:      Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(-3))}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(0))}},
+		},
+		{
+			name:      "clamp_max on scalar",
+			expr:      "clamp_max(7, 5)",
+			vars:      Vars{},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{NewScalar("", float64Pointer(5))}},
+		},
+		{
+			name: "stddev on series",
+			expr: "stddev($A)",
+			vars: Vars{"A": Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), float64Pointer(4)},
+				tp{time.Unix(20, 0), float64Pointer(4)},
+				tp{time.Unix(30, 0), float64Pointer(4)},
+				tp{time.Unix(40, 0), float64Pointer(5)},
+				tp{time.Unix(50, 0), float64Pointer(5)},
+				tp{time.Unix(60, 0), float64Pointer(7)},
+				tp{time.Unix(70, 0), float64Pointer(9)})}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(2))}},
+		},
+		{
+			name: "percentile on series",
+			expr: "percentile($A, 75)",
+			vars: Vars{"A": Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(4)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(20, 0), float64Pointer(3)},
+				tp{time.Unix(30, 0), float64Pointer(2)},
+				tp{time.Unix(40, 0), float64Pointer(5)})}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(4))}},
+		},
+		{
+			name:      "percentile out of range",
+			expr:      "percentile($A, 101)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "rate on number should error",
+			expr:      "rate($A)",
+			vars:      Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "rate on scalar should not parse",
+			expr:     "rate(1)",
+			vars:     Vars{},
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				if tt.results.Values != nil {
+					requireResultsInDelta(t, tt.results, res)
+				}
+			}
+		})
+	}
+}
+
+// requireResultsInDelta is like require.Equal but treats NaN values as equal.
+func requireResultsInDelta(t *testing.T, expected, actual Results) {
+	t.Helper()
+	require.Len(t, actual.Values, len(expected.Values))
+	for i := range expected.Values {
+		e := expected.Values[i].AsDataFrame()
+		a := actual.Values[i].AsDataFrame()
+		require.Equal(t, e.Fields[0].Labels, a.Fields[0].Labels)
+		for fIdx := range e.Fields {
+			require.Equal(t, e.Fields[fIdx].Len(), a.Fields[fIdx].Len())
+			for rIdx := 0; rIdx < e.Fields[fIdx].Len(); rIdx++ {
+				ev, av := e.Fields[fIdx].At(rIdx), a.Fields[fIdx].At(rIdx)
+				if ef, ok := ev.(*float64); ok && ef != nil && math.IsNaN(*ef) {
+					af := av.(*float64)
+					require.NotNil(t, af)
+					require.True(t, math.IsNaN(*af))
+					continue
+				}
+				require.Equal(t, ev, av)
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -174,8 +175,8 @@ func (f *FuncNode) Check(t *Tree) error {
 		// 	argType = TypeNumberSet
 		// }
 		if funcType == TypeVariantSet {
-			if !(argType == TypeNumberSet || argType == TypeSeriesSet || argType == TypeScalar) {
-				return fmt.Errorf("parse: expected %v or %v for argument %v, got %v", TypeNumberSet, TypeSeriesSet, i, argType)
+			if !(argType == TypeNumberSet || argType == TypeSeriesSet || argType == TypeScalar || argType == TypeTableSet) {
+				return fmt.Errorf("parse: expected %v, %v or %v for argument %v, got %v", TypeNumberSet, TypeSeriesSet, TypeTableSet, i, argType)
 			}
 		} else if funcType != argType {
 			return fmt.Errorf("parse: expected %v, got %v for argument %v (%v)", funcType, argType, i, arg.String())
@@ -298,6 +299,9 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// VectorMatching holds the label matching modifiers of the operation, e.g. on(host).
+	// It is nil when the operation has no modifiers.
+	VectorMatching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,9 +310,57 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.VectorMatching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.VectorMatching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
+// VectorMatchCardinality describes how many values on each side of a binary operation
+// may share the same matching labels.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne requires every value to match at most one value on the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne allows many values on the left side to match one value on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany allows one value on the left side to match many values on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching holds the label matching modifiers of a binary operation
+// such as "$A / on(host) group_left(region) $B".
+type VectorMatching struct {
+	// Card is the cardinality of the matching.
+	Card VectorMatchCardinality
+	// On is true if MatchingLabels are the only labels that are matched (on),
+	// and false if they are the labels that are excluded from matching (ignoring).
+	On bool
+	// MatchingLabels are the labels listed in the on or ignoring modifier.
+	MatchingLabels []string
+	// Include are the labels listed in the group_left or group_right modifier that
+	// are copied from the "one" side to the result.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (vm *VectorMatching) String() string {
+	var b strings.Builder
+	if vm.On {
+		fmt.Fprintf(&b, "on(%s)", strings.Join(vm.MatchingLabels, ", "))
+	} else {
+		fmt.Fprintf(&b, "ignoring(%s)", strings.Join(vm.MatchingLabels, ", "))
+	}
+	switch vm.Card {
+	case CardManyToOne:
+		fmt.Fprintf(&b, " group_left(%s)", strings.Join(vm.Include, ", "))
+	case CardOneToMany:
+		fmt.Fprintf(&b, " group_right(%s)", strings.Join(vm.Include, ", "))
+	}
+	return b.String()
+}
+
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
@@ -355,7 +407,7 @@ func (u *UnaryNode) StringAST() string {
 // Check performs parse time checking on the UnaryNode so it fulfills the Node interface.
 func (u *UnaryNode) Check(t *Tree) error {
 	switch rt := u.Arg.Return(); rt {
-	case TypeNumberSet, TypeSeriesSet, TypeScalar:
+	case TypeNumberSet, TypeSeriesSet, TypeScalar, TypeTableSet:
 		return u.Arg.Check(t)
 	default:
 		return fmt.Errorf(`parse: type error in %s, expected "number", got %s`, u, rt)
@@ -378,7 +430,7 @@ func Walk(n Node, f func(Node)) {
 		for _, a := range n.Args {
 			Walk(a, f)
 		}
-	case *ScalarNode, *StringNode:
+	case *ScalarNode, *StringNode, *VarNode:
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
@@ -399,8 +451,10 @@ const (
 	TypeNumberSet
 	// TypeSeriesSet is a collection of labelled time series.
 	TypeSeriesSet
-	// TypeVariantSet is a collection of the same type Number, Series, or Scalar.
+	// TypeVariantSet is a collection of the same type Number, Series, Scalar, or Table.
 	TypeVariantSet
+	// TypeTableSet is a collection of tables, such as the result of a SQL query.
+	TypeTableSet
 )
 
 // String returns a string representation of the ReturnType.
@@ -416,6 +470,8 @@ func (f ReturnType) String() string {
 		return "scalar"
 	case TypeVariantSet:
 		return "variant"
+	case TypeTableSet:
+		return "tableSet"
 	default:
 		return "unknown"
 	}
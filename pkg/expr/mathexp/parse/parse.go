@@ -165,18 +165,79 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [VM] A}
+A -> C {"&&" [VM] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [VM] P}
+P -> M {( "+" | "-" ) [VM] M}
+M -> E {( "*" | "/" ) [VM] F}
+E -> F {( "**" ) [VM] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+VM -> [( "on" | "ignoring" ) labels] [( "group_left" | "group_right" ) [labels]]
+labels -> "(" [label {"," label}] ")"
 */
 
+// binary parses the optional vector matching modifiers that follow operator,
+// and then the right hand side argument of the operation using rhs.
+func (t *Tree) binary(operator item, lhs Node, rhs func() Node) Node {
+	vm := t.vectorMatching()
+	b := newBinary(operator, lhs, rhs())
+	b.VectorMatching = vm
+	return b
+}
+
+// vectorMatching parses VM in the grammar. It returns nil if there are no modifiers.
+func (t *Tree) vectorMatching() *VectorMatching {
+	var vm *VectorMatching
+	if token := t.peek(); token.typ == itemFunc && (token.val == "on" || token.val == "ignoring") {
+		t.next()
+		vm = &VectorMatching{
+			On:             token.val == "on",
+			MatchingLabels: t.labelList(token.val),
+		}
+	}
+	if token := t.peek(); token.typ == itemFunc && (token.val == "group_left" || token.val == "group_right") {
+		t.next()
+		if vm == nil {
+			vm = &VectorMatching{}
+		}
+		vm.Card = CardManyToOne
+		if token.val == "group_right" {
+			vm.Card = CardOneToMany
+		}
+		if t.peek().typ == itemLeftParen {
+			vm.Include = t.labelList(token.val)
+		}
+	}
+	return vm
+}
+
+// labelList parses labels in the grammar.
+func (t *Tree) labelList(context string) []string {
+	labels := []string{}
+	t.expect(itemLeftParen, context)
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		case itemComma:
+			// separator between labels
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // expr:
 
 // O is A {"||" A} in the grammar.
@@ -185,7 +246,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +259,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +272,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +285,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +298,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +311,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -329,6 +390,8 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			// argument separator, the next argument follows
 		case itemRightParen:
 			return
 		}
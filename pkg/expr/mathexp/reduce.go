@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -81,8 +84,128 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	avg := Avg(fv)
+	if math.IsNaN(*avg) {
+		return avg
+	}
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *avg
+		f += d * d
+	}
+	f = math.Sqrt(f / float64(fv.Len()))
+	return &f
+}
+
+// Percentile returns a ReducerFunc that calculates the q-th percentile (0 <= q <= 100)
+// of the values, interpolating linearly between the closest ranks.
+func Percentile(q float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		var f float64
+		if fv.Len() == 0 {
+			f = math.NaN()
+			return &f
+		}
+		values := make([]float64, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v := fv.GetValue(i)
+			if v == nil || math.IsNaN(*v) {
+				nan := math.NaN()
+				return &nan
+			}
+			values = append(values, *v)
+		}
+		sort.Float64s(values)
+		rank := q / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f = values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// First returns the first value, or NaN if there are no values.
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// CountNonNull returns the number of values that are not null or NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	return firstLastFunc(fv, func(first, last float64) float64 {
+		return last - first
+	})
+}
+
+// DiffAbs returns the absolute difference between the last and the first value.
+func DiffAbs(fv *Float64Field) *float64 {
+	return firstLastFunc(fv, func(first, last float64) float64 {
+		return math.Abs(last - first)
+	})
+}
+
+// PercentDiff returns the difference between the last and the first value
+// as a percentage of the absolute first value. If the first value is 0, NaN is returned.
+func PercentDiff(fv *Float64Field) *float64 {
+	return firstLastFunc(fv, func(first, last float64) float64 {
+		if first == 0 {
+			return math.NaN()
+		}
+		return (last - first) / math.Abs(first) * 100
+	})
+}
+
+// firstLastFunc applies fn to the first and the last value. If there are no values,
+// or the first or last value is null or NaN, NaN is returned.
+func firstLastFunc(fv *Float64Field, fn func(first, last float64) float64) *float64 {
+	f := math.NaN()
+	if fv.Len() == 0 {
+		return &f
+	}
+	first, last := fv.GetValue(0), fv.GetValue(fv.Len()-1)
+	if first == nil || last == nil {
+		return &f
+	}
+	f = fn(*first, *last)
+	return &f
+}
+
+// percentileReducerRegexp matches percentile reducer names such as p95 or p99.9.
+var percentileReducerRegexp = regexp.MustCompile(`^p(\d+(?:\.\d+)?)$`)
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
-	switch strings.ToLower(rFunc) {
+	name := strings.ToLower(rFunc)
+	if m := percentileReducerRegexp.FindStringSubmatch(name); m != nil {
+		q, err := strconv.ParseFloat(m[1], 64)
+		if err != nil || q > 100 {
+			return nil, fmt.Errorf("reduction %v is not a valid percentile, expected p0 to p100", rFunc)
+		}
+		return Percentile(q), nil
+	}
+
+	switch name {
 	case "sum":
 		return Sum, nil
 	case "mean":
@@ -95,6 +218,20 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "median":
+		return Percentile(50), nil
+	case "stddev":
+		return StdDev, nil
+	case "diff":
+		return Diff, nil
+	case "diff_abs":
+		return DiffAbs, nil
+	case "percent_diff":
+		return PercentDiff, nil
+	case "count_non_null":
+		return CountNonNull, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -514,3 +514,157 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	series := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("temp", nil,
+					tp{time.Unix(5, 0), float64Pointer(4)},
+					tp{time.Unix(10, 0), float64Pointer(1)},
+					tp{time.Unix(15, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(2)},
+					tp{time.Unix(25, 0), float64Pointer(5)}),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name    string
+		red     string
+		vars    Vars
+		mapper  ReduceMapper
+		errIs   require.ErrorAssertionFunc
+		results Results
+	}{
+		{
+			name:    "median series",
+			red:     "median",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:    "p75 series",
+			red:     "p75",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(4))}},
+		},
+		{
+			name:    "p12.5 series",
+			red:     "p12.5",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(1.5))}},
+		},
+		{
+			name:  "p101 will error",
+			red:   "p101",
+			vars:  series,
+			errIs: require.Error,
+		},
+		{
+			name:    "stddev series",
+			red:     "stddev",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(math.Sqrt(2)))}},
+		},
+		{
+			name:    "first series",
+			red:     "first",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(4))}},
+		},
+		{
+			name:    "diff series",
+			red:     "diff",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
+		{
+			name:    "diff_abs series",
+			red:     "diff_abs",
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "dropNN: diff_abs series with a nil value",
+			red:     "diff_abs",
+			vars:    seriesWithNil,
+			mapper:  DropNonNumber{},
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(0))}},
+		},
+		{
+			name:    "percent_diff series",
+			red:     "percent_diff",
+			vars:    series,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(25))}},
+		},
+		{
+			name: "percent_diff series starting at zero",
+			red:  "percent_diff",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("temp", nil,
+							tp{time.Unix(5, 0), float64Pointer(0)},
+							tp{time.Unix(10, 0), float64Pointer(2)}),
+					},
+				},
+			},
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "count_non_null series with a nil value",
+			red:     "count_non_null",
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
+		{
+			name:    "replaceNN: median series with a nil value",
+			red:     "median",
+			vars:    seriesWithNil,
+			mapper:  ReplaceNonNumberWithValue{Value: 4},
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:    "median empty series",
+			red:     "median",
+			vars:    seriesEmpty,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			seriesSet := tt.vars["A"]
+			for _, series := range seriesSet.Values {
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
+				tt.errIs(t, err)
+				if err != nil {
+					return
+				}
+				results.Values = append(results.Values, ns)
+			}
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, results, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
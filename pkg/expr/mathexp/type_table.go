@@ -0,0 +1,186 @@
+package mathexp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// Table is a collection of rows that is not a time series, such as the result
+// of a SQL query. The string columns of a row are its labels and the numeric
+// columns hold its values. All numeric columns are *float64 fields.
+type Table struct {
+	Frame *data.Frame
+}
+
+// TableFromFrame creates a Table from a data frame with at least one numeric
+// field. Numeric fields are converted to *float64 fields and any other fields
+// are copied as they are.
+func TableFromFrame(refID string, frame *data.Frame) (Table, error) {
+	t := Table{data.NewFrame(frame.Name)}
+	numericCount := 0
+	for _, field := range frame.Fields {
+		if !field.Type().Numeric() {
+			t.Frame.Fields = append(t.Frame.Fields, copyField(field))
+			continue
+		}
+		numericCount++
+		converted := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, field.Len())
+		converted.Name = field.Name
+		converted.Labels = field.Labels
+		converted.Config = field.Config
+		for i := 0; i < field.Len(); i++ {
+			f, err := field.NullableFloatAt(i)
+			if err != nil {
+				return t, fmt.Errorf("failed to convert column %s of frame %s: %w", field.Name, frame.Name, err)
+			}
+			converted.Set(i, f)
+		}
+		t.Frame.Fields = append(t.Frame.Fields, converted)
+	}
+	if numericCount == 0 {
+		return t, fmt.Errorf("no numeric column found in frame %v", frame.Name)
+	}
+	t.Frame.RefID = refID
+	t.Frame.Meta = frame.Meta
+	return t, nil
+}
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (t Table) Type() parse.ReturnType { return parse.TypeTableSet }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (t Table) Value() interface{} { return &t }
+
+// GetLabels returns nil as the labels of a Table are held by its rows.
+func (t Table) GetLabels() data.Labels { return nil }
+
+func (t Table) SetLabels(ls data.Labels) {}
+
+func (t Table) GetMeta() interface{} {
+	return t.Frame.Meta.Custom
+}
+
+func (t Table) SetMeta(v interface{}) {
+	t.Frame.SetMeta(&data.FrameMeta{Custom: v})
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (t Table) AsDataFrame() *data.Frame { return t.Frame }
+
+// Len returns the number of rows of the Table.
+func (t Table) Len() int {
+	return t.Frame.Rows()
+}
+
+// RowLabels returns the labels of the row at idx, made of the names and values
+// of the string columns of the Table.
+func (t Table) RowLabels(idx int) data.Labels {
+	var labels data.Labels
+	for _, field := range t.Frame.Fields {
+		if field.Type() != data.FieldTypeString && field.Type() != data.FieldTypeNullableString {
+			continue
+		}
+		if labels == nil {
+			labels = make(data.Labels)
+		}
+		v, ok := field.ConcreteAt(idx)
+		if !ok {
+			continue
+		}
+		labels[field.Name] = v.(string)
+	}
+	return labels
+}
+
+// Column returns a Number for every row of the Table that holds the value of
+// the numeric column name and the labels of the row.
+func (t Table) Column(refID, name string) ([]Number, error) {
+	field, err := t.valueField(name)
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]Number, t.Len())
+	for i := range numbers {
+		n := NewNumber(refID, t.RowLabels(i))
+		n.SetValue(field.At(i).(*float64))
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// Select returns a new Table with only the columns in names, in that order.
+func (t Table) Select(refID string, names []string) (Table, error) {
+	newTable := Table{data.NewFrame(t.Frame.Name)}
+	newTable.Frame.RefID = refID
+	for _, name := range names {
+		field, idx := t.Frame.FieldByName(strings.TrimSpace(name))
+		if idx == -1 {
+			return newTable, fmt.Errorf("no column %s found in table", name)
+		}
+		newTable.Frame.Fields = append(newTable.Frame.Fields, copyField(field))
+	}
+	return newTable, nil
+}
+
+// Filter returns a new Table with the rows for which keep returns true.
+func (t Table) Filter(refID string, keep func(idx int) bool) Table {
+	newTable := Table{t.Frame.EmptyCopy()}
+	newTable.Frame.RefID = refID
+	for i := 0; i < t.Len(); i++ {
+		if keep(i) {
+			newTable.Frame.AppendRow(t.Frame.RowCopy(i)...)
+		}
+	}
+	return newTable
+}
+
+// MapValues returns a new Table where every value of every numeric column
+// is replaced by the result of mapper.
+func (t Table) MapValues(refID string, mapper func(f *float64) (*float64, error)) (Table, error) {
+	newTable := Table{t.Frame.EmptyCopy()}
+	newTable.Frame.RefID = refID
+	for fIdx, field := range t.Frame.Fields {
+		newField := newTable.Frame.Fields[fIdx]
+		if field.Type() != data.FieldTypeNullableFloat64 {
+			for i := 0; i < field.Len(); i++ {
+				newField.Append(field.CopyAt(i))
+			}
+			continue
+		}
+		for i := 0; i < field.Len(); i++ {
+			f, err := mapper(field.At(i).(*float64))
+			if err != nil {
+				return newTable, err
+			}
+			newField.Append(f)
+		}
+	}
+	return newTable, nil
+}
+
+// valueField returns the numeric column name of the Table.
+func (t Table) valueField(name string) (*data.Field, error) {
+	field, idx := t.Frame.FieldByName(name)
+	if idx == -1 {
+		return nil, fmt.Errorf("no column %s found in table", name)
+	}
+	if field.Type() != data.FieldTypeNullableFloat64 {
+		return nil, fmt.Errorf("column %s of table is not numeric", name)
+	}
+	return field, nil
+}
+
+// copyField returns a copy of field with its values.
+func copyField(field *data.Field) *data.Field {
+	newField := data.NewFieldFromFieldType(field.Type(), field.Len())
+	newField.Name = field.Name
+	newField.Labels = field.Labels.Copy()
+	newField.Config = field.Config
+	for i := 0; i < field.Len(); i++ {
+		newField.Set(i, field.CopyAt(i))
+	}
+	return newField
+}
@@ -0,0 +1,124 @@
+package mathexp
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTable(t *testing.T) Table {
+	t.Helper()
+	table, err := TableFromFrame("A", data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("region", nil, []string{"eu", "us", "eu"}),
+		data.NewField("cpu", nil, []int64{10, 90, 50}),
+		data.NewField("mem", nil, []*float64{float64Pointer(1), nil, float64Pointer(3)}),
+	))
+	require.NoError(t, err)
+	return table
+}
+
+func TestTableFromFrame(t *testing.T) {
+	table := makeTable(t)
+	require.Equal(t, 3, table.Len())
+	require.Equal(t, data.FieldTypeNullableFloat64, table.Frame.Fields[2].Type())
+	require.Equal(t, data.Labels{"host": "b", "region": "us"}, table.RowLabels(1))
+
+	_, err := TableFromFrame("A", data.NewFrame("",
+		data.NewField("host", nil, []string{"a"}),
+	))
+	require.Error(t, err)
+}
+
+func TestTableExpressions(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "column returns a number per row labelled by the string columns",
+			expr:      `column($A, "cpu")`,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a", "region": "eu"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"host": "b", "region": "us"}, float64Pointer(90)),
+				makeNumber("", data.Labels{"host": "c", "region": "eu"}, float64Pointer(50)),
+			}},
+		},
+		{
+			name:      "select picks the label columns",
+			expr:      `column(select($A, "host, mem"), "mem")`,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b"}, nil),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(3)),
+			}},
+		},
+		{
+			name:      "math is applied to every numeric column",
+			expr:      `column($A * 2 + $A, "mem")`,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a", "region": "eu"}, float64Pointer(3)),
+				makeNumber("", data.Labels{"host": "b", "region": "us"}, nil),
+				makeNumber("", data.Labels{"host": "c", "region": "eu"}, float64Pointer(9)),
+			}},
+		},
+		{
+			name:      "filter keeps the rows that match the condition",
+			expr:      `column(filter($A, column($A, "cpu") > 20), "cpu")`,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "b", "region": "us"}, float64Pointer(90)),
+				makeNumber("", data.Labels{"host": "c", "region": "eu"}, float64Pointer(50)),
+			}},
+		},
+		{
+			name:      "column fails on a string column",
+			expr:      `column($A, "host")`,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "select fails on an unknown column",
+			expr:      `select($A, "disk")`,
+			execErrIs: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", Vars{"A": Results{[]Value{makeTable(t)}}})
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
+
+func TestTableVars(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected []string
+	}{
+		{`$A * 2`, nil},
+		{`abs($A)`, nil},
+		{`column($A, "cpu") > $B`, []string{"A"}},
+		{`column(select($A * 2, "host, cpu"), "cpu")`, []string{"A", "A"}},
+		{`filter($A, column($B, "cpu") > 1)`, []string{"A", "B"}},
+	}
+	for _, c := range cases {
+		t.Run(c.expr, func(t *testing.T) {
+			e, err := New(c.expr)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, e.TableVars())
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_union(t *testing.T) {
@@ -242,3 +243,145 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorMatching(t *testing.T) {
+	cpu := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"host": "a", "job": "prom"}, float64Pointer(4)),
+			makeNumber("", data.Labels{"host": "b", "job": "prom"}, float64Pointer(9)),
+		},
+	}
+	cores := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"host": "a", "source": "loki"}, float64Pointer(2)),
+			makeNumber("", data.Labels{"host": "b", "source": "loki"}, float64Pointer(3)),
+		},
+	}
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "on() matches overlapping label sets",
+			expr:      "$A / on(host) $B",
+			vars:      Vars{"A": cpu, "B": cores},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(2)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name:      "ignoring() matches overlapping label sets",
+			expr:      `$A * ignoring(job, "source") $B`,
+			vars:      Vars{"A": cpu, "B": cores},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(8)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(27)),
+				},
+			},
+		},
+		{
+			name: "group_left keeps labels of the many side and includes labels of the one side",
+			expr: "$A - on(host) group_left(source) $B",
+			vars: Vars{
+				"A": Results{
+					Values: Values{
+						makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(4)),
+						makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(5)),
+					},
+				},
+				"B": Results{Values: Values{cores.Values[0]}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"host": "a", "cpu": "0", "source": "loki"}, float64Pointer(2)),
+					makeNumber("", data.Labels{"host": "a", "cpu": "1", "source": "loki"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name: "group_right keeps the operand order",
+			expr: "$B - on(host) group_right $A",
+			vars: Vars{
+				"A": Results{
+					Values: Values{
+						makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(4)),
+						makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(5)),
+					},
+				},
+				"B": Results{Values: Values{cores.Values[0]}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(-2)),
+					makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(-3)),
+				},
+			},
+		},
+		{
+			name:      "scalars match every value",
+			expr:      "$A + on(host) 1",
+			vars:      Vars{"A": Results{Values: Values{cpu.Values[0]}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"host": "a", "job": "prom"}, float64Pointer(5)),
+				},
+			},
+		},
+		{
+			name: "unmatched series are an error",
+			expr: "$A / on(host) $B",
+			vars: Vars{
+				"A": cpu,
+				"B": Results{Values: Values{cores.Values[0]}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name: "many-to-many matching is an error",
+			expr: "$A / on(job) $B",
+			vars: Vars{
+				"A": cpu,
+				"B": Results{Values: Values{makeNumber("", data.Labels{"job": "prom"}, float64Pointer(2))}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "missing label list is a parse error",
+			expr:     "$A / on $B",
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				if err == nil {
+					require.Equal(t, tt.results, res)
+				}
+			}
+		})
+	}
+}
@@ -119,6 +119,12 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeHysteresis:
+		node.Command, err = UnmarshalHysteresisCommand(rn)
+	case TypeAggregate:
+		node.Command, err = UnmarshalAggregateCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -146,6 +152,9 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+	// tables is whether a math expression uses the results as tables, so the non time series
+	// frames with numeric fields are returned as tables rather than as series.
+	tables bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -221,7 +230,7 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 		},
 	}
 
-	resp, err := s.dataService.QueryData(ctx, &backend.QueryDataRequest{
+	resp, err := s.queryData(ctx, dn, &backend.QueryDataRequest{
 		PluginContext: pc,
 		Queries:       q,
 		Headers:       dn.request.Headers,
@@ -256,6 +265,15 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 
 		dataSource := dn.datasource.Type
 		for _, frame := range qr.Frames {
+			if dn.tables && frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot && isTable(frame) {
+				logger.Debug("expression datasource query (table)", "query", refID)
+				table, err := mathexp.TableFromFrame(refID, frame)
+				if err != nil {
+					return mathexp.Results{}, err
+				}
+				vals = append(vals, table)
+				continue
+			}
 			logger.Debug("expression datasource query (seriesSet)", "query", refID)
 			// Check for TimeSeriesTypeNot in InfluxDB queries. A data frame of this type will cause
 			// the WideToMany() function to error out, which results in unhealthy alerts.
@@ -299,6 +317,17 @@ func isNumberTable(frame *data.Frame) bool {
 	return numericCount == 1 && otherCount == 0
 }
 
+// isTable returns true if the frame has at least one numeric field, so it
+// can be used as a mathexp.Table.
+func isTable(frame *data.Frame) bool {
+	for _, field := range frame.Fields {
+		if field.Type().Numeric() {
+			return true
+		}
+	}
+	return false
+}
+
 func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 	numericField := 0
 	stringFieldIdxs := []int{}
@@ -2,8 +2,10 @@ package expr
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -39,6 +41,7 @@ type Service struct {
 	cfg            *setting.Cfg
 	dataService    backend.QueryDataHandler
 	secretsService secrets.Service
+	queryCache     *queryCache
 }
 
 func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, secretsService secrets.Service) *Service {
@@ -46,9 +49,18 @@ func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, secretsServic
 		cfg:            cfg,
 		dataService:    pluginClient,
 		secretsService: secretsService,
+		queryCache:     newQueryCache(cfg.ExpressionsQueryCacheTTL),
 	}
 }
 
+// maxConcurrentNodes returns how many nodes of a pipeline may be executed at the same time.
+func (s *Service) maxConcurrentNodes() int {
+	if s.cfg == nil || s.cfg.ExpressionsMaxConcurrentNodes < 1 {
+		return 1
+	}
+	return s.cfg.ExpressionsMaxConcurrentNodes
+}
+
 func (s *Service) isDisabled() bool {
 	if s.cfg == nil {
 		return true
@@ -64,18 +76,42 @@ func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 // ExecutePipeline executes an expression pipeline and returns all the results.
 func (s *Service) ExecutePipeline(ctx context.Context, pipeline DataPipeline) (*backend.QueryDataResponse, error) {
 	res := backend.NewQueryDataResponse()
-	vars, err := pipeline.execute(ctx, s)
+	vars, durations, err := pipeline.execute(ctx, s)
 	if err != nil {
 		return nil, err
 	}
+	// a frame can be the result of more than one node, e.g. for "$A" in a math expression
+	seen := make(map[*data.Frame]bool)
 	for refID, val := range vars {
+		frames := val.Values.AsDataFrames(refID)
+		for _, frame := range frames {
+			if !seen[frame] {
+				seen[frame] = true
+				addExecutionTimeStat(frame, durations[refID])
+			}
+		}
 		res.Responses[refID] = backend.DataResponse{
-			Frames: val.Values.AsDataFrames(refID),
+			Frames: frames,
 		}
 	}
 	return res, nil
 }
 
+// addExecutionTimeStat adds the time it took to execute the node that returned
+// the frame to the frame's meta stats.
+func addExecutionTimeStat(frame *data.Frame, d time.Duration) {
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Stats = append(frame.Meta.Stats, data.QueryStat{
+		FieldConfig: data.FieldConfig{
+			DisplayName: "Node execution time",
+			Unit:        "ms",
+		},
+		Value: float64(d) / float64(time.Millisecond),
+	})
+}
+
 func DataSourceModel() *models.DataSource {
 	return &models.DataSource{
 		Id:             DatasourceID,
@@ -68,6 +68,17 @@ func TestService(t *testing.T) {
 	res, err := s.ExecutePipeline(context.Background(), pl)
 	require.NoError(t, err)
 
+	// every frame has the execution time of its node in the stats, which is not
+	// deterministic so it is checked and removed before comparing the frames.
+	for _, r := range res.Responses {
+		for _, frame := range r.Frames {
+			require.NotNil(t, frame.Meta)
+			require.Len(t, frame.Meta.Stats, 1)
+			require.Equal(t, "Node execution time", frame.Meta.Stats[0].DisplayName)
+			frame.Meta = nil
+		}
+	}
+
 	bDF := data.NewFrame("",
 		data.NewField("Time", nil, []time.Time{time.Unix(1, 0)}),
 		data.NewField("B", nil, []*float64{fp(4)}))
@@ -98,6 +109,107 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceTableResults(t *testing.T) {
+	me := &mockEndpoint{
+		Frames: []*data.Frame{data.NewFrame("test",
+			data.NewField("host", nil, []string{"a", "b"}),
+			data.NewField("cpu", nil, []float64{10, 90}),
+			data.NewField("mem", nil, []int64{1, 2}),
+		)},
+	}
+	s := Service{
+		cfg:            setting.NewCfg(),
+		dataService:    me,
+		secretsService: secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()),
+	}
+
+	req := &Request{Queries: []Query{
+		{
+			RefID: "A",
+			DataSource: &models.DataSource{
+				OrgId: 1,
+				Uid:   "test",
+				Type:  "test",
+			},
+			JSON: json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "column($A, \"cpu\") > 50" }`),
+		},
+	}}
+
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), pl)
+	require.NoError(t, err)
+
+	require.Len(t, res.Responses["A"].Frames, 1)
+	require.Len(t, res.Responses["A"].Frames[0].Fields, 3)
+
+	frames := res.Responses["B"].Frames
+	require.Len(t, frames, 2)
+	for i, expected := range []struct {
+		labels data.Labels
+		value  float64
+	}{
+		{data.Labels{"host": "a"}, 0},
+		{data.Labels{"host": "b"}, 1},
+	} {
+		require.Equal(t, expected.labels, frames[i].Fields[0].Labels)
+		require.Equal(t, expected.value, *frames[i].Fields[0].At(0).(*float64))
+	}
+}
+
+func TestServiceInfluxDBNonTimeSeriesReduce(t *testing.T) {
+	// InfluxDB can return a frame without a time field next to the series. It is ignored rather
+	// than returned as a table, as reduce does not accept tables.
+	me := &mockEndpoint{
+		Frames: []*data.Frame{
+			data.NewFrame("series",
+				data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+				data.NewField("value", data.Labels{"host": "a"}, []*float64{fp(2), fp(4)})),
+			data.NewFrame("table",
+				data.NewField("host", nil, []string{"a"}),
+				data.NewField("cpu", nil, []float64{10}),
+				data.NewField("mem", nil, []float64{1})),
+		},
+	}
+	s := Service{
+		cfg:            setting.NewCfg(),
+		dataService:    me,
+		secretsService: secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()),
+	}
+
+	req := &Request{Queries: []Query{
+		{
+			RefID: "A",
+			DataSource: &models.DataSource{
+				OrgId: 1,
+				Uid:   "influx",
+				Type:  models.DS_INFLUXDB,
+			},
+			JSON: json.RawMessage(`{ "datasource": { "uid": "influx" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "expression": "A", "reducer": "mean" }`),
+		},
+	}}
+
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), pl)
+	require.NoError(t, err)
+
+	frames := res.Responses["B"].Frames
+	require.Len(t, frames, 1)
+	require.Equal(t, data.Labels{"host": "a"}, frames[0].Fields[0].Labels)
+	require.Equal(t, 3.0, *frames[0].Fields[0].At(0).(*float64))
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// ThresholdIsAbove is the threshold function for values greater than the threshold.
+	ThresholdIsAbove = "gt"
+	// ThresholdIsBelow is the threshold function for values less than the threshold.
+	ThresholdIsBelow = "lt"
+	// ThresholdIsWithinRange is the threshold function for values between the two thresholds.
+	ThresholdIsWithinRange = "within_range"
+	// ThresholdIsOutsideRange is the threshold function for values outside of the two thresholds.
+	ThresholdIsOutsideRange = "outside_range"
+)
+
+var supportedThresholdFuncs = []string{ThresholdIsAbove, ThresholdIsBelow, ThresholdIsWithinRange, ThresholdIsOutsideRange}
+
+// ThresholdCommand is an expression command that compares each Number, or the last
+// point of each Series, of its input against a threshold. It returns a Number per
+// input value that is 1 when the threshold is met, 0 when it is not, and nil when
+// the input has no value.
+type ThresholdCommand struct {
+	ReferenceVar  string
+	ThresholdFunc string
+	Conditions    []float64
+	refID         string
+	evaluator     classic.Evaluator
+}
+
+// ThresholdConditionJSON is the JSON model for a single threshold condition.
+type ThresholdConditionJSON struct {
+	Evaluator classic.ConditionEvalJSON `json:"evaluator"`
+}
+
+// NewThresholdCommand creates a new ThresholdCommand.
+func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions []float64) (*ThresholdCommand, error) {
+	switch thresholdFunc {
+	case ThresholdIsAbove, ThresholdIsBelow:
+		if len(conditions) < 1 {
+			return nil, fmt.Errorf("incorrect number of arguments for threshold function %s: got %d but need 1", thresholdFunc, len(conditions))
+		}
+	case ThresholdIsWithinRange, ThresholdIsOutsideRange:
+		if len(conditions) < 2 {
+			return nil, fmt.Errorf("incorrect number of arguments for threshold function %s: got %d but need 2", thresholdFunc, len(conditions))
+		}
+	default:
+		return nil, fmt.Errorf("expected threshold function to be one of [%s], got %s", strings.Join(supportedThresholdFuncs, ","), thresholdFunc)
+	}
+
+	evaluator, err := classic.NewEvaluator(classic.ConditionEvalJSON{
+		Params: conditions,
+		Type:   thresholdFunc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ThresholdCommand{
+		ReferenceVar:  referenceVar,
+		ThresholdFunc: thresholdFunc,
+		Conditions:    conditions,
+		refID:         refID,
+		evaluator:     evaluator,
+	}, nil
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	jsonFromM, err := json.Marshal(rn.Query["conditions"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal threshold expression body: %w", err)
+	}
+	var conditions []ThresholdConditionJSON
+	if err = json.Unmarshal(jsonFromM, &conditions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled threshold expression body: %w", err)
+	}
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("threshold expression for refId %v requires exactly one condition, got %d", rn.RefID, len(conditions))
+	}
+
+	firstCondition := conditions[0]
+	return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		var num mathexp.Number
+		switch v := val.(type) {
+		case mathexp.Number:
+			num = v
+		case mathexp.Series:
+			if v.Len() == 0 {
+				num = mathexp.NewNumber(tc.refID, v.GetLabels())
+				break
+			}
+			last, err := v.Reduce(tc.refID, "last", nil)
+			if err != nil {
+				return newRes, err
+			}
+			num = last
+		default:
+			return newRes, fmt.Errorf("can only apply a threshold to type number or series, got type %v", val.Type())
+		}
+		newRes.Values = append(newRes.Values, tc.evaluate(num))
+	}
+	return newRes, nil
+}
+
+// evaluate returns a new Number with the labels of num that holds the
+// result of the threshold evaluation.
+func (tc *ThresholdCommand) evaluate(num mathexp.Number) mathexp.Number {
+	var l data.Labels
+	if num.GetLabels() != nil {
+		l = num.GetLabels().Copy()
+	}
+	res := mathexp.NewNumber(tc.refID, l)
+
+	if num.GetFloat64Value() == nil {
+		res.SetValue(nil)
+		return res
+	}
+
+	var v float64
+	if tc.evaluator.Eval(num) {
+		v = 1
+	}
+	res.SetValue(&v)
+	return res
+}
@@ -0,0 +1,125 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestNewThresholdCommand(t *testing.T) {
+	var tests = []struct {
+		name          string
+		thresholdFunc string
+		conditions    []float64
+		isError       bool
+	}{
+		{
+			name:          "gt with one argument",
+			thresholdFunc: ThresholdIsAbove,
+			conditions:    []float64{80},
+		},
+		{
+			name:          "lt without arguments",
+			thresholdFunc: ThresholdIsBelow,
+			conditions:    []float64{},
+			isError:       true,
+		},
+		{
+			name:          "within_range with two arguments",
+			thresholdFunc: ThresholdIsWithinRange,
+			conditions:    []float64{80, 95},
+		},
+		{
+			name:          "outside_range with one argument",
+			thresholdFunc: ThresholdIsOutsideRange,
+			conditions:    []float64{80},
+			isError:       true,
+		},
+		{
+			name:          "unknown threshold function",
+			thresholdFunc: "no_value",
+			conditions:    []float64{80},
+			isError:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := NewThresholdCommand("B", "A", test.thresholdFunc, test.conditions)
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	q := `{
+		"expression" : "$A",
+		"type": "threshold",
+		"conditions": [{ "evaluator": { "type": "within_range", "params": [80, 95] } }]
+	}`
+	var qmap = make(map[string]interface{})
+	require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+
+	cmd, err := UnmarshalThresholdCommand(&rawNode{
+		RefID: "B",
+		Query: qmap,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "A", cmd.ReferenceVar)
+	require.Equal(t, ThresholdIsWithinRange, cmd.ThresholdFunc)
+	require.Equal(t, []float64{80, 95}, cmd.Conditions)
+
+	_, err = UnmarshalThresholdCommand(&rawNode{
+		RefID: "B",
+		Query: map[string]interface{}{"expression": "$A"},
+	})
+	require.Error(t, err)
+}
+
+func TestThresholdCommand_Execute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 2)
+	series.SetPoint(0, time.Unix(0, 0), fp(99))
+	series.SetPoint(1, time.Unix(60, 0), fp(90))
+
+	above := mathexp.NewNumber("A", data.Labels{"host": "b"})
+	above.SetValue(fp(96))
+
+	noValue := mathexp.NewNumber("A", data.Labels{"host": "c"})
+
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series, above, noValue}},
+	}
+
+	cmd, err := NewThresholdCommand("B", "A", ThresholdIsWithinRange, []float64{80, 95})
+	require.NoError(t, err)
+
+	res, err := cmd.Execute(context.Background(), vars)
+	require.NoError(t, err)
+	require.Len(t, res.Values, 3)
+
+	expected := []struct {
+		labels data.Labels
+		value  *float64
+	}{
+		{data.Labels{"host": "a"}, fp(1)},
+		{data.Labels{"host": "b"}, fp(0)},
+		{data.Labels{"host": "c"}, nil},
+	}
+	for i, e := range expected {
+		num, ok := res.Values[i].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, e.labels, num.GetLabels())
+		require.Equal(t, e.value, num.GetFloat64Value())
+	}
+}
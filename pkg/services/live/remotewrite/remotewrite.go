@@ -26,6 +26,18 @@ func SerializeLabelsColumn(frames ...*data.Frame) ([]byte, error) {
 
 // TimeSeriesFromFrames converts frames to slice of Prometheus TimeSeries.
 func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(makeMetricName, false, frames...)
+}
+
+// TimeSeriesFromFramesWithMetricName converts frames to slice of Prometheus TimeSeries
+// that all have the same metric name, instead of one made of the frame and field names.
+// Fields of several frames with the same labels result in one time series, with the
+// samples of the last of them.
+func TimeSeriesFromFramesWithMetricName(name string, frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(func(*data.Frame, *data.Field) string { return name }, true, frames...)
+}
+
+func timeSeriesFromFrames(metricName func(*data.Frame, *data.Field) string, unique bool, frames ...*data.Frame) []prompb.TimeSeries {
 	var entries = make(map[metricKey]prompb.TimeSeries)
 	var keys []metricKey // sorted keys.
 
@@ -39,8 +51,7 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 			if !field.Type().Numeric() {
 				continue
 			}
-			metricName := makeMetricName(frame, field)
-			metricName, ok := sanitizeMetricName(metricName)
+			metricName, ok := sanitizeMetricName(metricName(frame, field))
 			if !ok {
 				continue
 			}
@@ -78,8 +89,11 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 				Value: metricName,
 			})
 			promTimeSeries := prompb.TimeSeries{Labels: labelsCopy, Samples: samples}
+			// with unique keys, a series replaces the previous one with the same key, and is listed only once.
+			if _, ok := entries[key]; !ok || !unique {
+				keys = append(keys, key)
+			}
 			entries[key] = promTimeSeries
-			keys = append(keys, key)
 		}
 	}
 
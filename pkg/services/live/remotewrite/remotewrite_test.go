@@ -77,6 +77,48 @@ func TestTsFromFramesMultipleFrames(t *testing.T) {
 	require.Equal(t, 4.0, ts[1].Samples[1].Value)
 }
 
+func TestTsFromFramesWithMetricNameSameLabels(t *testing.T) {
+	t1 := time.Now()
+	t2 := time.Now().Add(time.Second)
+	frame1 := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", nil, []float64{1.0}),
+	)
+	frame2 := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{t2}),
+		data.NewField("value", nil, []float64{2.0}),
+	)
+	// the series of the last frame replaces the series of the first one.
+	ts := TimeSeriesFromFramesWithMetricName("recorded", frame1, frame2)
+	require.Len(t, ts, 1)
+	require.Len(t, ts[0].Samples, 1)
+	require.Equal(t, toSampleTime(t2), ts[0].Samples[0].Timestamp)
+	require.Equal(t, 2.0, ts[0].Samples[0].Value)
+}
+
+func TestTsFromFramesWithMetricName(t *testing.T) {
+	t1 := time.Now()
+	frame1 := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"instance": "a"}, []float64{1.0}),
+	)
+	frame2 := data.NewFrame("other",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"instance": "b"}, []float64{2.0}),
+	)
+	ts := TimeSeriesFromFramesWithMetricName("recorded", frame1, frame2)
+	require.Len(t, ts, 2)
+	for i, instance := range []string{"a", "b"} {
+		require.Len(t, ts[i].Labels, 2)
+		require.Equal(t, "instance", ts[i].Labels[0].Name)
+		require.Equal(t, instance, ts[i].Labels[0].Value)
+		require.Equal(t, "__name__", ts[i].Labels[1].Name)
+		require.Equal(t, "recorded", ts[i].Labels[1].Value)
+		require.Len(t, ts[i].Samples, 1)
+		require.Equal(t, float64(i+1), ts[i].Samples[0].Value)
+	}
+}
+
 func TestSerialize(t *testing.T) {
 	frame := data.NewFrame("test",
 		data.NewField("time", nil, []time.Time{time.Now(), time.Now().Add(time.Second)}),
@@ -28,10 +28,12 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	mam     *notifier.MultiOrgAlertmanager
-	secrets secrets.Service
-	store   AlertingStore
-	log     log.Logger
+	mam             *notifier.MultiOrgAlertmanager
+	secrets         secrets.Service
+	store           AlertingStore
+	provenanceStore store.ProvisioningStore
+	deliveryStore   store.NotificationDeliveryStore
+	log             log.Logger
 }
 
 type UnknownReceiverError struct {
@@ -154,12 +156,20 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
 	}
 
+	provisioned, err := hasProvisionedAlertmanagerResources(c.Req.Context(), srv.provenanceStore, c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance")
+	}
+	if provisioned {
+		return ErrResp(http.StatusBadRequest, errProvisionedResource, "failed to delete the Alertmanager configuration")
+	}
+
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
 		return errResp
 	}
 
-	if err := am.SaveAndApplyDefaultConfig(c.Req.Context()); err != nil {
+	if err := am.SaveAndApplyDefaultConfig(c.Req.Context(), c.UserId); err != nil {
 		srv.log.Error("unable to save and apply default alertmanager configuration", "err", err)
 		return ErrResp(http.StatusInternalServerError, err, "failed to save and apply default Alertmanager configuration")
 	}
@@ -205,6 +215,17 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		return ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
 	}
 
+	result, err := srv.gettableUserConfig(cfg)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// gettableUserConfig converts a stored configuration to the configuration returned to the clients,
+// that has the names of the secure settings instead of their values.
+func (srv AlertmanagerSrv) gettableUserConfig(cfg *apimodels.PostableUserConfig) (apimodels.GettableUserConfig, error) {
 	result := apimodels.GettableUserConfig{
 		TemplateFiles: cfg.TemplateFiles,
 		AlertmanagerConfig: apimodels.GettableApiAlertingConfig{
@@ -218,7 +239,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 			for k := range pr.SecureSettings {
 				decryptedValue, err := srv.getDecryptedSecret(pr, k)
 				if err != nil {
-					return ErrResp(http.StatusInternalServerError, err, "failed to decrypt stored secure setting: %s", k)
+					return apimodels.GettableUserConfig{}, fmt.Errorf("failed to decrypt stored secure setting: %s: %w", k, err)
 				}
 				if decryptedValue == "" {
 					continue
@@ -244,7 +265,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, &gettableApiReceiver)
 	}
 
-	return response.JSON(http.StatusOK, result)
+	return result, nil
 }
 
 func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *models.ReqContext) response.Response {
@@ -347,6 +368,16 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		}
 	}
 
+	if query.Result != nil {
+		currentConfig, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to load latest configuration")
+		}
+		if err := checkProvisionedAlertmanagerResources(c.Req.Context(), srv.provenanceStore, c.OrgId, currentConfig, &body); err != nil {
+			return toProvenanceErrorResponse(err, "failed to save and apply Alertmanager configuration")
+		}
+	}
+
 	if err := srv.loadSecureSettings(c.Req.Context(), c.OrgId, body.AlertmanagerConfig.Receivers); err != nil {
 		var unknownReceiverError UnknownReceiverError
 		if errors.As(err, &unknownReceiverError) {
@@ -367,7 +398,7 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		}
 	}
 
-	if err := am.SaveAndApplyConfig(c.Req.Context(), &body); err != nil {
+	if err := am.SaveAndApplyConfig(c.Req.Context(), &body, c.UserId); err != nil {
 		srv.log.Error("unable to save and apply alertmanager configuration", "err", err)
 		return ErrResp(http.StatusBadRequest, err, "failed to save and apply Alertmanager configuration")
 	}
@@ -422,6 +453,30 @@ func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body api
 	return response.JSON(statusForTestReceivers(result.Receivers), newTestReceiversResult(result))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestTemplates(c *models.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := am.TestTemplate(c.Req.Context(), body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrInvalidTemplate) || errors.Is(err, notifier.ErrUnknownIntegration) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if errors.Is(err, notifier.ErrAlertGroupNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to render templates")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
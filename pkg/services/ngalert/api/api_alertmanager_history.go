@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// defaultConfigHistoryLimit is the number of versions returned when the request has no limit.
+const defaultConfigHistoryLimit = 100
+
+// RouteGetAlertingConfigHistory returns the versions of the Alertmanager configuration of the organization, latest first.
+func (srv AlertmanagerSrv) RouteGetAlertingConfigHistory(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	limit := int(c.QueryInt64("limit"))
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit must not be negative"), "")
+	}
+	if limit == 0 {
+		limit = defaultConfigHistoryLimit
+	}
+
+	query := ngmodels.GetAlertmanagerConfigurationHistoryQuery{OrgID: c.OrgId, Limit: limit}
+	if err := srv.store.GetAlertmanagerConfigurationHistory(c.Req.Context(), &query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the configuration history")
+	}
+
+	result := make(apimodels.GettableAlertingConfigHistory, 0, len(query.Result))
+	for _, v := range query.Result {
+		result = append(result, apimodels.GettableAlertingConfigVersion{
+			ID:        v.ID,
+			CreatedAt: time.Unix(v.CreatedAt, 0).UTC(),
+			CreatedBy: v.CreatedByLogin,
+			Default:   v.Default,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetAlertingConfigDiff compares two versions of the Alertmanager configuration of the organization. The new
+// version is the latest version when the request does not have one.
+func (srv AlertmanagerSrv) RouteGetAlertingConfigDiff(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	baseID := c.QueryInt64("base")
+	if baseID <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("base must be the ID of a version"), "")
+	}
+	diffType := dashdiffs.ParseDiffType(c.Query("diffType"))
+
+	base, errResp := srv.getConfigVersion(c.Req.Context(), c.OrgId, baseID)
+	if errResp != nil {
+		return errResp
+	}
+	var latest *ngmodels.AlertConfiguration
+	if newID := c.QueryInt64("new"); newID > 0 {
+		latest, errResp = srv.getConfigVersion(c.Req.Context(), c.OrgId, newID)
+		if errResp != nil {
+			return errResp
+		}
+	} else {
+		query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
+		if err := srv.store.GetLatestAlertmanagerConfiguration(c.Req.Context(), &query); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+		}
+		latest = query.Result
+	}
+
+	baseData, err := srv.diffData(base)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to load the base version")
+	}
+	newData, err := srv.diffData(latest)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to load the new version")
+	}
+
+	contentType := "text/html"
+	if diffType == dashdiffs.DiffDelta {
+		contentType = "application/json"
+	}
+
+	result, err := dashdiffs.CalculateDiff(c.Req.Context(), &dashdiffs.Options{OrgId: c.OrgId, DiffType: diffType}, baseData, newData)
+	if err != nil {
+		if errors.Is(err, dashdiffs.ErrNilDiff) {
+			if diffType == dashdiffs.DiffDelta {
+				return response.Respond(http.StatusOK, []byte("{}")).SetHeader("Content-Type", contentType)
+			}
+			return response.Respond(http.StatusOK, []byte{}).SetHeader("Content-Type", contentType)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to compare the versions")
+	}
+
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", contentType)
+}
+
+// RoutePostAlertingConfigHistoryActivate restores a version of the Alertmanager configuration of the organization.
+// The version is saved again, as the latest version, and applied.
+func (srv AlertmanagerSrv) RoutePostAlertingConfigHistoryActivate(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid version ID")
+	}
+
+	version, errResp := srv.getConfigVersion(c.Req.Context(), c.OrgId, id)
+	if errResp != nil {
+		return errResp
+	}
+	cfg, err := notifier.Load([]byte(version.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to load the version")
+	}
+
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
+	if err := srv.store.GetLatestAlertmanagerConfiguration(c.Req.Context(), &query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+	}
+	currentConfig, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to load latest configuration")
+	}
+	if err := checkProvisionedAlertmanagerResources(c.Req.Context(), srv.provenanceStore, c.OrgId, currentConfig, cfg); err != nil {
+		return toProvenanceErrorResponse(err, "failed to restore the Alertmanager configuration")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		// It's okay if the alertmanager isn't ready yet, we're changing its config anyway.
+		if !errors.Is(errResp.Err(), notifier.ErrAlertmanagerNotReady) {
+			return errResp
+		}
+	}
+
+	// The secure settings of the version are already encrypted, so the version is saved as it is.
+	if err := am.SaveAndApplyConfig(c.Req.Context(), cfg, c.UserId); err != nil {
+		srv.log.Error("unable to restore alertmanager configuration", "id", id, "err", err)
+		return ErrResp(http.StatusBadRequest, err, "failed to restore the Alertmanager configuration")
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration restored"})
+}
+
+func (srv AlertmanagerSrv) getConfigVersion(ctx context.Context, orgID, id int64) (*ngmodels.AlertConfiguration, *response.NormalResponse) {
+	query := ngmodels.GetAlertmanagerConfigurationQuery{OrgID: orgID, ID: id}
+	if err := srv.store.GetAlertmanagerConfiguration(ctx, &query); err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, ErrResp(http.StatusNotFound, err, "version %d not found", id)
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get the version %d", id)
+	}
+	return query.Result, nil
+}
+
+// diffData returns the version of the configuration that is compared, without the values of the secure settings.
+func (srv AlertmanagerSrv) diffData(version *ngmodels.AlertConfiguration) (*simplejson.Json, error) {
+	cfg, err := notifier.Load([]byte(version.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, err
+	}
+	gettable, err := srv.gettableUserConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(gettable)
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(b)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertmanagerConfigHistory(t *testing.T) {
+	opsConfig := strings.ReplaceAll(validConfig, "grafana-default-email", "ops")
+
+	createSutWithHistory := func(t *testing.T) AlertmanagerSrv {
+		t.Helper()
+		sut := createSut(t)
+		configStore := sut.store.(FakeAlertingStore)
+		configStore.AddVersion(&ngmodels.AlertConfiguration{AlertmanagerConfiguration: validConfig, OrgID: 1, Default: true, CreatedAt: 1000})
+		configStore.AddVersion(&ngmodels.AlertConfiguration{AlertmanagerConfiguration: opsConfig, OrgID: 1, CreatedBy: 1, CreatedAt: 2000})
+		return sut
+	}
+
+	createRequest := func(role models.RoleType, url string, params map[string]string) *models.ReqContext {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if params != nil {
+			req = web.SetURLParams(req, params)
+		}
+		return &models.ReqContext{
+			Context: &web.Context{Req: req},
+			SignedInUser: &models.SignedInUser{
+				OrgRole: role,
+				OrgId:   1,
+				UserId:  1,
+			},
+		}
+	}
+
+	t.Run("should return the versions, latest first", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		response := sut.RouteGetAlertingConfigHistory(createRequest(models.ROLE_EDITOR, "/?limit=10", nil))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.GettableAlertingConfigHistory
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 2)
+		require.Equal(t, int64(2), result[0].ID)
+		require.Equal(t, int64(2000), result[0].CreatedAt.Unix())
+		require.False(t, result[0].Default)
+		require.Equal(t, int64(1), result[1].ID)
+		require.True(t, result[1].Default)
+	})
+
+	t.Run("should limit the versions", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		response := sut.RouteGetAlertingConfigHistory(createRequest(models.ROLE_EDITOR, "/?limit=1", nil))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.GettableAlertingConfigHistory
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 1)
+		require.Equal(t, int64(2), result[0].ID)
+	})
+
+	t.Run("should return 403 when not Editor", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		require.Equal(t, http.StatusForbidden, sut.RouteGetAlertingConfigHistory(createRequest(models.ROLE_VIEWER, "/", nil)).Status())
+		require.Equal(t, http.StatusForbidden, sut.RouteGetAlertingConfigDiff(createRequest(models.ROLE_VIEWER, "/?base=1", nil)).Status())
+		require.Equal(t, http.StatusForbidden, sut.RoutePostAlertingConfigHistoryActivate(createRequest(models.ROLE_VIEWER, "/", map[string]string{":id": "1"})).Status())
+	})
+
+	t.Run("should compare a version with the latest version", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		response := sut.RouteGetAlertingConfigDiff(createRequest(models.ROLE_EDITOR, "/?base=1&diffType=delta", nil))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		require.Contains(t, string(response.Body()), "grafana-default-email")
+		require.Contains(t, string(response.Body()), "ops")
+	})
+
+	t.Run("should return an empty diff for the same versions", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		response := sut.RouteGetAlertingConfigDiff(createRequest(models.ROLE_EDITOR, "/?base=2&new=2&diffType=delta", nil))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		require.JSONEq(t, "{}", string(response.Body()))
+	})
+
+	t.Run("should return 404 when the version does not exist", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		require.Equal(t, http.StatusNotFound, sut.RouteGetAlertingConfigDiff(createRequest(models.ROLE_EDITOR, "/?base=1&new=3", nil)).Status())
+		require.Equal(t, http.StatusNotFound, sut.RoutePostAlertingConfigHistoryActivate(createRequest(models.ROLE_EDITOR, "/", map[string]string{":id": "3"})).Status())
+	})
+
+	t.Run("should return 400 when the base version is missing", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		require.Equal(t, http.StatusBadRequest, sut.RouteGetAlertingConfigDiff(createRequest(models.ROLE_EDITOR, "/", nil)).Status())
+	})
+
+	t.Run("should restore a version", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+
+		response := sut.RoutePostAlertingConfigHistoryActivate(createRequest(models.ROLE_EDITOR, "/", map[string]string{":id": "1"}))
+
+		require.Equal(t, http.StatusAccepted, response.Status())
+	})
+
+	t.Run("should not restore a version without a provisioned contact point", func(t *testing.T) {
+		sut := createSutWithHistory(t)
+		require.NoError(t, sut.provenanceStore.SetProvenance(context.Background(), ngmodels.AlertmanagerResource{
+			OrgID: 1,
+			Type:  ngmodels.ResourceTypeContactPoint,
+			Name:  "ops",
+		}, ngmodels.ProvenanceFile))
+
+		response := sut.RoutePostAlertingConfigHistoryActivate(createRequest(models.ROLE_EDITOR, "/", map[string]string{":id": "1"}))
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		require.Contains(t, string(response.Body()), `contact point \"ops\"`)
+	})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// defaultNotificationLogLimit is the number of deliveries returned when the request has no limit.
+const defaultNotificationLogLimit = 100
+
+// RouteGetNotificationLog returns the deliveries of notifications by the integrations of the receivers
+// of the organization, newest first.
+func (srv AlertmanagerSrv) RouteGetNotificationLog(c *models.ReqContext) response.Response {
+	// The errors of the deliveries might contain the URLs of the integrations, which can be secure settings.
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	limit := int(c.QueryInt64("limit"))
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit must not be negative"), "")
+	}
+	if limit == 0 {
+		limit = defaultNotificationLogLimit
+	}
+
+	q := ngmodels.GetNotificationDeliveriesQuery{
+		OrgID:          c.OrgId,
+		Receiver:       c.Query("receiver"),
+		Integration:    c.Query("integration"),
+		IntegrationUID: c.Query("integrationUID"),
+		Failed:         c.QueryBool("failed"),
+		Limit:          limit,
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.UnixMilli(to)
+	}
+
+	if err := srv.deliveryStore.GetNotificationDeliveries(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification log")
+	}
+
+	result := apimodels.NotificationLogResponse{Entries: make([]apimodels.NotificationLogEntry, 0, len(q.Result))}
+	for _, d := range q.Result {
+		result.Entries = append(result.Entries, apimodels.NotificationLogEntry{
+			Receiver:       d.Receiver,
+			Integration:    d.Integration,
+			IntegrationUID: d.IntegrationUID,
+			GroupKey:       d.GroupKey,
+			Alerts:         d.Alerts,
+			Attempts:       d.Attempts,
+			StatusCode:     d.StatusCode,
+			Error:          d.Error,
+			Delivered:      d.Delivered(),
+			Duration:       d.Duration.String(),
+			SentAt:         d.SentAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetReceivers returns the receivers of the latest configuration of the organization, with the
+// last delivery of each of their integrations.
+func (srv AlertmanagerSrv) RouteGetReceivers(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
+	if err := srv.store.GetLatestAlertmanagerConfiguration(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get latest configuration")
+	}
+	cfg, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
+	}
+
+	q := ngmodels.GetLatestNotificationDeliveriesQuery{OrgID: c.OrgId}
+	if err := srv.deliveryStore.GetLatestNotificationDeliveries(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification log")
+	}
+	// The integrations of the default configuration do not have a UID, so the deliveries are
+	// matched by the position of the integration in its receiver.
+	type integrationKey struct {
+		receiver string
+		index    int
+	}
+	latest := make(map[integrationKey]*ngmodels.NotificationDelivery, len(q.Result))
+	for _, d := range q.Result {
+		latest[integrationKey{d.Receiver, d.IntegrationIndex}] = d
+	}
+
+	result := make(apimodels.ReceiversStatus, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+		status := apimodels.ReceiverStatus{
+			Name:         receiver.Name,
+			Integrations: make([]apimodels.IntegrationStatus, 0, len(receiver.GrafanaManagedReceivers)),
+		}
+		for i, integration := range receiver.GrafanaManagedReceivers {
+			integrationStatus := apimodels.IntegrationStatus{UID: integration.UID, Name: integration.Type}
+			// The delivery is ignored if the integration at this position changed since.
+			if last, ok := latest[integrationKey{receiver.Name, i}]; ok && last.IntegrationUID == integration.UID && last.Integration == integration.Type {
+				integrationStatus.LastNotifyAttempt = &last.SentAt
+				integrationStatus.LastNotifyAttemptDuration = last.Duration.String()
+				integrationStatus.LastNotifyAttemptStatusCode = last.StatusCode
+				integrationStatus.LastNotifyAttemptError = last.Error
+			}
+			status.Integrations = append(status.Integrations, integrationStatus)
+		}
+		result = append(result, status)
+	}
+	return response.JSON(http.StatusOK, result)
+}
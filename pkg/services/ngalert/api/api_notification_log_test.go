@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const receiversConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "grafana-default-email"
+		},
+		"receivers": [{
+			"name": "grafana-default-email",
+			"grafana_managed_receiver_configs": [{
+				"uid": "",
+				"name": "email receiver",
+				"type": "email",
+				"settings": {
+					"addresses": "<example@email.com>"
+				}
+			}, {
+				"uid": "",
+				"name": "email receiver",
+				"type": "email",
+				"settings": {
+					"addresses": "<ops@email.com>"
+				}
+			}]
+		}, {
+			"name": "ops",
+			"grafana_managed_receiver_configs": [{
+				"uid": "pd",
+				"name": "ops",
+				"type": "pagerduty",
+				"settings": {}
+			}, {
+				"uid": "slack",
+				"name": "ops",
+				"type": "slack",
+				"settings": {}
+			}]
+		}]
+	}
+}`
+
+func TestNotificationLog(t *testing.T) {
+	sentAt := time.Unix(1000, 0).UTC()
+
+	createSutWithDeliveries := func(t *testing.T) AlertmanagerSrv {
+		t.Helper()
+		sut := createSut(t)
+		sut.store.(FakeAlertingStore).AddVersion(&ngmodels.AlertConfiguration{AlertmanagerConfiguration: receiversConfig, OrgID: 1})
+		deliveryStore := &store.FakeNotificationDeliveryStore{}
+		for _, d := range []*ngmodels.NotificationDelivery{
+			{OrgID: 1, Receiver: "grafana-default-email", Integration: "email", Attempts: 1, Duration: time.Second, SentAt: sentAt},
+			{OrgID: 1, Receiver: "grafana-default-email", Integration: "email", Attempts: 3, Error: "failed to send email", SentAt: sentAt.Add(time.Minute)},
+			{OrgID: 1, Receiver: "ops", Integration: "pagerduty", IntegrationUID: "pd", Attempts: 1, StatusCode: http.StatusAccepted, SentAt: sentAt.Add(2 * time.Minute)},
+			{OrgID: 2, Receiver: "grafana-default-email", Integration: "email", Attempts: 1, SentAt: sentAt},
+		} {
+			require.NoError(t, deliveryStore.SaveNotificationDelivery(context.Background(), d))
+		}
+		sut.deliveryStore = deliveryStore
+		return sut
+	}
+
+	createRequest := func(role models.RoleType, url string) *models.ReqContext {
+		return &models.ReqContext{
+			Context: &web.Context{Req: httptest.NewRequest(http.MethodGet, url, nil)},
+			SignedInUser: &models.SignedInUser{
+				OrgRole: role,
+				OrgId:   1,
+			},
+		}
+	}
+
+	t.Run("should return the deliveries of the organization, newest first", func(t *testing.T) {
+		sut := createSutWithDeliveries(t)
+
+		response := sut.RouteGetNotificationLog(createRequest(models.ROLE_EDITOR, "/"))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.NotificationLogResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Entries, 3)
+		require.Equal(t, "pd", result.Entries[0].IntegrationUID)
+		require.Equal(t, http.StatusAccepted, result.Entries[0].StatusCode)
+		require.True(t, result.Entries[0].Delivered)
+		require.Equal(t, "failed to send email", result.Entries[1].Error)
+		require.False(t, result.Entries[1].Delivered)
+		require.Equal(t, 3, result.Entries[1].Attempts)
+		require.Equal(t, "1s", result.Entries[2].Duration)
+	})
+
+	t.Run("should filter the deliveries", func(t *testing.T) {
+		sut := createSutWithDeliveries(t)
+
+		response := sut.RouteGetNotificationLog(createRequest(models.ROLE_EDITOR, "/?receiver=grafana-default-email&failed=true"))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.NotificationLogResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Entries, 1)
+		require.Equal(t, "failed to send email", result.Entries[0].Error)
+	})
+
+	t.Run("should return 400 when the limit is negative", func(t *testing.T) {
+		sut := createSutWithDeliveries(t)
+
+		require.Equal(t, http.StatusBadRequest, sut.RouteGetNotificationLog(createRequest(models.ROLE_EDITOR, "/?limit=-1")).Status())
+	})
+
+	t.Run("should return 403 when not Editor", func(t *testing.T) {
+		sut := createSutWithDeliveries(t)
+
+		require.Equal(t, http.StatusForbidden, sut.RouteGetNotificationLog(createRequest(models.ROLE_VIEWER, "/")).Status())
+		require.Equal(t, http.StatusForbidden, sut.RouteGetReceivers(createRequest(models.ROLE_VIEWER, "/")).Status())
+	})
+
+	t.Run("should return the last delivery of each integration", func(t *testing.T) {
+		sut := createSutWithDeliveries(t)
+		second := &ngmodels.NotificationDelivery{OrgID: 1, Receiver: "grafana-default-email", Integration: "email", IntegrationIndex: 1, Attempts: 1, SentAt: sentAt.Add(3 * time.Minute)}
+		require.NoError(t, sut.deliveryStore.SaveNotificationDelivery(context.Background(), second))
+
+		response := sut.RouteGetReceivers(createRequest(models.ROLE_EDITOR, "/"))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.ReceiversStatus
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 2)
+		require.Equal(t, "grafana-default-email", result[0].Name)
+		require.Len(t, result[0].Integrations, 2)
+		email := result[0].Integrations[0]
+		require.Equal(t, "email", email.Name)
+		require.NotNil(t, email.LastNotifyAttempt)
+		require.Equal(t, sentAt.Add(time.Minute), email.LastNotifyAttempt.UTC())
+		require.Equal(t, "failed to send email", email.LastNotifyAttemptError)
+		secondEmail := result[0].Integrations[1]
+		require.Equal(t, "email", secondEmail.Name)
+		require.NotNil(t, secondEmail.LastNotifyAttempt)
+		require.Equal(t, sentAt.Add(3*time.Minute), secondEmail.LastNotifyAttempt.UTC())
+		require.Empty(t, secondEmail.LastNotifyAttemptError)
+
+		require.Equal(t, "ops", result[1].Name)
+		require.Len(t, result[1].Integrations, 2)
+		pagerduty := result[1].Integrations[0]
+		require.Equal(t, "pd", pagerduty.UID)
+		require.Equal(t, "pagerduty", pagerduty.Name)
+		require.Equal(t, http.StatusAccepted, pagerduty.LastNotifyAttemptStatusCode)
+		require.Empty(t, pagerduty.LastNotifyAttemptError)
+		slack := result[1].Integrations[1]
+		require.Equal(t, "slack", slack.UID)
+		require.Nil(t, slack.LastNotifyAttempt)
+	})
+}
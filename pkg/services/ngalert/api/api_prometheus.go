@@ -154,12 +154,40 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 		} else {
 			queryStr = string(encodedQuery)
 		}
+		// recording rules have no alerts, only the health and the last evaluation of the rule are known.
+		if rule.IsRecording() {
+			recordingRule := apimodels.Rule{
+				Name:   rule.Title,
+				Query:  queryStr,
+				Labels: rule.Labels,
+				Health: "ok",
+				Type:   apiv1.RuleTypeRecording,
+			}
+			if evaluation, ok := srv.manager.GetRecordingRuleEvaluation(c.OrgId, rule.UID); ok {
+				recordingRule.LastEvaluation = evaluation.EvaluationTime
+				recordingRule.EvaluationTime = evaluation.EvaluationDuration.Seconds()
+				if evaluation.Error != nil {
+					recordingRule.Health = "error"
+					recordingRule.LastError = evaluation.Error.Error()
+				}
+				if evaluation.EvaluationTime.After(newGroup.LastEvaluation) {
+					newGroup.LastEvaluation = evaluation.EvaluationTime
+				}
+			}
+			if rule.IsPaused {
+				recordingRule.Health = "paused"
+			}
+			newGroup.Rules = append(newGroup.Rules, apimodels.AlertingRule{Query: queryStr, Rule: recordingRule})
+			newGroup.Interval = float64(rule.IntervalSeconds)
+			continue
+		}
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       queryStr,
-			Duration:    rule.For.Seconds(),
-			Annotations: rule.Annotations,
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         queryStr,
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   rule.Annotations,
 		}
 
 		newRule := apimodels.Rule{
@@ -180,9 +208,14 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				Labels:      map[string]string(alertState.Labels),
 				Annotations: alertState.Annotations,
 				State:       alertState.State.String(),
+				StateReason: alertState.StateReason,
 				ActiveAt:    &activeAt,
 				Value:       valString, // TODO: set this once it is added to the evaluation results
 			}
+			if !alertState.KeepFiringSince.IsZero() {
+				keepFiringSince := alertState.KeepFiringSince
+				alert.KeepFiringSince = &keepFiringSince
+			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
 				newRule.LastEvaluation = alertState.LastEvaluationTime
@@ -212,6 +245,10 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			alertingRule.Alerts = append(alertingRule.Alerts, alert)
 		}
 
+		if rule.IsPaused {
+			newRule.Health = "paused"
+		}
+
 		alertingRule.Rule = newRule
 		newGroup.Rules = append(newGroup.Rules, alertingRule)
 		newGroup.Interval = float64(rule.IntervalSeconds)
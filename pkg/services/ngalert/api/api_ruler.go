@@ -28,6 +28,8 @@ type RulerSrv struct {
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	scheduleService schedule.ScheduleService
+	historian       store.StateHistoryStore
+	provenanceStore store.ProvisioningStore
 	log             log.Logger
 }
 
@@ -38,6 +40,17 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+	if err := checkProvisionedRules(c.Req.Context(), srv.provenanceStore, c.SignedInUser.OrgId, ruleUIDs(q.Result)); err != nil {
+		return toProvenanceErrorResponse(err, "failed to delete namespace alert rules")
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.Req.Context(), c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
@@ -60,6 +73,18 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := web.Params(c.Req)[":Groupname"]
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if err := checkProvisionedRules(c.Req.Context(), srv.provenanceStore, c.SignedInUser.OrgId, ruleUIDs(q.Result)); err != nil {
+		return toProvenanceErrorResponse(err, "failed to delete rule group")
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.Req.Context(), c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -267,6 +292,22 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		}
 	}
 
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	changedUIDs := ruleUIDs(q.Result)
+	for uid := range alertRuleUIDs {
+		changedUIDs = append(changedUIDs, uid)
+	}
+	if err := checkProvisionedRules(c.Req.Context(), srv.provenanceStore, c.SignedInUser.OrgId, changedUIDs); err != nil {
+		return toProvenanceErrorResponse(err, "failed to update rule group")
+	}
+
 	numOfNewRules := len(ruleGroupConfig.Rules) - len(alertRuleUIDs)
 	if numOfNewRules > 0 {
 		// quotas are checked in advanced
@@ -308,30 +349,50 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            r.Data,
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			NamespaceID:     namespaceID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			ID:                r.ID,
+			OrgID:             r.OrgID,
+			Title:             r.Title,
+			Condition:         r.Condition,
+			Data:              r.Data,
+			Updated:           r.Updated,
+			IntervalSeconds:   r.IntervalSeconds,
+			Version:           r.Version,
+			UID:               r.UID,
+			NamespaceUID:      r.NamespaceUID,
+			NamespaceID:       namespaceID,
+			RuleGroup:         r.RuleGroup,
+			NoDataState:       apimodels.NoDataState(r.NoDataState),
+			ExecErrState:      apimodels.ExecutionErrorState(r.ExecErrState),
+			IsPaused:          r.IsPaused,
+			Record:            r.Record,
+			Dependencies:      r.Dependencies,
+			EvaluationTimeout: model.Duration(r.EvaluationTimeout),
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
-		For:         model.Duration(r.For),
-		Annotations: r.Annotations,
-		Labels:      r.Labels,
+		For:           model.Duration(r.For),
+		KeepFiringFor: model.Duration(r.KeepFiringFor),
+		Annotations:   r.Annotations,
+		Labels:        r.Labels,
 	}
 	return gettableExtendedRuleNode
 }
 
+func ruleUIDs(rules []*ngmodels.AlertRule) []string {
+	uids := make([]string, 0, len(rules))
+	for _, r := range rules {
+		uids = append(uids, r.UID)
+	}
+	return uids
+}
+
+func toProvenanceErrorResponse(err error, msg string) response.Response {
+	if errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, msg)
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to get provenance")
+}
+
 func toNamespaceErrorResponse(err error) response.Response {
 	if errors.Is(err, ngmodels.ErrCannotEditNamespace) {
 		return ErrResp(http.StatusForbidden, err, err.Error())
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultStateHistoryLimit is the number of transitions returned when the request has no limit.
+const defaultStateHistoryLimit = 100
+
+// RouteGetStateHistory returns the transitions of the alert instances of the rules that
+// the user can see, newest first.
+func (srv RulerSrv) RouteGetStateHistory(c *models.ReqContext) response.Response {
+	if srv.historian == nil {
+		return ErrResp(http.StatusNotFound, errors.New("state history is not enabled"), "")
+	}
+
+	matchers := make(labels.Matchers, 0)
+	for _, s := range c.QueryStrings("matcher") {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid matcher")
+		}
+		matchers = append(matchers, m)
+	}
+
+	limit := int(c.QueryInt64("limit"))
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit must not be negative"), "")
+	}
+	if limit == 0 {
+		limit = defaultStateHistoryLimit
+	}
+
+	q := ngmodels.GetAlertStateHistoryQuery{
+		OrgID:    c.SignedInUser.OrgId,
+		RuleUID:  c.Query("ruleUID"),
+		Matchers: matchers,
+		Limit:    limit,
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.UnixMilli(to)
+	}
+
+	namespaceMap, err := srv.store.GetNamespaces(c.Req.Context(), c.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+
+	if q.RuleUID != "" {
+		ruleQuery := ngmodels.GetAlertRuleByUIDQuery{OrgID: q.OrgID, UID: q.RuleUID}
+		if err := srv.store.GetAlertRuleByUID(c.Req.Context(), &ruleQuery); err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return ErrResp(http.StatusNotFound, err, "")
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+		}
+		if _, ok := namespaceMap[ruleQuery.Result.NamespaceUID]; !ok {
+			return ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+		}
+	} else {
+		namespaceUIDs := make([]string, 0, len(namespaceMap))
+		for uid := range namespaceMap {
+			namespaceUIDs = append(namespaceUIDs, uid)
+		}
+		rulesQuery := ngmodels.ListAlertRulesQuery{OrgID: q.OrgID, NamespaceUIDs: namespaceUIDs}
+		if len(namespaceUIDs) > 0 {
+			if err := srv.store.GetOrgAlertRules(c.Req.Context(), &rulesQuery); err != nil {
+				return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+			}
+		}
+		// the history is filtered by the historian, so that the limit applies to the rules that the user can see.
+		q.RuleUIDs = make(map[string]struct{}, len(rulesQuery.Result))
+		for _, rule := range rulesQuery.Result {
+			q.RuleUIDs[rule.UID] = struct{}{}
+		}
+		if len(q.RuleUIDs) == 0 {
+			return response.JSON(http.StatusOK, apimodels.StateHistoryResponse{Entries: []apimodels.StateHistoryEntry{}})
+		}
+	}
+
+	if err := srv.historian.GetAlertStateHistory(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get state history")
+	}
+
+	result := apimodels.StateHistoryResponse{Entries: make([]apimodels.StateHistoryEntry, 0, len(q.Result))}
+	for _, entry := range q.Result {
+		result.Entries = append(result.Entries, apimodels.StateHistoryEntry{
+			RuleUID:       entry.RuleUID,
+			Labels:        entry.Labels,
+			PreviousState: string(entry.PreviousState),
+			State:         string(entry.State),
+			Reason:        entry.Reason,
+			Values:        entry.Values,
+			EvaluatedAt:   entry.EvaluatedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/expr"
@@ -14,9 +15,12 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -27,6 +31,7 @@ type TestingApiSrv struct {
 	DatasourceCache   datasources.CacheService
 	log               log.Logger
 	secretsService    secrets.Service
+	backtesting       *backtesting.Engine
 }
 
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -89,10 +94,77 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 	}
 
 	evaluator := eval.NewEvaluator(srv.Cfg, srv.log, srv.DatasourceCache, srv.secretsService)
-	evalResults, err := evaluator.QueriesAndExpressionsEval(c.SignedInUser.OrgId, cmd.Data, now, srv.ExpressionService)
+	if cmd.Explain {
+		explanation, err := evaluator.QueriesAndExpressionsExplain(c.SignedInUser.OrgId, cmd.Data, now, srv.ExpressionService)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "Failed to explain queries and expressions")
+		}
+		return response.JSON(http.StatusOK, explanation)
+	}
+
+	evalResults, err := evaluator.QueriesAndExpressionsEval(c.SignedInUser.OrgId, cmd.Data, 0, now, srv.ExpressionService)
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "Failed to evaluate queries and expressions")
 	}
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) RouteBacktestConfig(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	interval := time.Duration(cmd.Interval)
+	if interval == 0 {
+		interval = srv.Cfg.UnifiedAlerting.BaseInterval
+	}
+	if interval <= 0 || interval%srv.Cfg.UnifiedAlerting.BaseInterval != 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("interval (%v) should be non-zero and divided exactly by scheduler interval: %v", interval, srv.Cfg.UnifiedAlerting.BaseInterval), "")
+	}
+
+	noDataState := ngmodels.NoData
+	if cmd.NoDataState != "" {
+		var err error
+		if noDataState, err = ngmodels.NoDataStateFromString(string(cmd.NoDataState)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+	execErrState := ngmodels.AlertingErrState
+	if cmd.ExecErrState != "" {
+		var err error
+		if execErrState, err = ngmodels.ErrStateFromString(string(cmd.ExecErrState)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+
+	condition := ngmodels.Condition{
+		Condition: cmd.Condition,
+		OrgID:     c.SignedInUser.OrgId,
+		Data:      cmd.Data,
+	}
+	if err := validateCondition(c.Req.Context(), condition, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           cmd.Title,
+		Condition:       cmd.Condition,
+		Data:            cmd.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		UID:             "backtesting-" + util.GenerateShortUID(),
+		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
+		For:             time.Duration(cmd.For),
+		KeepFiringFor:   time.Duration(cmd.KeepFiringFor),
+		Labels:          cmd.Labels,
+	}
+
+	frames, err := srv.backtesting.Test(c.Req.Context(), rule, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidRange) || errors.Is(err, backtesting.ErrTooManyEvaluations) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to backtest the alert rule")
+	}
+	return response.JSONStreaming(http.StatusOK, util.DynMap{
+		"frames": frames,
+	})
+}
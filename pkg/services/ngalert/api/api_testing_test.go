@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRouteBacktestConfig(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 10 * time.Second
+	srv := TestingApiSrv{Cfg: cfg}
+
+	reqCtx := func() *models.ReqContext {
+		return &models.ReqContext{
+			Context:      &web.Context{Req: httptest.NewRequest(http.MethodPost, "/api/v1/rule/backtest", nil)},
+			SignedInUser: &models.SignedInUser{OrgId: 1},
+		}
+	}
+
+	t.Run("should reject an unknown no data state", func(t *testing.T) {
+		resp := srv.RouteBacktestConfig(reqCtx(), apimodels.BacktestConfig{NoDataState: "Unknown"})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		require.Contains(t, string(resp.Body()), "unknown NoData state option Unknown")
+	})
+
+	t.Run("should reject an unknown execution error state", func(t *testing.T) {
+		resp := srv.RouteBacktestConfig(reqCtx(), apimodels.BacktestConfig{ExecErrState: "OK"})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		require.Contains(t, string(resp.Body()), "unknown Error state option OK")
+	})
+}
@@ -125,6 +125,10 @@ func (f *ForkedRulerApi) forkRouteGetGrafanaRuleGroupConfig(ctx *models.ReqConte
 	return f.GrafanaRuler.RouteGetRulegGroupConfig(ctx)
 }
 
+func (f *ForkedRulerApi) forkRouteGetGrafanaStateHistory(ctx *models.ReqContext) response.Response {
+	return f.GrafanaRuler.RouteGetStateHistory(ctx)
+}
+
 func (f *ForkedRulerApi) forkRouteGetGrafanaRulesConfig(ctx *models.ReqContext) response.Response {
 	return f.GrafanaRuler.RouteGetRulesConfig(ctx)
 }
@@ -183,6 +183,26 @@ func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaAlertingConfig(ctx *models.Re
 	return f.GrafanaSvc.RouteGetAlertingConfig(ctx)
 }
 
+func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaAlertingConfigHistory(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetAlertingConfigHistory(ctx)
+}
+
+func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaAlertingConfigDiff(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetAlertingConfigDiff(ctx)
+}
+
+func (f *ForkedAlertmanagerApi) forkRoutePostGrafanaAlertingConfigHistoryActivate(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RoutePostAlertingConfigHistoryActivate(ctx)
+}
+
+func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaNotificationLog(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetNotificationLog(ctx)
+}
+
+func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaReceivers(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetReceivers(ctx)
+}
+
 func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaSilence(ctx *models.ReqContext) response.Response {
 	return f.GrafanaSvc.RouteGetSilence(ctx)
 }
@@ -202,3 +222,7 @@ func (f *ForkedAlertmanagerApi) forkRoutePostGrafanaAlertingConfig(ctx *models.R
 func (f *ForkedAlertmanagerApi) forkRoutePostTestGrafanaReceivers(ctx *models.ReqContext, conf apimodels.TestReceiversConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
+
+func (f *ForkedAlertmanagerApi) forkRoutePostTestGrafanaTemplates(ctx *models.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
+	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
+}
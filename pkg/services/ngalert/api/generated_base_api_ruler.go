@@ -25,6 +25,7 @@ type RulerApiForkingService interface {
 	RouteDeleteRuleGroupConfig(*models.ReqContext) response.Response
 	RouteGetGrafanaRuleGroupConfig(*models.ReqContext) response.Response
 	RouteGetGrafanaRulesConfig(*models.ReqContext) response.Response
+	RouteGetGrafanaStateHistory(*models.ReqContext) response.Response
 	RouteGetNamespaceGrafanaRulesConfig(*models.ReqContext) response.Response
 	RouteGetNamespaceRulesConfig(*models.ReqContext) response.Response
 	RouteGetRulegGroupConfig(*models.ReqContext) response.Response
@@ -57,6 +58,10 @@ func (f *ForkedRulerApi) RouteGetGrafanaRulesConfig(ctx *models.ReqContext) resp
 	return f.forkRouteGetGrafanaRulesConfig(ctx)
 }
 
+func (f *ForkedRulerApi) RouteGetGrafanaStateHistory(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetGrafanaStateHistory(ctx)
+}
+
 func (f *ForkedRulerApi) RouteGetNamespaceGrafanaRulesConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetNamespaceGrafanaRulesConfig(ctx)
 }
@@ -151,6 +156,16 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiForkingService, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/history"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/history",
+				srv.RouteGetGrafanaStateHistory,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}"),
 			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/rules/{Namespace}"),
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/prometheus/alertmanager/config"
+)
+
+// errProvisionedResource is returned when a request changes or deletes an object that is provisioned from a file.
+// Such objects can only be changed by changing the provisioning files.
+var errProvisionedResource = errors.New("cannot change or delete an object provisioned from a file")
+
+// checkProvisionedRules returns errProvisionedResource if any of the alert rules is provisioned from a file.
+func checkProvisionedRules(ctx context.Context, provenanceStore store.ProvisioningStore, orgID int64, ruleUIDs []string) error {
+	if len(ruleUIDs) == 0 {
+		return nil
+	}
+	provenances, err := provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return err
+	}
+	for _, uid := range ruleUIDs {
+		if provenances[uid] == ngmodels.ProvenanceFile {
+			return fmt.Errorf("%w: alert rule %s", errProvisionedResource, uid)
+		}
+	}
+	return nil
+}
+
+// checkProvisionedAlertmanagerResources returns errProvisionedResource if the posted configuration changes or removes
+// any contact point, template, mute timing or notification policy of the current configuration that is provisioned
+// from a file. It must be called before the secure settings of the current configuration are copied into the posted one.
+func checkProvisionedAlertmanagerResources(ctx context.Context, provenanceStore store.ProvisioningStore, orgID int64, current, posted *apimodels.PostableUserConfig) error {
+	if current == nil {
+		return nil
+	}
+
+	contactPoints, err := provisionedResources(ctx, provenanceStore, orgID, ngmodels.ResourceTypeContactPoint)
+	if err != nil {
+		return err
+	}
+	for name := range contactPoints {
+		cur := findReceiver(current.AlertmanagerConfig.Receivers, name)
+		if cur == nil {
+			continue
+		}
+		if !receiverUnchanged(cur, findReceiver(posted.AlertmanagerConfig.Receivers, name)) {
+			return fmt.Errorf("%w: contact point %q", errProvisionedResource, name)
+		}
+	}
+
+	templates, err := provisionedResources(ctx, provenanceStore, orgID, ngmodels.ResourceTypeTemplate)
+	if err != nil {
+		return err
+	}
+	for name := range templates {
+		cur, ok := current.TemplateFiles[name]
+		if !ok {
+			continue
+		}
+		if tmpl, ok := posted.TemplateFiles[name]; !ok || tmpl != cur {
+			return fmt.Errorf("%w: template %q", errProvisionedResource, name)
+		}
+	}
+
+	muteTimings, err := provisionedResources(ctx, provenanceStore, orgID, ngmodels.ResourceTypeMuteTiming)
+	if err != nil {
+		return err
+	}
+	for name := range muteTimings {
+		cur := findMuteTiming(current.AlertmanagerConfig.MuteTimeIntervals, name)
+		if cur == nil {
+			continue
+		}
+		if !jsonEqual(cur, findMuteTiming(posted.AlertmanagerConfig.MuteTimeIntervals, name)) {
+			return fmt.Errorf("%w: mute timing %q", errProvisionedResource, name)
+		}
+	}
+
+	policies, err := provisionedResources(ctx, provenanceStore, orgID, ngmodels.ResourceTypeNotificationPolicy)
+	if err != nil {
+		return err
+	}
+	if _, ok := policies[ngmodels.NotificationPolicyResourceID]; ok {
+		if !jsonEqual(current.AlertmanagerConfig.Route, posted.AlertmanagerConfig.Route) {
+			return fmt.Errorf("%w: notification policy tree", errProvisionedResource)
+		}
+	}
+
+	return nil
+}
+
+// hasProvisionedAlertmanagerResources returns true if any object of the Alertmanager configuration of the organization
+// is provisioned from a file.
+func hasProvisionedAlertmanagerResources(ctx context.Context, provenanceStore store.ProvisioningStore, orgID int64) (bool, error) {
+	for _, resourceType := range []string{
+		ngmodels.ResourceTypeContactPoint,
+		ngmodels.ResourceTypeTemplate,
+		ngmodels.ResourceTypeMuteTiming,
+		ngmodels.ResourceTypeNotificationPolicy,
+	} {
+		resources, err := provisionedResources(ctx, provenanceStore, orgID, resourceType)
+		if err != nil {
+			return false, err
+		}
+		if len(resources) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func provisionedResources(ctx context.Context, provenanceStore store.ProvisioningStore, orgID int64, resourceType string) (map[string]struct{}, error) {
+	provenances, err := provenanceStore.GetProvenances(ctx, orgID, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]struct{}, len(provenances))
+	for id, p := range provenances {
+		if p == ngmodels.ProvenanceFile {
+			result[id] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+func findReceiver(receivers []*apimodels.PostableApiReceiver, name string) *apimodels.PostableApiReceiver {
+	for _, r := range receivers {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func findMuteTiming(muteTimings []config.MuteTimeInterval, name string) *config.MuteTimeInterval {
+	for i := range muteTimings {
+		if muteTimings[i].Name == name {
+			return &muteTimings[i]
+		}
+	}
+	return nil
+}
+
+// receiverUnchanged returns true if the posted receiver has the same integrations as the current one. The secure
+// settings are not returned to the clients, so the posted receiver keeps the current secure settings when it has none.
+// Otherwise, they must be the same as the stored ones, which is the case only when a stored configuration is posted again.
+func receiverUnchanged(current, posted *apimodels.PostableApiReceiver) bool {
+	if posted == nil || len(current.GrafanaManagedReceivers) != len(posted.GrafanaManagedReceivers) {
+		return false
+	}
+	for i, cur := range current.GrafanaManagedReceivers {
+		n := posted.GrafanaManagedReceivers[i]
+		if cur.UID != n.UID || cur.Name != n.Name || cur.Type != n.Type || cur.DisableResolveMessage != n.DisableResolveMessage {
+			return false
+		}
+		if (len(n.SecureSettings) > 0 && !reflect.DeepEqual(cur.SecureSettings, n.SecureSettings)) || !settingsEqual(cur.Settings, n.Settings) {
+			return false
+		}
+	}
+	return true
+}
+
+func settingsEqual(a, b *simplejson.Json) bool {
+	am, bm := settingsMap(a), settingsMap(b)
+	if len(am) == 0 && len(bm) == 0 {
+		return true
+	}
+	return jsonEqual(am, bm)
+}
+
+func settingsMap(settings *simplejson.Json) map[string]interface{} {
+	if settings == nil {
+		return nil
+	}
+	return settings.MustMap()
+}
+
+// jsonEqual compares two values by their JSON representation.
+func jsonEqual(a, b interface{}) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var av, bv interface{}
+	if err := json.Unmarshal(aj, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(bj, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/stretchr/testify/require"
+)
+
+const provisionedConfig = `{
+	"template_files": {
+		"email": "{{ define \"email\" }}{{ .Status }}{{ end }}"
+	},
+	"alertmanager_config": {
+		"route": {
+			"receiver": "email",
+			"routes": [{
+				"receiver": "email",
+				"object_matchers": [["team", "=", "ops"]],
+				"mute_time_intervals": ["weekends"]
+			}]
+		},
+		"mute_time_intervals": [{
+			"name": "weekends",
+			"time_intervals": [{"weekdays": ["saturday", "sunday"]}]
+		}],
+		"templates": null,
+		"receivers": [{
+			"name": "email",
+			"grafana_managed_receiver_configs": [{
+				"uid": "email-uid",
+				"name": "email",
+				"type": "email",
+				"disableResolveMessage": false,
+				"settings": {"addresses": "ops@example.com"},
+				"secureSettings": {}
+			}]
+		}]
+	}
+}`
+
+func TestCheckProvisionedAlertmanagerResources(t *testing.T) {
+	const orgID = 1
+
+	load := func(t *testing.T) *apimodels.PostableUserConfig {
+		t.Helper()
+		cfg, err := notifier.Load([]byte(provisionedConfig))
+		require.NoError(t, err)
+		return cfg
+	}
+
+	testCases := []struct {
+		name        string
+		provisioned []ngmodels.AlertmanagerResource
+		change      func(cfg *apimodels.PostableUserConfig)
+		expErr      string
+	}{
+		{
+			name:   "should allow any change when nothing is provisioned",
+			change: func(cfg *apimodels.PostableUserConfig) { cfg.AlertmanagerConfig.Receivers = nil },
+		},
+		{
+			name:        "should allow a configuration that keeps the provisioned objects",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeContactPoint, Name: "email"}, {Type: ngmodels.ResourceTypeTemplate, Name: "email"}, {Type: ngmodels.ResourceTypeMuteTiming, Name: "weekends"}, {Type: ngmodels.ResourceTypeNotificationPolicy, Name: ngmodels.NotificationPolicyResourceID}},
+			change: func(cfg *apimodels.PostableUserConfig) {
+				cfg.TemplateFiles["other"] = "{{ define \"other\" }}{{ end }}"
+			},
+		},
+		{
+			name:        "should reject removing a provisioned contact point",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeContactPoint, Name: "email"}},
+			change:      func(cfg *apimodels.PostableUserConfig) { cfg.AlertmanagerConfig.Receivers = nil },
+			expErr:      `contact point "email"`,
+		},
+		{
+			name:        "should reject changing the settings of a provisioned contact point",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeContactPoint, Name: "email"}},
+			change: func(cfg *apimodels.PostableUserConfig) {
+				cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].Settings = simplejson.NewFromAny(map[string]interface{}{"addresses": "dev@example.com"})
+			},
+			expErr: `contact point "email"`,
+		},
+		{
+			name:        "should reject setting a secure setting of a provisioned contact point",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeContactPoint, Name: "email"}},
+			change: func(cfg *apimodels.PostableUserConfig) {
+				cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].SecureSettings = map[string]string{"password": "secret"}
+			},
+			expErr: `contact point "email"`,
+		},
+		{
+			name:        "should reject changing a provisioned template",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeTemplate, Name: "email"}},
+			change:      func(cfg *apimodels.PostableUserConfig) { cfg.TemplateFiles["email"] = "" },
+			expErr:      `template "email"`,
+		},
+		{
+			name:        "should reject removing a provisioned mute timing",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeMuteTiming, Name: "weekends"}},
+			change:      func(cfg *apimodels.PostableUserConfig) { cfg.AlertmanagerConfig.MuteTimeIntervals = nil },
+			expErr:      `mute timing "weekends"`,
+		},
+		{
+			name:        "should reject changing a provisioned notification policy tree",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeNotificationPolicy, Name: ngmodels.NotificationPolicyResourceID}},
+			change:      func(cfg *apimodels.PostableUserConfig) { cfg.AlertmanagerConfig.Route.Routes = nil },
+			expErr:      "notification policy tree",
+		},
+		{
+			name:        "should ignore provisioned objects that are not in the current configuration",
+			provisioned: []ngmodels.AlertmanagerResource{{Type: ngmodels.ResourceTypeContactPoint, Name: "slack"}, {Type: ngmodels.ResourceTypeTemplate, Name: "slack"}},
+			change:      func(cfg *apimodels.PostableUserConfig) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provenanceStore := store.NewFakeProvisioningStore()
+			for _, r := range tc.provisioned {
+				r.OrgID = orgID
+				require.NoError(t, provenanceStore.SetProvenance(context.Background(), r, ngmodels.ProvenanceFile))
+			}
+			posted := load(t)
+			tc.change(posted)
+
+			err := checkProvisionedAlertmanagerResources(context.Background(), provenanceStore, orgID, load(t), posted)
+
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errProvisionedResource)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestCheckProvisionedRules(t *testing.T) {
+	provenanceStore := store.NewFakeProvisioningStore()
+	require.NoError(t, provenanceStore.SetProvenance(context.Background(), &ngmodels.AlertRule{OrgID: 1, UID: "file"}, ngmodels.ProvenanceFile))
+	require.NoError(t, provenanceStore.SetProvenance(context.Background(), &ngmodels.AlertRule{OrgID: 1, UID: "api"}, ngmodels.ProvenanceApi))
+
+	require.NoError(t, checkProvisionedRules(context.Background(), provenanceStore, 1, []string{"api", "none"}))
+	require.NoError(t, checkProvisionedRules(context.Background(), provenanceStore, 2, []string{"file"}))
+	require.ErrorIs(t, checkProvisionedRules(context.Background(), provenanceStore, 1, []string{"api", "file"}), errProvisionedResource)
+}
@@ -10,6 +10,7 @@ import (
 
 type FakeAlertingStore struct {
 	orgsWithConfig map[int64]bool
+	versions       map[int64][]*models.AlertConfiguration
 }
 
 func newFakeAlertingStore(t *testing.T) FakeAlertingStore {
@@ -17,16 +18,52 @@ func newFakeAlertingStore(t *testing.T) FakeAlertingStore {
 
 	return FakeAlertingStore{
 		orgsWithConfig: map[int64]bool{},
+		versions:       map[int64][]*models.AlertConfiguration{},
 	}
 }
 
+// AddVersion adds a version of the configuration of the organization, that becomes the latest.
+func (f FakeAlertingStore) AddVersion(cfg *models.AlertConfiguration) {
+	cfg.ID = int64(len(f.versions[cfg.OrgID]) + 1)
+	f.versions[cfg.OrgID] = append(f.versions[cfg.OrgID], cfg)
+	f.orgsWithConfig[cfg.OrgID] = true
+}
+
 func (f FakeAlertingStore) Setup(orgID int64) {
 	f.orgsWithConfig[orgID] = true
 }
 
 func (f FakeAlertingStore) GetLatestAlertmanagerConfiguration(_ context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error {
 	if _, ok := f.orgsWithConfig[query.OrgID]; ok {
+		if versions := f.versions[query.OrgID]; len(versions) > 0 {
+			query.Result = versions[len(versions)-1]
+		}
 		return nil
 	}
 	return store.ErrNoAlertmanagerConfiguration
 }
+
+func (f FakeAlertingStore) GetAlertmanagerConfiguration(_ context.Context, query *models.GetAlertmanagerConfigurationQuery) error {
+	for _, v := range f.versions[query.OrgID] {
+		if v.ID == query.ID {
+			query.Result = v
+			return nil
+		}
+	}
+	return store.ErrNoAlertmanagerConfiguration
+}
+
+func (f FakeAlertingStore) GetAlertmanagerConfigurationHistory(_ context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error {
+	versions := f.versions[query.OrgID]
+	result := make([]*models.AlertConfigurationVersionMeta, 0, len(versions))
+	for i := len(versions) - 1; i >= 0 && (query.Limit <= 0 || len(result) < query.Limit); i-- {
+		result = append(result, &models.AlertConfigurationVersionMeta{
+			ID:        versions[i].ID,
+			CreatedAt: versions[i].CreatedAt,
+			Default:   versions[i].Default,
+			CreatedBy: versions[i].CreatedBy,
+		})
+	}
+	query.Result = result
+	return nil
+}
@@ -70,6 +70,36 @@ import (
 //       200: Ack
 //       400: ValidationError
 
+// swagger:route GET /api/alertmanager/grafana/config/history alertmanager RouteGetGrafanaAlertingConfigHistory
+//
+// gets the versions of the Alerting config, the latest first
+//
+//     Responses:
+//       200: GettableAlertingConfigHistory
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/grafana/config/history/diff alertmanager RouteGetGrafanaAlertingConfigDiff
+//
+// compares two versions of the Alerting config
+//
+//     Produces:
+//     - application/json
+//     - text/html
+//
+//     Responses:
+//       200: AlertingConfigDiff
+//       400: ValidationError
+//       404: ValidationError
+
+// swagger:route POST /api/alertmanager/grafana/config/history/{id}/_activate alertmanager RoutePostGrafanaAlertingConfigHistoryActivate
+//
+// restores a version of the Alerting config, that is saved again as the latest version
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: ValidationError
+
 // swagger:route GET /api/alertmanager/grafana/api/v2/status alertmanager RouteGetGrafanaAMStatus
 //
 // get alertmanager status and configuration
@@ -446,6 +476,56 @@ type BodyAlertingConfig struct {
 	Body PostableUserConfig
 }
 
+// swagger:parameters RouteGetGrafanaAlertingConfigHistory
+type AlertingConfigHistoryParams struct {
+	// Maximum number of versions to return. Default is 100.
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RouteGetGrafanaAlertingConfigDiff
+type AlertingConfigDiffParams struct {
+	// ID of the version to compare from
+	// in: query
+	// required: true
+	Base int64 `json:"base"`
+	// ID of the version to compare to. Default is the latest version.
+	// in: query
+	// required: false
+	New int64 `json:"new"`
+	// Format of the diff: json, basic or delta. The json and basic formats are HTML. Default is basic.
+	// in: query
+	// required: false
+	DiffType string `json:"diffType"`
+}
+
+// swagger:parameters RoutePostGrafanaAlertingConfigHistoryActivate
+type AlertingConfigVersionParams struct {
+	// ID of the version
+	// in: path
+	ID int64 `json:"id"`
+}
+
+// swagger:model
+type GettableAlertingConfigHistory []GettableAlertingConfigVersion
+
+// swagger:model
+type GettableAlertingConfigVersion struct {
+	ID int64 `json:"id"`
+	// CreatedAt is the time the version was saved.
+	CreatedAt time.Time `json:"created_at"`
+	// CreatedBy is the login of the user that saved the version. It is empty when Grafana saved it.
+	CreatedBy string `json:"created_by,omitempty"`
+	// Default is true when the version is the default configuration.
+	Default bool `json:"default"`
+}
+
+// AlertingConfigDiff is the difference between two versions of the Alerting config, in the requested format.
+// The secure settings are not part of the diff.
+// swagger:model
+type AlertingConfigDiff string
+
 // alertmanager routes
 // swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestReceivers
 // ruler routes
@@ -253,12 +253,14 @@ func (c *GettableRuleGroupConfig) validate() error {
 }
 
 type ApiRuleNode struct {
-	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
-	Alert       string            `yaml:"alert,omitempty" json:"alert,omitempty"`
-	Expr        string            `yaml:"expr" json:"expr"`
-	For         model.Duration    `yaml:"for,omitempty" json:"for,omitempty"`
-	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
-	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Record string         `yaml:"record,omitempty" json:"record,omitempty"`
+	Alert  string         `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Expr   string         `yaml:"expr" json:"expr"`
+	For    model.Duration `yaml:"for,omitempty" json:"for,omitempty"`
+	// KeepFiringFor is how long the alerts keep firing after the condition is no longer met.
+	KeepFiringFor model.Duration    `yaml:"keep_firing_for,omitempty" json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
 type RuleType int
@@ -366,22 +368,38 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// IsPaused stops the evaluation of the alert rule, keeping the state of its alert instances.
+	IsPaused bool `json:"is_paused" yaml:"is_paused"`
+	// Record makes the rule a recording rule that writes the series of the condition
+	// as a metric with this name, instead of alerting on it.
+	Record string `json:"record,omitempty" yaml:"record,omitempty"`
+	// Dependencies suppress the alert instances of the rule while the alert rules
+	// they reference have firing alert instances that match their matchers.
+	Dependencies []models.RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// EvaluationTimeout is the maximum duration of an evaluation of the rule. It cannot be
+	// greater than the interval of the rule group. The evaluation timeout of the settings
+	// applies when it is not set.
+	EvaluationTimeout model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ID                int64                   `json:"id" yaml:"id"`
+	OrgID             int64                   `json:"orgId" yaml:"orgId"`
+	Title             string                  `json:"title" yaml:"title"`
+	Condition         string                  `json:"condition" yaml:"condition"`
+	Data              []models.AlertQuery     `json:"data" yaml:"data"`
+	Updated           time.Time               `json:"updated" yaml:"updated"`
+	IntervalSeconds   int64                   `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version           int64                   `json:"version" yaml:"version"`
+	UID               string                  `json:"uid" yaml:"uid"`
+	NamespaceUID      string                  `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID       int64                   `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup         string                  `json:"rule_group" yaml:"rule_group"`
+	NoDataState       NoDataState             `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState      ExecutionErrorState     `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused          bool                    `json:"is_paused" yaml:"is_paused"`
+	Record            string                  `json:"record,omitempty" yaml:"record,omitempty"`
+	Dependencies      []models.RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	EvaluationTimeout model.Duration          `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
 }
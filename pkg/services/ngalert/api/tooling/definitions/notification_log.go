@@ -0,0 +1,95 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/alertmanager/grafana/notifications/log alertmanager RouteGetGrafanaNotificationLog
+//
+// gets the deliveries of notifications by the integrations of the receivers, newest first
+//
+//     Responses:
+//       200: NotificationLogResponse
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/grafana/config/api/v1/receivers alertmanager RouteGetGrafanaReceivers
+//
+// gets the receivers with the status of the last delivery of each integration
+//
+//     Responses:
+//       200: ReceiversStatus
+//       400: ValidationError
+
+// swagger:parameters RouteGetGrafanaNotificationLog
+type NotificationLogParams struct {
+	// Name of the receiver
+	// in: query
+	Receiver string `json:"receiver"`
+	// Type of the integration, such as pagerduty or slack
+	// in: query
+	Integration string `json:"integration"`
+	// UID of the integration
+	// in: query
+	IntegrationUID string `json:"integrationUID"`
+	// Return only the deliveries that failed
+	// in: query
+	Failed bool `json:"failed"`
+	// Start of the time range, in epoch milliseconds
+	// in: query
+	From int64 `json:"from"`
+	// End of the time range, in epoch milliseconds
+	// in: query
+	To int64 `json:"to"`
+	// Maximum number of deliveries. Default is 100.
+	// in: query
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type NotificationLogResponse struct {
+	Entries []NotificationLogEntry `json:"entries"`
+}
+
+// swagger:model
+type NotificationLogEntry struct {
+	Receiver string `json:"receiver"`
+	// Integration is the type of the integration, such as pagerduty or slack.
+	Integration    string `json:"integration"`
+	IntegrationUID string `json:"integrationUid"`
+	GroupKey       string `json:"groupKey"`
+	// Alerts is the number of alerts in the notification.
+	Alerts int `json:"alerts"`
+	// Attempts is the number of times the integration tried to send the notification.
+	Attempts int `json:"attempts"`
+	// StatusCode is the HTTP status code of the last attempt, if the integration uses HTTP.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Error is the error of the last attempt, if the notification was not delivered.
+	Error     string    `json:"error,omitempty"`
+	Delivered bool      `json:"delivered"`
+	Duration  string    `json:"duration"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// swagger:model
+type ReceiversStatus []ReceiverStatus
+
+// swagger:model
+type ReceiverStatus struct {
+	Name         string              `json:"name"`
+	Integrations []IntegrationStatus `json:"integrations"`
+}
+
+// swagger:model
+type IntegrationStatus struct {
+	UID string `json:"uid"`
+	// Name is the type of the integration, such as pagerduty or slack.
+	Name string `json:"name"`
+	// LastNotifyAttempt is the time of the last delivery, if there is one.
+	LastNotifyAttempt *time.Time `json:"lastNotifyAttempt,omitempty"`
+	// LastNotifyAttemptDuration is the duration of the last delivery, including its retries.
+	LastNotifyAttemptDuration string `json:"lastNotifyAttemptDuration,omitempty"`
+	// LastNotifyAttemptStatusCode is the HTTP status code of the last attempt of the last delivery.
+	LastNotifyAttemptStatusCode int `json:"lastNotifyAttemptStatusCode,omitempty"`
+	// LastNotifyAttemptError is the error of the last delivery, if it failed.
+	LastNotifyAttemptError string `json:"lastNotifyAttemptError,omitempty"`
+}
@@ -101,6 +101,8 @@ type AlertingRule struct {
 	// required: true
 	Query    string  `json:"query,omitempty"`
 	Duration float64 `json:"duration,omitempty"`
+	// KeepFiringFor is how long, in seconds, the alerts keep firing after the condition is no longer met.
+	KeepFiringFor float64 `json:"keepFiringFor,omitempty"`
 	// required: true
 	Annotations overrideLabels `json:"annotations,omitempty"`
 	// required: true
@@ -135,6 +137,11 @@ type Alert struct {
 	// required: true
 	State    string     `json:"state"`
 	ActiveAt *time.Time `json:"activeAt"`
+	// KeepFiringSince is when the condition of the firing alert was no longer met.
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
+	// StateReason explains the state when it is not the result of the condition,
+	// such as the alert rule being paused or the alert being suppressed.
+	StateReason string `json:"stateReason,omitempty"`
 	// required: true
 	Value string `json:"value"`
 }
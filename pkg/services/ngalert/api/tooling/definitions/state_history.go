@@ -0,0 +1,54 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route Get /api/ruler/grafana/api/v1/history ruler RouteGetGrafanaStateHistory
+//
+// Get the transitions of alert instances between states, newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryResponse
+//       400: ValidationError
+
+// swagger:parameters RouteGetGrafanaStateHistory
+type StateHistoryParams struct {
+	// UID of the alert rule
+	// in: query
+	RuleUID string `json:"ruleUID"`
+	// Label matchers of the alert instances, such as team="a"
+	// in: query
+	Matcher []string `json:"matcher"`
+	// Start of the time range, in epoch milliseconds
+	// in: query
+	From int64 `json:"from"`
+	// End of the time range, in epoch milliseconds
+	// in: query
+	To int64 `json:"to"`
+	// Maximum number of transitions
+	// in: query
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryResponse struct {
+	Entries []StateHistoryEntry `json:"entries"`
+}
+
+// swagger:model
+type StateHistoryEntry struct {
+	RuleUID       string            `json:"ruleUid"`
+	Labels        map[string]string `json:"labels"`
+	PreviousState string            `json:"previousState"`
+	State         string            `json:"state"`
+	// Reason explains the transition when it is not the result of the condition,
+	// such as the error of the evaluation or the absence of data.
+	Reason string `json:"reason,omitempty"`
+	// Values contains the RefID and value of the reduce and math expressions of the evaluation.
+	Values      map[string]*float64 `json:"values,omitempty"`
+	EvaluatedAt time.Time           `json:"evaluatedAt"`
+}
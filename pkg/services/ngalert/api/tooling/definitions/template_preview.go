@@ -0,0 +1,65 @@
+package definitions
+
+import (
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/templates/test alertmanager RoutePostTestGrafanaTemplates
+//
+// Render the notifications of the integrations with a template, without saving it.
+//
+//     Responses:
+//       200: TestTemplatesResults
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: ValidationError
+//       409: AlertManagerNotReady
+
+// swagger:parameters RoutePostTestGrafanaTemplates
+type TestTemplatesConfigParams struct {
+	// in:body
+	Body TestTemplatesConfigBodyParams
+}
+
+type TestTemplatesConfigBodyParams struct {
+	// Template is the content of a template file, such as one with define blocks. It is parsed after
+	// the templates of the configuration, so its definitions replace theirs.
+	Template string `json:"template"`
+	// Title replaces the default template of the title of the integrations, as the title
+	// setting of a contact point does.
+	Title string `json:"title,omitempty"`
+	// Body replaces the default template of the body of the integrations, as the message
+	// setting of a contact point does.
+	Body string `json:"body,omitempty"`
+	// Integrations are the types of the integrations to render the notifications of, such as
+	// slack or email. Default is all the types.
+	Integrations []string `json:"integrations,omitempty"`
+	// Alerts are the alerts of the notifications. Default is a single firing test alert.
+	Alerts []amv2.PostableAlert `json:"alerts,omitempty"`
+	// AlertGroup selects a group of alerts of the Alertmanager to render the notifications of
+	// instead of the alerts.
+	AlertGroup *TestTemplatesAlertGroup `json:"alertGroup,omitempty"`
+}
+
+// TestTemplatesAlertGroup identifies a group of alerts by its receiver and its labels, as returned by
+// the alert groups of the Alertmanager.
+type TestTemplatesAlertGroup struct {
+	Receiver string         `json:"receiver"`
+	Labels   model.LabelSet `json:"labels"`
+}
+
+// swagger:model
+type TestTemplatesResults struct {
+	Results []TestTemplatesResult `json:"results"`
+}
+
+// swagger:model
+type TestTemplatesResult struct {
+	// Integration is the type of the integration, such as slack or email.
+	Integration string `json:"integration"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	// Error is the error of the rendering of the title or the body, if there is one.
+	Error string `json:"error,omitempty"`
+}
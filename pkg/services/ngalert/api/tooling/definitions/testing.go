@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -52,6 +53,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestConfig
+//
+// Test a rule against a range of time in the past
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -82,6 +97,47 @@ type EvalQueriesRequest struct {
 type EvalQueriesPayload struct {
 	Data []models.AlertQuery `json:"data"`
 	Now  time.Time           `json:"now"`
+	// Explain returns how each query and expression was executed instead of their results.
+	Explain bool `json:"explain,omitempty"`
+}
+
+// swagger:parameters RouteBacktestConfig
+type BacktestConfigRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Interval between the evaluations of the rule. It defaults to the base interval of the scheduler.
+	Interval model.Duration `json:"interval,omitempty"`
+
+	Condition     string              `json:"condition"`
+	Data          []models.AlertQuery `json:"data"`
+	Title         string              `json:"title"`
+	Labels        map[string]string   `json:"labels,omitempty"`
+	For           model.Duration      `json:"for,omitempty"`
+	KeepFiringFor model.Duration      `json:"keep_firing_for,omitempty"`
+	NoDataState   NoDataState         `json:"no_data_state"`
+	ExecErrState  ExecutionErrorState `json:"exec_err_state"`
+}
+
+// swagger:model
+type BacktestResult struct {
+	// Frames is an array of dataframes, one for each alert instance, with a time field and a state field
+	// with the labels of the alert instance. Each row is an evaluation of the rule, and the state of the
+	// alert instance after it.
+	Frames []BacktestFrame `json:"frames"`
+}
+
+// BacktestFrame is a dataframe in the JSON format of the dataframes.
+type BacktestFrame struct {
+	// Schema holds the name of the dataframe and the names, types and labels of its fields.
+	Schema map[string]interface{} `json:"schema"`
+	// Data holds the values of the fields of the dataframe, an array of values for each field.
+	Data map[string]interface{} `json:"data"`
 }
 
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
@@ -0,0 +1,135 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// MaxEvaluations is the maximum number of evaluations of a backtest.
+const MaxEvaluations = 1000
+
+var (
+	ErrInvalidRange       = errors.New("the start of the range must be before its end")
+	ErrInvalidInterval    = errors.New("the interval must be positive")
+	ErrTooManyEvaluations = fmt.Errorf("the range must not contain more than %d evaluations", MaxEvaluations)
+)
+
+// evaluateFunc evaluates the condition at the given time.
+type evaluateFunc func(condition *models.Condition, now time.Time) (eval.Results, error)
+
+// Engine evaluates alert rules over a range of time in the past.
+type Engine struct {
+	evaluate    evaluateFunc
+	externalURL *url.URL
+	log         log.Logger
+}
+
+func NewEngine(cfg *setting.Cfg, datasourceCache datasources.CacheService, secretsService secrets.Service, expressionService *expr.Service, externalURL *url.URL) *Engine {
+	logger := log.New("ngalert.backtesting")
+	evaluator := eval.NewEvaluator(cfg, logger, datasourceCache, secretsService)
+	return &Engine{
+		evaluate: func(condition *models.Condition, now time.Time) (eval.Results, error) {
+			return evaluator.ConditionEval(condition, now, expressionService)
+		},
+		externalURL: externalURL,
+		log:         logger,
+	}
+}
+
+// Test evaluates the alert rule at each interval of the rule between from and to, and feeds the
+// results to a state manager that lives only for the test. It returns a data frame per alert
+// instance with the time of each evaluation and the state of the alert instance after it.
+func (e *Engine) Test(ctx context.Context, rule *models.AlertRule, from, to time.Time) ([]*data.Frame, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	if rule.IntervalSeconds <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	if int64(to.Sub(from)/interval) >= MaxEvaluations {
+		return nil, ErrTooManyEvaluations
+	}
+
+	now := from
+	manager := state.NewEphemeralManager(e.log, e.externalURL, func() time.Time { return now })
+	defer manager.Close()
+
+	condition := models.Condition{
+		Condition: rule.Condition,
+		OrgID:     rule.OrgID,
+		Data:      rule.Data,
+
+		EvaluationTimeout: rule.EvaluationTimeout,
+	}
+
+	tl := newTimeline()
+	for ; !now.After(to); now = now.Add(interval) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results, err := e.evaluate(&condition, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the alert rule at %s: %w", now.Format(time.RFC3339), err)
+		}
+		for _, s := range manager.ProcessEvalResults(ctx, rule, results) {
+			tl.add(s, now)
+		}
+	}
+	e.log.Debug("alert rule backtested", "uid", rule.UID, "from", from, "to", to, "instances", len(tl.order))
+	return tl.frames(), nil
+}
+
+// timeline contains the states of each alert instance, in the order in which the alert instances
+// first appeared.
+type timeline struct {
+	order     []string
+	instances map[string]*instanceTimeline
+}
+
+type instanceTimeline struct {
+	labels data.Labels
+	times  []time.Time
+	states []string
+}
+
+func newTimeline() *timeline {
+	return &timeline{instances: make(map[string]*instanceTimeline)}
+}
+
+func (tl *timeline) add(s *state.State, t time.Time) {
+	it, ok := tl.instances[s.CacheId]
+	if !ok {
+		it = &instanceTimeline{labels: s.Labels.Copy()}
+		tl.instances[s.CacheId] = it
+		tl.order = append(tl.order, s.CacheId)
+	}
+	it.times = append(it.times, t)
+	it.states = append(it.states, s.State.String())
+}
+
+func (tl *timeline) frames() []*data.Frame {
+	frames := make([]*data.Frame, 0, len(tl.order))
+	for _, id := range tl.order {
+		it := tl.instances[id]
+		frames = append(frames, data.NewFrame("",
+			data.NewField("Time", nil, it.times),
+			data.NewField("State", it.labels, it.states),
+		))
+	}
+	return frames
+}
@@ -0,0 +1,95 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEngine_Test(t *testing.T) {
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := 10 * time.Second
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "backtest",
+		NamespaceUID:    "namespace",
+		Title:           "test",
+		IntervalSeconds: int64(interval.Seconds()),
+		For:             15 * time.Second,
+		NoDataState:     models.Alerting,
+		ExecErrState:    models.ErrorErrState,
+	}
+
+	// results contains the state of the evaluation at each interval from the start of the range.
+	results := []eval.State{eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.NoData, eval.Error}
+	var evaluated []time.Time
+	engine := &Engine{
+		evaluate: func(_ *models.Condition, now time.Time) (eval.Results, error) {
+			evaluated = append(evaluated, now)
+			result := eval.Result{State: results[now.Sub(from)/interval], EvaluatedAt: now}
+			switch result.State {
+			case eval.NoData:
+			case eval.Error:
+				result.Error = errors.New("failed to query")
+			default:
+				result.Instance = data.Labels{"instance": "a"}
+			}
+			return eval.Results{result}, nil
+		},
+		log: log.New("test"),
+	}
+
+	fieldValues := func(field *data.Field) []interface{} {
+		values := make([]interface{}, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			values = append(values, field.At(i))
+		}
+		return values
+	}
+
+	t.Run("should return the states of each alert instance", func(t *testing.T) {
+		frames, err := engine.Test(context.Background(), rule, from, from.Add(6*interval))
+		require.NoError(t, err)
+		require.Len(t, evaluated, len(results))
+		require.Len(t, frames, 2)
+
+		// the alert instance is pending for For before it fires.
+		require.Equal(t, "a", frames[0].Fields[1].Labels["instance"])
+		require.Equal(t, []interface{}{evaluated[0], evaluated[1], evaluated[2], evaluated[3], evaluated[4]}, fieldValues(frames[0].Fields[0]))
+		require.Equal(t, []interface{}{"Normal", "Pending", "Pending", "Alerting", "Normal"}, fieldValues(frames[0].Fields[1]))
+
+		// the alert instance without data fires because of NoDataState, and errors because of ExecErrState.
+		require.NotContains(t, frames[1].Fields[1].Labels, "instance")
+		require.Equal(t, []interface{}{evaluated[5], evaluated[6]}, fieldValues(frames[1].Fields[0]))
+		require.Equal(t, []interface{}{"Alerting", "Error"}, fieldValues(frames[1].Fields[1]))
+	})
+
+	t.Run("should fail when the range is invalid", func(t *testing.T) {
+		_, err := engine.Test(context.Background(), rule, from, from)
+		require.ErrorIs(t, err, ErrInvalidRange)
+	})
+
+	t.Run("should fail when the range contains too many evaluations", func(t *testing.T) {
+		_, err := engine.Test(context.Background(), rule, from, from.Add(MaxEvaluations*interval))
+		require.ErrorIs(t, err, ErrTooManyEvaluations)
+	})
+
+	t.Run("should fail when the evaluation fails", func(t *testing.T) {
+		failing := &Engine{
+			evaluate: func(_ *models.Condition, _ time.Time) (eval.Results, error) {
+				return nil, errors.New("boom")
+			},
+			log: log.New("test"),
+		}
+		_, err := failing.Test(context.Background(), rule, from, from.Add(interval))
+		require.EqualError(t, err, "failed to evaluate the alert rule at 2022-01-01T00:00:00Z: boom")
+	})
+}
@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"sort"
@@ -123,10 +124,15 @@ const (
 	// Error is the eval state for an alert rule condition
 	// that evaluated to Error.
 	Error
+
+	// Suppressed is the state of an alert instance that would be
+	// Alerting or Pending while a dependency of its alert rule is firing.
+	// It is never the result of an evaluation.
+	Suppressed
 )
 
 func (s State) String() string {
-	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error"}[s]
+	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error", "Suppressed"}[s]
 }
 
 // AlertExecCtx is the context provided for executing an alert condition.
@@ -575,30 +581,89 @@ func (evalResults Results) AsDataFrame() data.Frame {
 	return *frame
 }
 
-// ConditionEval executes conditions and evaluates the result.
+// ErrEvaluationTimedOut is the error of the evaluations that take longer than the evaluation timeout.
+var ErrEvaluationTimedOut = errors.New("evaluation timed out")
+
+// evaluationTimeout returns the timeout of an evaluation: the timeout of the alert rule if it is
+// set, or the evaluation timeout of the settings otherwise.
+func (e *Evaluator) evaluationTimeout(ruleTimeout time.Duration) time.Duration {
+	if ruleTimeout > 0 {
+		return ruleTimeout
+	}
+	return e.cfg.UnifiedAlerting.EvaluationTimeout
+}
+
+// evaluationContext returns the context of an evaluation, with the timeout if it is set.
+func evaluationContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// timeoutError returns ErrEvaluationTimedOut if the deadline of ctx was exceeded, or nil otherwise.
+func timeoutError(ctx context.Context, timeout time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrEvaluationTimedOut, timeout)
+	}
+	return nil
+}
+
+// ConditionEval executes conditions and evaluates the result. The result is an error
+// if the evaluation takes longer than the evaluation timeout of the condition, or
+// than the evaluation timeout of the settings if the condition has none.
 func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, expressionService *expr.Service) (Results, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.cfg.UnifiedAlerting.EvaluationTimeout)
+	timeout := e.evaluationTimeout(condition.EvaluationTimeout)
+	alertCtx, cancelFn := evaluationContext(timeout)
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log}
 
 	execResult := executeCondition(alertExecCtx, condition, now, expressionService, e.dataSourceCache, e.secretsService)
+	if err := timeoutError(alertCtx, timeout); err != nil {
+		execResult = ExecutionResults{Error: err}
+	}
 
 	evalResults := evaluateExecutionResult(execResult, now)
 	return evalResults, nil
 }
 
-// QueriesAndExpressionsEval executes queries and expressions and returns the result.
-func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, expressionService *expr.Service) (*backend.QueryDataResponse, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.cfg.UnifiedAlerting.EvaluationTimeout)
+// QueriesAndExpressionsEval executes queries and expressions and returns the result. The
+// evaluation timeout of the settings applies if timeout is zero.
+func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, timeout time.Duration, now time.Time, expressionService *expr.Service) (*backend.QueryDataResponse, error) {
+	timeout = e.evaluationTimeout(timeout)
+	alertCtx, cancelFn := evaluationContext(timeout)
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log}
 
 	execResult, err := executeQueriesAndExpressions(alertExecCtx, data, now, expressionService, e.dataSourceCache, e.secretsService)
+	if timeoutErr := timeoutError(alertCtx, timeout); timeoutErr != nil {
+		return nil, timeoutErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute conditions: %w", err)
 	}
 
 	return execResult, nil
 }
+
+// QueriesAndExpressionsExplain executes queries and expressions and returns how
+// each query and expression was executed.
+func (e *Evaluator) QueriesAndExpressionsExplain(orgID int64, data []models.AlertQuery, now time.Time, expressionService *expr.Service) (*expr.Explanation, error) {
+	alertCtx, cancelFn := evaluationContext(e.evaluationTimeout(0))
+	defer cancelFn()
+
+	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log}
+
+	queryDataReq, err := GetExprRequest(alertExecCtx, data, now, e.dataSourceCache, e.secretsService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build queries and expressions request: %w", err)
+	}
+
+	explanation, err := expressionService.Explain(alertCtx, queryDataReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain conditions: %w", err)
+	}
+	return explanation, nil
+}
@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -8,6 +9,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestEvaluateExecutionResult(t *testing.T) {
@@ -340,3 +343,61 @@ func TestEvaluateExecutionResultsNoData(t *testing.T) {
 		require.ElementsMatch(t, []string{"A,B", "C"}, refIDs)
 	})
 }
+
+func TestEvaluatorTimeoutError(t *testing.T) {
+	t.Run("the evaluation times out when the deadline is exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		err := timeoutError(ctx, time.Second)
+		require.ErrorIs(t, err, ErrEvaluationTimedOut)
+		require.EqualError(t, err, "evaluation timed out after 1s")
+
+		v := evaluateExecutionResult(ExecutionResults{Error: err}, time.Time{})
+		require.Len(t, v, 1)
+		require.Equal(t, Error, v[0].State)
+	})
+
+	t.Run("the evaluation does not time out before the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		require.NoError(t, timeoutError(ctx, time.Minute))
+	})
+
+	t.Run("the evaluation does not time out when it is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, timeoutError(ctx, time.Second))
+	})
+}
+
+func TestEvaluatorEvaluationTimeout(t *testing.T) {
+	e := &Evaluator{cfg: &setting.Cfg{UnifiedAlerting: setting.UnifiedAlertingSettings{EvaluationTimeout: time.Minute}}}
+
+	t.Run("the timeout of the rule applies when it is stricter than the settings", func(t *testing.T) {
+		timeout := e.evaluationTimeout(10 * time.Millisecond)
+		require.Equal(t, 10*time.Millisecond, timeout)
+
+		ctx, cancel := evaluationContext(timeout)
+		defer cancel()
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("the evaluation did not time out with the timeout of the rule")
+		}
+		err := timeoutError(ctx, timeout)
+		require.ErrorIs(t, err, ErrEvaluationTimedOut)
+		require.EqualError(t, err, "evaluation timed out after 10ms")
+	})
+
+	t.Run("the timeout of the settings applies when the rule has none", func(t *testing.T) {
+		require.Equal(t, time.Minute, e.evaluationTimeout(0))
+	})
+
+	t.Run("the evaluation has no timeout when none is set", func(t *testing.T) {
+		e := &Evaluator{cfg: &setting.Cfg{}}
+		ctx, cancel := evaluationContext(e.evaluationTimeout(0))
+		defer cancel()
+		_, ok := ctx.Deadline()
+		require.False(t, ok)
+	})
+}
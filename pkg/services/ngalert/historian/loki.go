@@ -0,0 +1,251 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	lokiPushPath  = "/loki/api/v1/push"
+	lokiQueryPath = "/loki/api/v1/query_range"
+
+	// stateHistoryLabelKey and stateHistoryLabelValue identify the streams of the state history in Loki.
+	stateHistoryLabelKey   = "from"
+	stateHistoryLabelValue = "state-history"
+	orgIDLabel             = "orgID"
+	ruleUIDLabel           = "ruleUID"
+
+	// lokiMaxLines is the maximum number of lines that are requested to Loki. The label
+	// matchers are applied to the lines returned by Loki, so the limit of the query can
+	// not be used.
+	lokiMaxLines = 5000
+
+	lokiTimeout = 30 * time.Second
+)
+
+// LokiConfig is the configuration of a Loki backend.
+type LokiConfig struct {
+	URL               *url.URL
+	TenantID          string
+	BasicAuthUser     string
+	BasicAuthPassword string
+}
+
+// LokiBackend saves the transitions of alert instances in Loki, a stream per alert rule.
+type LokiBackend struct {
+	cfg    LokiConfig
+	client *http.Client
+	log    log.Logger
+}
+
+func NewLokiBackend(cfg LokiConfig, logger log.Logger) *LokiBackend {
+	return &LokiBackend{
+		cfg:    cfg,
+		client: &http.Client{Timeout: lokiTimeout},
+		log:    logger,
+	}
+}
+
+// lokiEntry is a line of a state history stream.
+type lokiEntry struct {
+	Labels        map[string]string   `json:"labels"`
+	PreviousState string              `json:"previous"`
+	State         string              `json:"current"`
+	Reason        string              `json:"reason,omitempty"`
+	Values        map[string]*float64 `json:"values,omitempty"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiQueryResponse struct {
+	Data struct {
+		Result []lokiStream `json:"result"`
+	} `json:"data"`
+}
+
+// SaveAlertStateHistory pushes the transitions of alert instances to Loki.
+func (b *LokiBackend) SaveAlertStateHistory(ctx context.Context, entries []*models.AlertStateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	streams := make(map[string]*lokiStream)
+	var keys []string
+	for _, entry := range entries {
+		line, err := json.Marshal(lokiEntry{
+			Labels:        entry.Labels,
+			PreviousState: string(entry.PreviousState),
+			State:         string(entry.State),
+			Reason:        entry.Reason,
+			Values:        entry.Values,
+		})
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("%d/%s", entry.RuleOrgID, entry.RuleUID)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: map[string]string{
+				stateHistoryLabelKey: stateHistoryLabelValue,
+				orgIDLabel:           strconv.FormatInt(entry.RuleOrgID, 10),
+				ruleUIDLabel:         entry.RuleUID,
+			}}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.EvaluatedAt.UnixNano(), 10), string(line)})
+	}
+
+	req := lokiPushRequest{Streams: make([]lokiStream, 0, len(keys))}
+	for _, key := range keys {
+		req.Streams = append(req.Streams, *streams[key])
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.do(ctx, http.MethodPost, lokiPushPath, nil, bytes.NewReader(body))
+	return err
+}
+
+// GetAlertStateHistory queries the transitions of alert instances from Loki, newest first.
+func (b *LokiBackend) GetAlertStateHistory(ctx context.Context, query *models.GetAlertStateHistoryQuery) error {
+	selectors := []string{
+		fmt.Sprintf("%s=%q", stateHistoryLabelKey, stateHistoryLabelValue),
+		fmt.Sprintf("%s=%q", orgIDLabel, strconv.FormatInt(query.OrgID, 10)),
+	}
+	if query.RuleUID != "" {
+		selectors = append(selectors, fmt.Sprintf("%s=%q", ruleUIDLabel, query.RuleUID))
+	}
+
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := query.From
+	if from.IsZero() {
+		// Loki queries the last hour when start is not set.
+		from = to.Add(-time.Hour)
+	}
+
+	params := url.Values{}
+	params.Set("query", "{"+strings.Join(selectors, ",")+"}")
+	params.Set("start", strconv.FormatInt(from.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(to.UnixNano(), 10))
+	params.Set("direction", "backward")
+	params.Set("limit", strconv.Itoa(lokiMaxLines))
+
+	body, err := b.do(ctx, http.MethodGet, lokiQueryPath, params, nil)
+	if err != nil {
+		return err
+	}
+
+	var res lokiQueryResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("failed to parse the response of Loki: %w", err)
+	}
+
+	result := make([]*models.AlertStateHistoryEntry, 0)
+	for _, stream := range res.Data.Result {
+		orgID, err := strconv.ParseInt(stream.Stream[orgIDLabel], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid organisation in state history stream: %w", err)
+		}
+		for _, value := range stream.Values {
+			ts, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid timestamp in state history stream: %w", err)
+			}
+			var line lokiEntry
+			if err := json.Unmarshal([]byte(value[1]), &line); err != nil {
+				b.log.Warn("ignoring invalid line of state history stream", "ruleUID", stream.Stream[ruleUIDLabel], "err", err)
+				continue
+			}
+			entry := &models.AlertStateHistoryEntry{
+				RuleOrgID:     orgID,
+				RuleUID:       stream.Stream[ruleUIDLabel],
+				Labels:        line.Labels,
+				PreviousState: models.InstanceStateType(line.PreviousState),
+				State:         models.InstanceStateType(line.State),
+				Reason:        line.Reason,
+				Values:        line.Values,
+				EvaluatedAt:   time.Unix(0, ts).UTC(),
+			}
+			if query.Matches(entry) {
+				result = append(result, entry)
+			}
+		}
+	}
+
+	// the lines of each stream are sorted, but not the lines of different streams.
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].EvaluatedAt.After(result[j].EvaluatedAt)
+	})
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	query.Result = result
+	return nil
+}
+
+func (b *LokiBackend) do(ctx context.Context, method, path string, params url.Values, body io.Reader) ([]byte, error) {
+	u := *b.cfg.URL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	if params != nil {
+		u.RawQuery = params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", b.cfg.TenantID)
+	}
+	if b.cfg.BasicAuthUser != "" || b.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(b.cfg.BasicAuthUser, b.cfg.BasicAuthPassword)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Loki: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			b.log.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of Loki: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("request to Loki failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
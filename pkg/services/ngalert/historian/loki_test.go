@@ -0,0 +1,92 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestLokiBackend(t *testing.T) {
+	var pushed lokiPushRequest
+	var queries []url.Values
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		switch r.URL.Path {
+		case "/loki/loki/api/v1/push":
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &pushed))
+			w.WriteHeader(http.StatusNoContent)
+		case "/loki/loki/api/v1/query_range":
+			queries = append(queries, r.URL.Query())
+			res := lokiQueryResponse{}
+			res.Data.Result = pushed.Streams
+			require.NoError(t, json.NewEncoder(w).Encode(res))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL + "/loki/")
+	require.NoError(t, err)
+	backend := NewLokiBackend(LokiConfig{URL: u, TenantID: "tenant", BasicAuthUser: "user", BasicAuthPassword: "pass"}, log.New("test"))
+
+	start := time.Unix(1000, 0).UTC()
+	value := 1.0
+	entries := []*models.AlertStateHistoryEntry{
+		{RuleOrgID: 1, RuleUID: "a", Labels: models.InstanceLabels{"host": "1"}, PreviousState: models.InstanceStateNormal, State: models.InstanceStateFiring, Values: map[string]*float64{"B": &value}, EvaluatedAt: start},
+		{RuleOrgID: 1, RuleUID: "b", Labels: models.InstanceLabels{"host": "2"}, PreviousState: models.InstanceStateNormal, State: models.InstanceStateError, Reason: "failed", EvaluatedAt: start.Add(time.Minute)},
+		{RuleOrgID: 1, RuleUID: "a", Labels: models.InstanceLabels{"host": "2"}, PreviousState: models.InstanceStateFiring, State: models.InstanceStateNormal, EvaluatedAt: start.Add(2 * time.Minute)},
+	}
+	require.NoError(t, backend.SaveAlertStateHistory(context.Background(), entries))
+
+	t.Run("pushes a stream per rule", func(t *testing.T) {
+		require.Len(t, pushed.Streams, 2)
+		require.Equal(t, map[string]string{"from": "state-history", "orgID": "1", "ruleUID": "a"}, pushed.Streams[0].Stream)
+		require.Len(t, pushed.Streams[0].Values, 2)
+		require.Equal(t, "1000000000000", pushed.Streams[0].Values[0][0])
+		require.Equal(t, "tenant", headers[0].Get("X-Scope-OrgID"))
+		require.NotEmpty(t, headers[0].Get("Authorization"))
+	})
+
+	t.Run("queries the streams and filters by labels", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{
+			OrgID:    1,
+			RuleUID:  "a",
+			Matchers: labels.Matchers{{Type: labels.MatchEqual, Name: "host", Value: "2"}},
+			From:     start,
+			To:       start.Add(time.Hour),
+		}
+		require.NoError(t, backend.GetAlertStateHistory(context.Background(), q))
+		require.Equal(t, `{from="state-history",orgID="1",ruleUID="a"}`, queries[0].Get("query"))
+		require.Equal(t, "1000000000000", queries[0].Get("start"))
+
+		// the fake server returns all the streams
+		require.Len(t, q.Result, 2)
+		require.Equal(t, "a", q.Result[0].RuleUID)
+		require.Equal(t, models.InstanceStateNormal, q.Result[0].State)
+		require.Equal(t, start.Add(2*time.Minute), q.Result[0].EvaluatedAt)
+		require.Equal(t, "b", q.Result[1].RuleUID)
+		require.Equal(t, "failed", q.Result[1].Reason)
+	})
+
+	t.Run("applies the limit", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{OrgID: 1, Limit: 1}
+		require.NoError(t, backend.GetAlertStateHistory(context.Background(), q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, start.Add(2*time.Minute), q.Result[0].EvaluatedAt)
+	})
+}
@@ -50,6 +50,8 @@ type Scheduler struct {
 	EvalTotal                *prometheus.CounterVec
 	EvalFailures             *prometheus.CounterVec
 	EvalDuration             *prometheus.SummaryVec
+	EvalQueued               *prometheus.GaugeVec
+	EvalDropped              *prometheus.CounterVec
 	GetAlertRulesDuration    prometheus.Histogram
 	SchedulePeriodicDuration prometheus.Histogram
 }
@@ -161,6 +163,24 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org"},
 		),
+		EvalQueued: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_queued",
+				Help:      "The number of rule evaluations waiting for the concurrency limits.",
+			},
+			[]string{"org"},
+		),
+		EvalDropped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_dropped_total",
+				Help:      "The total number of rule evaluations dropped because they waited for the concurrency limits until the next evaluation.",
+			},
+			[]string{"org"},
+		),
 		GetAlertRulesDuration: promauto.With(r).NewHistogram(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr"
 )
 
@@ -92,6 +93,20 @@ func (aq *AlertQuery) IsExpression() (bool, error) {
 	return expr.IsDataSource(aq.DatasourceUID), nil
 }
 
+// IsHysteresisExpression returns true if the alert query is a hysteresis expression.
+func (aq *AlertQuery) IsHysteresisExpression() (bool, error) {
+	if !expr.IsDataSource(aq.DatasourceUID) {
+		return false, nil
+	}
+	var model struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(aq.Model, &model); err != nil {
+		return false, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	return model.Type == expr.TypeHysteresis.String(), nil
+}
+
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
 func (aq *AlertQuery) setMaxDatapoints() error {
 	if aq.modelProps == nil {
@@ -213,6 +228,33 @@ func (aq *AlertQuery) setQueryType() error {
 	return nil
 }
 
+// WithLoadedDimensions returns a copy of the query that, if the query is a hysteresis
+// expression, has the labels of the currently firing dimensions set in its model.
+// Any other query is returned unchanged.
+func (aq AlertQuery) WithLoadedDimensions(loaded []data.Labels) (AlertQuery, error) {
+	if !expr.IsDataSource(aq.DatasourceUID) {
+		return aq, nil
+	}
+	model := make(map[string]interface{})
+	if err := json.Unmarshal(aq.Model, &model); err != nil {
+		return aq, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if model["type"] != expr.TypeHysteresis.String() {
+		return aq, nil
+	}
+	if loaded == nil {
+		loaded = []data.Labels{}
+	}
+	model[expr.HysteresisLoadedDimensionsKey] = loaded
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return aq, fmt.Errorf("unable to marshal query model: %w", err)
+	}
+	aq.Model = raw
+	aq.modelProps = nil
+	return aq, nil
+}
+
 // PreSave sets query's properties.
 // It should be called before being saved.
 func (aq *AlertQuery) PreSave() error {
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -245,3 +246,65 @@ func TestAlertQueryMarshalling(t *testing.T) {
 		}
 	}
 }
+
+func TestAlertQuery_IsHysteresisExpression(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		aq       AlertQuery
+		expected bool
+	}{
+		{
+			desc:     "hysteresis expression",
+			aq:       AlertQuery{DatasourceUID: "-100", Model: json.RawMessage(`{"type": "hysteresis", "expression": "$A"}`)},
+			expected: true,
+		},
+		{
+			desc:     "other expression",
+			aq:       AlertQuery{DatasourceUID: "-100", Model: json.RawMessage(`{"type": "threshold", "expression": "$A"}`)},
+			expected: false,
+		},
+		{
+			desc:     "query of a data source",
+			aq:       AlertQuery{DatasourceUID: "abc", Model: json.RawMessage(`{"type": "hysteresis"}`)},
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := tc.aq.IsHysteresisExpression()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAlertQuery_WithLoadedDimensions(t *testing.T) {
+	loaded := []data.Labels{{"host": "a"}}
+
+	t.Run("sets the loaded dimensions of a hysteresis expression", func(t *testing.T) {
+		aq := AlertQuery{
+			RefID:         "B",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type": "hysteresis", "expression": "$A", "loadedDimensions": [{"host": "b"}]}`),
+		}
+		res, err := aq.WithLoadedDimensions(loaded)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type": "hysteresis", "expression": "$A", "loadedDimensions": [{"host": "a"}]}`, string(res.Model))
+		require.JSONEq(t, `{"type": "hysteresis", "expression": "$A", "loadedDimensions": [{"host": "b"}]}`, string(aq.Model))
+
+		res, err = aq.WithLoadedDimensions(nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type": "hysteresis", "expression": "$A", "loadedDimensions": []}`, string(res.Model))
+	})
+
+	t.Run("does not change other queries", func(t *testing.T) {
+		for _, aq := range []AlertQuery{
+			{RefID: "A", DatasourceUID: "abc", Model: json.RawMessage(`{"type": "hysteresis"}`)},
+			{RefID: "B", DatasourceUID: "-100", Model: json.RawMessage(`{"type": "threshold", "expression": "$A"}`)},
+		} {
+			res, err := aq.WithLoadedDimensions(loaded)
+			require.NoError(t, err)
+			require.Equal(t, aq, res)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
 )
 
 var (
@@ -33,6 +35,16 @@ const (
 	OK       NoDataState = "OK"
 )
 
+// NoDataStateFromString returns the NoDataState of the string, or an error if it is not one.
+func NoDataStateFromString(state string) (NoDataState, error) {
+	switch NoDataState(state) {
+	case Alerting, NoData, OK:
+		return NoDataState(state), nil
+	default:
+		return "", fmt.Errorf("unknown NoData state option %s", state)
+	}
+}
+
 type ExecutionErrorState string
 
 func (executionErrorState ExecutionErrorState) String() string {
@@ -44,6 +56,16 @@ const (
 	ErrorErrState    ExecutionErrorState = "Error"
 )
 
+// ErrStateFromString returns the ExecutionErrorState of the string, or an error if it is not one.
+func ErrStateFromString(state string) (ExecutionErrorState, error) {
+	switch ExecutionErrorState(state) {
+	case AlertingErrState, ErrorErrState:
+		return ExecutionErrorState(state), nil
+	default:
+		return "", fmt.Errorf("unknown Error state option %s", state)
+	}
+}
+
 const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
@@ -75,6 +97,46 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// IsPaused is true when the alert rule is not evaluated. The state of its alert
+	// instances is kept until the alert rule is resumed.
+	IsPaused bool `xorm:"is_paused"`
+	// KeepFiringFor is how long the alert instances keep firing after the condition
+	// is no longer met.
+	KeepFiringFor time.Duration `xorm:"keep_firing_for"`
+	// Record is the name of the metric that a recording rule writes the series of its
+	// condition to. The rule is an alerting rule when it is empty.
+	Record string `xorm:"record"`
+	// RuleGroupIndex is the position of the alert rule in its rule group, starting at 1.
+	RuleGroupIndex int `xorm:"rule_group_idx"`
+	// Dependencies suppress the alert instances of the alert rule while the alert rules
+	// they reference are firing.
+	Dependencies []RuleDependency `xorm:"dependencies"`
+	// EvaluationTimeout is the maximum duration of an evaluation of the alert rule.
+	// The evaluation timeout of the settings applies when it is zero.
+	EvaluationTimeout time.Duration `xorm:"evaluation_timeout"`
+}
+
+// RuleDependency references an alert rule whose firing alert instances suppress the alert
+// instances of the dependent alert rule.
+type RuleDependency struct {
+	RuleUID string `json:"ruleUID"`
+	// Matchers select the alert instances of the referenced alert rule, such as
+	// severity="critical". All of them must match. Any alert instance matches when
+	// there are none.
+	Matchers []string `json:"matchers,omitempty"`
+}
+
+// LabelMatchers parses the matchers of the dependency.
+func (d RuleDependency) LabelMatchers() (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(d.Matchers))
+	for _, s := range d.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", s, err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -87,6 +149,11 @@ func (k AlertRuleKey) String() string {
 	return fmt.Sprintf("{orgID: %d, UID: %s}", k.OrgID, k.UID)
 }
 
+// IsRecording returns true if the alert rule is a recording rule.
+func (alertRule *AlertRule) IsRecording() bool {
+	return alertRule.Record != ""
+}
+
 // GetKey returns the alert definitions identifier
 func (alertRule *AlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
@@ -106,7 +173,7 @@ func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 }
 
 func (alertRule *AlertRule) ResourceType() string {
-	return "alertRule"
+	return ResourceTypeAlertRule
 }
 
 func (alertRule *AlertRule) ResourceID() string {
@@ -137,9 +204,15 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For               time.Duration
+	Annotations       map[string]string
+	Labels            map[string]string
+	IsPaused          bool             `xorm:"is_paused"`
+	KeepFiringFor     time.Duration    `xorm:"keep_firing_for"`
+	Record            string           `xorm:"record"`
+	RuleGroupIndex    int              `xorm:"rule_group_idx"`
+	Dependencies      []RuleDependency `xorm:"dependencies"`
+	EvaluationTimeout time.Duration    `xorm:"evaluation_timeout"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -164,6 +237,19 @@ type ListAlertRulesQuery struct {
 	Result []*AlertRule
 }
 
+// GetAlertRulesForSchedulingQuery is the query for retrieving the alert rules to schedule.
+type GetAlertRulesForSchedulingQuery struct {
+	ExcludeOrgs []int64
+	// UpdatedAfter is optional and allows fetching only the alert rules that were updated
+	// since. The keys of the alert rules deleted since are then returned as well.
+	UpdatedAfter time.Time
+
+	Result []*AlertRule
+	// ResultDeleted are the keys of the alert rules deleted since UpdatedAfter. They are
+	// only set when UpdatedAfter is set.
+	ResultDeleted []AlertRuleKey
+}
+
 // ListNamespaceAlertRulesQuery is the query for listing namespace alert rules
 type ListNamespaceAlertRulesQuery struct {
 	OrgID int64
@@ -211,6 +297,10 @@ type Condition struct {
 
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
+
+	// EvaluationTimeout is the maximum duration of the evaluation of the condition.
+	// The evaluation timeout of the settings applies when it is zero.
+	EvaluationTimeout time.Duration `json:"-"`
 }
 
 // IsValid checks the condition's validity.
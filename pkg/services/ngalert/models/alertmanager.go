@@ -11,6 +11,18 @@ type AlertConfiguration struct {
 	CreatedAt                 int64 `xorm:"created"`
 	Default                   bool
 	OrgID                     int64 `xorm:"org_id"`
+	// CreatedBy is the ID of the user that saved the configuration. It is 0 when Grafana saved it.
+	CreatedBy int64 `xorm:"created_by"`
+}
+
+// AlertConfigurationVersionMeta is a version of the Alertmanager configuration of an organization, without the configuration.
+type AlertConfigurationVersionMeta struct {
+	ID        int64 `xorm:"id"`
+	CreatedAt int64
+	Default   bool
+	CreatedBy int64 `xorm:"created_by"`
+	// CreatedByLogin is the login of the user that saved the configuration.
+	CreatedByLogin string `xorm:"created_by_login"`
 }
 
 // GetLatestAlertmanagerConfigurationQuery is the query to get the latest alertmanager configuration.
@@ -19,10 +31,26 @@ type GetLatestAlertmanagerConfigurationQuery struct {
 	Result *AlertConfiguration
 }
 
+// GetAlertmanagerConfigurationQuery is the query to get a version of the alertmanager configuration.
+type GetAlertmanagerConfigurationQuery struct {
+	OrgID  int64
+	ID     int64
+	Result *AlertConfiguration
+}
+
+// GetAlertmanagerConfigurationHistoryQuery is the query to get the versions of the alertmanager configuration,
+// the latest first.
+type GetAlertmanagerConfigurationHistoryQuery struct {
+	OrgID  int64
+	Limit  int
+	Result []*AlertConfigurationVersionMeta
+}
+
 // SaveAlertmanagerConfigurationCmd is the command to save an alertmanager configuration.
 type SaveAlertmanagerConfigurationCmd struct {
 	AlertmanagerConfiguration string
 	ConfigurationVersion      string
 	Default                   bool
 	OrgID                     int64
+	CreatedBy                 int64
 }
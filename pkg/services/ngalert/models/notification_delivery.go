@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationDelivery represents the delivery of a notification by an integration of a receiver,
+// including its retries.
+type NotificationDelivery struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	OrgID    int64  `xorm:"org_id"`
+	Receiver string `xorm:"receiver"`
+	// Integration is the type of the integration, such as pagerduty or slack.
+	Integration    string `xorm:"integration"`
+	IntegrationUID string `xorm:"integration_uid"`
+	// IntegrationIndex is the position of the integration in the receiver.
+	IntegrationIndex int    `xorm:"integration_idx"`
+	GroupKey         string `xorm:"group_key"`
+	// Alerts is the number of alerts in the notification.
+	Alerts int `xorm:"alerts"`
+	// Attempts is the number of times the integration tried to send the notification.
+	Attempts int `xorm:"attempts"`
+	// StatusCode is the HTTP status code of the last attempt, zero if the integration does not use HTTP
+	// or if the attempt did not get a response.
+	StatusCode int `xorm:"status_code"`
+	// Error is the error of the last attempt, empty if the notification was delivered.
+	Error    string        `xorm:"error"`
+	Duration time.Duration `xorm:"duration"`
+	SentAt   time.Time     `xorm:"sent_at"`
+}
+
+// Delivered returns true if the integration delivered the notification.
+func (d *NotificationDelivery) Delivered() bool {
+	return d.Error == ""
+}
+
+// GetNotificationDeliveriesQuery is the query for retrieving the deliveries of notifications
+// within a specific organisation, newest first.
+type GetNotificationDeliveriesQuery struct {
+	OrgID int64
+	// Receiver, Integration and IntegrationUID filter the deliveries, if they are not empty.
+	Receiver       string
+	Integration    string
+	IntegrationUID string
+	// Failed filters the deliveries that failed, if it is true.
+	Failed bool
+	From   time.Time
+	To     time.Time
+	// Limit is the maximum number of deliveries, no limit if it is zero.
+	Limit int
+
+	Result []*NotificationDelivery
+}
+
+// GetLatestNotificationDeliveriesQuery is the query for retrieving the latest delivery of each
+// integration of the receivers within a specific organisation.
+type GetLatestNotificationDeliveriesQuery struct {
+	OrgID int64
+
+	Result []*NotificationDelivery
+}
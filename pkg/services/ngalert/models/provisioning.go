@@ -14,3 +14,35 @@ type Provisionable interface {
 	ResourceID() string
 	ResourceOrgID() int64
 }
+
+// Resource types of the objects that can be provisioned.
+const (
+	ResourceTypeAlertRule          = "alertRule"
+	ResourceTypeContactPoint       = "contactPoint"
+	ResourceTypeTemplate           = "template"
+	ResourceTypeMuteTiming         = "muteTiming"
+	ResourceTypeNotificationPolicy = "notificationPolicy"
+)
+
+// NotificationPolicyResourceID is the ID of the notification policy tree, as an organization has only one.
+const NotificationPolicyResourceID = "policy"
+
+// AlertmanagerResource is an object of the Alertmanager configuration of an organization, such as a
+// contact point or a template, identified by its type and name.
+type AlertmanagerResource struct {
+	OrgID int64
+	Type  string
+	Name  string
+}
+
+func (r AlertmanagerResource) ResourceType() string {
+	return r.Type
+}
+
+func (r AlertmanagerResource) ResourceID() string {
+	return r.Name
+}
+
+func (r AlertmanagerResource) ResourceOrgID() int64 {
+	return r.OrgID
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+const (
+	// StateReasonMissingSeries is the reason of the transition of an alert instance that
+	// is no longer returned by the evaluation of its alert rule.
+	StateReasonMissingSeries = "MissingSeries"
+	// StateReasonPaused is the reason of the state of an alert instance whose alert rule is paused.
+	StateReasonPaused = "Paused"
+)
+
+// StateReasonSuppressedBy returns the reason of the state of an alert instance that is
+// suppressed because ruleUID, a dependency of its alert rule, is firing.
+func StateReasonSuppressedBy(ruleUID string) string {
+	return fmt.Sprintf("Suppressed by the alert rule %s", ruleUID)
+}
+
+// AlertStateHistoryEntry represents the transition of an alert instance from a state to another.
+type AlertStateHistoryEntry struct {
+	ID            int64             `xorm:"pk autoincr 'id'" json:"-"`
+	RuleOrgID     int64             `xorm:"rule_org_id" json:"ruleOrgId"`
+	RuleUID       string            `xorm:"rule_uid" json:"ruleUid"`
+	Labels        InstanceLabels    `json:"labels"`
+	PreviousState InstanceStateType `json:"previousState"`
+	State         InstanceStateType `xorm:"current_state" json:"state"`
+	// Reason explains the transition when it is not the result of the condition,
+	// such as the error of the evaluation or the absence of data.
+	Reason string `json:"reason,omitempty"`
+	// Values contains the RefID and value of the reduce and math expressions of the evaluation.
+	Values      map[string]*float64 `xorm:"eval_values" json:"values,omitempty"`
+	EvaluatedAt time.Time           `json:"evaluatedAt"`
+}
+
+// GetAlertStateHistoryQuery is the query for retrieving the transitions of the alert
+// instances of an organisation, newest first.
+type GetAlertStateHistoryQuery struct {
+	OrgID int64
+	// RuleUID filters the transitions of a single alert rule, if it is not empty.
+	RuleUID string
+	// RuleUIDs filters the transitions of the alert rules in the set, if it is not nil,
+	// such as the rules that a user can see.
+	RuleUIDs map[string]struct{}
+	// Matchers filter the transitions by the labels of the alert instances.
+	Matchers labels.Matchers
+	From     time.Time
+	To       time.Time
+	// Limit is the maximum number of transitions, no limit if it is zero.
+	Limit int
+
+	Result []*AlertStateHistoryEntry
+}
+
+// Matches returns true if the transition is of one of the alert rules of the query, and the
+// labels of its alert instance match the matchers of the query.
+func (q *GetAlertStateHistoryQuery) Matches(e *AlertStateHistoryEntry) bool {
+	if q.RuleUIDs != nil {
+		if _, ok := q.RuleUIDs[e.RuleUID]; !ok {
+			return false
+		}
+	}
+	return e.MatchesLabels(q.Matchers)
+}
+
+// MatchesLabels returns true if the labels of the alert instance match all the matchers.
+func (e *AlertStateHistoryEntry) MatchesLabels(matchers labels.Matchers) bool {
+	for _, m := range matchers {
+		if !m.Matches(e.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,7 +2,9 @@ package ngalert
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -13,12 +15,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -27,6 +32,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// stateHistoryCleanupInterval is how often the transitions of alert instances that are older than
+// the retention of the state history are deleted.
+const stateHistoryCleanupInterval = time.Hour
+
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService *quota.QuotaService, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert, folderService dashboards.FolderService) (*AlertNG, error) {
@@ -77,6 +86,9 @@ type AlertNG struct {
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
+
+	// Store persists the alert rules, the Alertmanager configurations and the provenance of the provisioned objects.
+	Store *store.DBstore
 }
 
 func (ng *AlertNG) init() error {
@@ -88,11 +100,14 @@ func (ng *AlertNG) init() error {
 		SQLStore:        ng.SQLStore,
 		Logger:          ng.Log,
 		FolderService:   ng.folderService,
+
+		MaxAlertmanagerConfigVersions: ng.Cfg.UnifiedAlerting.AlertmanagerConfigHistoryMaxVersions,
 	}
+	ng.Store = store
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
-	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"))
+	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, store, ng.KVStore, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"))
 	if err != nil {
 		return err
 	}
@@ -117,6 +132,25 @@ func (ng *AlertNG) init() error {
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.Cfg.UnifiedAlerting.MinInterval,
+		SequentialRuleGroups:    ng.Cfg.UnifiedAlerting.SequentialRuleGroupEvaluation,
+
+		MaxConcurrentEvaluations:       ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluations,
+		MaxConcurrentEvaluationsPerOrg: ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluationsPerOrg,
+	}
+	if ng.Cfg.UnifiedAlerting.HAEvaluationSharding && len(ng.Cfg.UnifiedAlerting.HAPeers) > 0 {
+		schedCfg.ClusterMembership = ng.MultiOrgAlertmanager
+	}
+	if cfg := ng.Cfg.UnifiedAlerting.RecordingRules; cfg.Enabled {
+		u, err := url.Parse(cfg.RemoteWriteURL)
+		if err != nil {
+			return fmt.Errorf("failed to parse the URL of the recording rules remote write endpoint: %w", err)
+		}
+		schedCfg.RecordingWriter = writer.NewRemoteWriter(writer.RemoteWriteConfig{
+			URL:               u,
+			BasicAuthUser:     cfg.RemoteWriteBasicAuthUsername,
+			BasicAuthPassword: cfg.RemoteWriteBasicAuthPassword,
+			Timeout:           cfg.Timeout,
+		}, ng.Log.New("writer", "remote_write"))
 	}
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
@@ -124,7 +158,11 @@ func (ng *AlertNG) init() error {
 		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
 		appUrl = nil
 	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.SQLStore)
+	stateHistory, err := configureHistorian(ng.Cfg.UnifiedAlerting.StateHistory, store, ng.Log)
+	if err != nil {
+		return err
+	}
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.SQLStore, stateHistory)
 	scheduler := schedule.NewScheduler(schedCfg, ng.ExpressionService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
@@ -143,14 +181,41 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		AlertingStore:        store,
 		AdminConfigStore:     store,
+		ProvisioningStore:    store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		StateHistory:         stateHistory,
+		DeliveryStore:        store,
+		Backtesting:          backtesting.NewEngine(ng.Cfg, ng.DataSourceCache, ng.SecretsService, ng.ExpressionService, appUrl),
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
 	return nil
 }
 
+// configureHistorian returns the backend that records the transitions of alert instances,
+// or nil if the state history is disabled.
+func configureHistorian(cfg setting.UnifiedAlertingStateHistorySettings, db store.StateHistoryStore, logger log.Logger) (store.StateHistoryStore, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	switch cfg.Backend {
+	case setting.StateHistoryBackendLoki:
+		u, err := url.Parse(cfg.LokiRemoteURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the URL of the state history Loki backend: %w", err)
+		}
+		return historian.NewLokiBackend(historian.LokiConfig{
+			URL:               u,
+			TenantID:          cfg.LokiTenantID,
+			BasicAuthUser:     cfg.LokiBasicAuthUsername,
+			BasicAuthPassword: cfg.LokiBasicAuthPassword,
+		}, logger.New("historian", "loki")), nil
+	default:
+		return db, nil
+	}
+}
+
 // Run starts the scheduler and Alertmanager.
 func (ng *AlertNG) Run(ctx context.Context) error {
 	ng.Log.Debug("ngalert starting")
@@ -166,9 +231,35 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
+	if cfg := ng.Cfg.UnifiedAlerting.StateHistory; cfg.Enabled && cfg.Backend == setting.StateHistoryBackendSQL {
+		children.Go(func() error {
+			ng.deleteOldStateHistory(subCtx, cfg.Retention)
+			return nil
+		})
+	}
 	return children.Wait()
 }
 
+// deleteOldStateHistory periodically deletes the transitions of alert instances that are older
+// than the retention, until the context is done.
+func (ng *AlertNG) deleteOldStateHistory(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(stateHistoryCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := ng.Store.DeleteAlertStateHistory(ctx, time.Now().Add(-retention))
+			if err != nil {
+				ng.Log.Error("failed to delete old alert state history", "err", err)
+				continue
+			}
+			ng.Log.Debug("deleted old alert state history", "count", deleted)
+		}
+	}
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -124,10 +124,13 @@ type Alertmanager struct {
 	orgID           int64
 
 	decryptFn channels.GetDecryptedValueFn
+
+	// deliveryStore saves the deliveries of notifications, if it is not nil.
+	deliveryStore store.NotificationDeliveryStore
 }
 
-func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store store.AlertingStore, kvStore kvstore.KVStore,
-	peer ClusterPeer, decryptFn channels.GetDecryptedValueFn, ns notifications.Service, m *metrics.Alertmanager) (*Alertmanager, error) {
+func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store store.AlertingStore, deliveryStore store.NotificationDeliveryStore,
+	kvStore kvstore.KVStore, peer ClusterPeer, decryptFn channels.GetDecryptedValueFn, ns notifications.Service, m *metrics.Alertmanager) (*Alertmanager, error) {
 	am := &Alertmanager{
 		Settings:            cfg,
 		stopc:               make(chan struct{}),
@@ -136,6 +139,7 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store s
 		stageMetrics:        notify.NewMetrics(m.Registerer),
 		dispatcherMetrics:   dispatch.NewDispatcherMetrics(false, m.Registerer),
 		Store:               store,
+		deliveryStore:       deliveryStore,
 		peer:                peer,
 		peerTimeout:         cfg.UnifiedAlerting.HAPeerTimeout,
 		Metrics:             m,
@@ -231,8 +235,9 @@ func (am *Alertmanager) StopAndWait() {
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration the database and applies the configuration to the Alertmanager.
-// It rollbacks the save if we fail to apply the configuration.
-func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
+// It rollbacks the save if we fail to apply the configuration. createdBy is the ID of the user that resets the configuration,
+// or 0 if Grafana does.
+func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context, createdBy int64) error {
 	am.reloadConfigMtx.Lock()
 	defer am.reloadConfigMtx.Unlock()
 
@@ -241,6 +246,7 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 		Default:                   true,
 		ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
 		OrgID:                     am.orgID,
+		CreatedBy:                 createdBy,
 	}
 
 	cfg, err := Load([]byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
@@ -262,8 +268,9 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 }
 
 // SaveAndApplyConfig saves the configuration the database and applies the configuration to the Alertmanager.
-// It rollbacks the save if we fail to apply the configuration.
-func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig) error {
+// It rollbacks the save if we fail to apply the configuration. createdBy is the ID of the user that changes the
+// configuration, or 0 if Grafana does.
+func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, createdBy int64) error {
 	rawConfig, err := json.Marshal(&cfg)
 	if err != nil {
 		return fmt.Errorf("failed to serialize to the Alertmanager configuration: %w", err)
@@ -276,6 +283,7 @@ func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.P
 		AlertmanagerConfiguration: string(rawConfig),
 		ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
 		OrgID:                     am.orgID,
+		CreatedBy:                 createdBy,
 	}
 
 	err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
@@ -308,21 +316,29 @@ func (am *Alertmanager) ApplyConfig(dbCfg *ngmodels.AlertConfiguration) error {
 	return nil
 }
 
+// getTemplate returns the templates of the configuration.
 func (am *Alertmanager) getTemplate() (*template.Template, error) {
 	am.reloadConfigMtx.RLock()
 	defer am.reloadConfigMtx.RUnlock()
+	return am.configTemplate()
+}
+
+// configTemplate returns the templates of the configuration, followed by the extra template files.
+// It must be called with reloadConfigMtx held.
+func (am *Alertmanager) configTemplate(extraPaths ...string) (*template.Template, error) {
 	if !am.ready() {
 		return nil, errors.New("alertmanager is not initialized")
 	}
-	paths := make([]string, 0, len(am.config.TemplateFiles))
+	paths := make([]string, 0, len(am.config.TemplateFiles)+len(extraPaths))
 	for name := range am.config.TemplateFiles {
 		paths = append(paths, filepath.Join(am.WorkingDirPath(), name))
 	}
+	paths = append(paths, extraPaths...)
 	return am.templateFromPaths(paths...)
 }
 
 func (am *Alertmanager) templateFromPaths(paths ...string) (*template.Template, error) {
-	tmpl, err := template.FromGlobs(paths...)
+	tmpl, err := channels.FromGlobs(paths...)
 	if err != nil {
 		return nil, err
 	}
@@ -464,6 +480,9 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
+		if am.deliveryStore != nil {
+			n = &deliveryNotifier{NotificationChannel: n, uid: r.UID}
+		}
 		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
 	}
 	return integrations, nil
@@ -512,6 +531,8 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		n, err = channels.NewVictoropsNotifier(cfg, am.NotificationService, tmpl)
 	case "teams":
 		n, err = channels.NewTeamsNotifier(cfg, am.NotificationService, tmpl)
+	case "teams-workflows":
+		n, err = channels.NewTeamsWorkflowsNotifier(cfg, am.NotificationService, tmpl)
 	case "dingding":
 		n, err = channels.NewDingDingNotifier(cfg, am.NotificationService, tmpl)
 	case "kafka":
@@ -534,6 +555,12 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		n, err = channels.NewOpsgenieNotifier(cfg, am.NotificationService, tmpl, am.decryptFn)
 	case "prometheus-alertmanager":
 		n, err = channels.NewAlertmanagerNotifier(cfg, tmpl, am.decryptFn)
+	case "webex":
+		n, err = channels.NewWebexNotifier(cfg, am.NotificationService, tmpl, am.decryptFn)
+	case "mattermost":
+		n, err = channels.NewMattermostNotifier(cfg, am.NotificationService, tmpl)
+	case "sns":
+		n, err = channels.NewSNSNotifier(cfg, tmpl, am.decryptFn, am.Settings)
 	default:
 		return nil, InvalidReceiverError{
 			Receiver: r,
@@ -701,7 +728,18 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
 		s = append(s, notify.NewDedupStage(&integrations[i], notificationLog, recv))
-		s = append(s, notify.NewRetryStage(integrations[i], name, am.stageMetrics))
+		var retry notify.Stage = notify.NewRetryStage(integrations[i], name, am.stageMetrics)
+		if am.deliveryStore != nil {
+			retry = &deliveryStage{
+				next:        retry,
+				store:       am.deliveryStore,
+				orgID:       am.orgID,
+				receiver:    name,
+				integration: integrations[i],
+				logger:      am.logger,
+			}
+		}
+		s = append(s, retry)
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
 
 		fs = append(fs, s)
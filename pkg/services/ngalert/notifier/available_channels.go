@@ -556,6 +556,36 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "teams-workflows",
+			Name:        "Microsoft Teams Workflows",
+			Description: "Sends notifications as adaptive cards to Microsoft Teams using a Workflows webhook",
+			Heading:     "Teams Workflows settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Teams Workflows webhook url",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Title",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Templated title of the card",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "teams.default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
 		{
 			Type:        "telegram",
 			Name:        "Telegram",
@@ -859,5 +889,206 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "webex",
+			Name:        "Cisco Webex Teams",
+			Description: "Sends notifications to a Cisco Webex Teams room",
+			Heading:     "Webex settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Bot Token",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Access token of the Webex bot that posts the messages.",
+					Placeholder:  "Webex bot access token",
+					PropertyName: "bot_token",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Room ID",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "ID of the room to post the messages to. The bot must be a member of the room.",
+					PropertyName: "room_id",
+					Required:     true,
+				},
+				{
+					Label:        "API URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  channels.WebexAPIURL,
+					PropertyName: "api_url",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Description:  "Templated Markdown message",
+					Placeholder:  `{{ template "webex.default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "mattermost",
+			Name:        "Mattermost",
+			Description: "Sends notifications to Mattermost using an incoming webhook",
+			Heading:     "Mattermost settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Webhook URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Mattermost incoming webhook url",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Channel",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Override the channel of the webhook, such as town-square or @username.",
+					PropertyName: "channel",
+				},
+				{
+					Label:        "Username",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Override the username of the webhook.",
+					PropertyName: "username",
+				},
+				{
+					Label:        "Icon URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Override the profile picture of the webhook.",
+					PropertyName: "icon_url",
+				},
+				{
+					Label:        "Title",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Templated title of the message",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Text Body",
+					Element:      alerting.ElementTypeTextArea,
+					Description:  "Body of the message",
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "text",
+				},
+			},
+		},
+		{
+			Type:        "sns",
+			Name:        "AWS SNS",
+			Description: "Publishes notifications to an AWS SNS topic",
+			Heading:     "AWS SNS settings",
+			Info:        "The requests are signed with AWS Signature Version 4. The credentials must allow sns:Publish on the topic.",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Topic ARN",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "arn:aws:sns:us-east-1:123456789012:alerts",
+					PropertyName: "topic_arn",
+					Required:     true,
+				},
+				{
+					Label:        "Region",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Region of the topic. Defaults to the region of the topic ARN.",
+					PropertyName: "region",
+				},
+				{
+					Label:        "Authentication Provider",
+					Element:      alerting.ElementTypeSelect,
+					Description:  "How to get the AWS credentials to sign the requests with. Must be one of the allowed_auth_providers of the AWS settings of the server.",
+					PropertyName: "auth_provider",
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: "default",
+							Label: "AWS SDK Default",
+						},
+						{
+							Value: "keys",
+							Label: "Access & secret key",
+						},
+						{
+							Value: "credentials",
+							Label: "Credentials file",
+						},
+						{
+							Value: "ec2_iam_role",
+							Label: "EC2 IAM role",
+						},
+					},
+				},
+				{
+					Label:        "Credentials Profile Name",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "default",
+					PropertyName: "profile",
+					ShowWhen: alerting.ShowWhen{
+						Field: "auth_provider",
+						Is:    "credentials",
+					},
+				},
+				{
+					Label:        "Access Key ID",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "access_key",
+					Secure:       true,
+					ShowWhen: alerting.ShowWhen{
+						Field: "auth_provider",
+						Is:    "keys",
+					},
+				},
+				{
+					Label:        "Secret Access Key",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "secret_key",
+					Secure:       true,
+					ShowWhen: alerting.ShowWhen{
+						Field: "auth_provider",
+						Is:    "keys",
+					},
+				},
+				{
+					Label:        "Assume Role ARN",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Optional ARN of a role to assume. Requires assume_role_enabled in the AWS settings of the server.",
+					PropertyName: "assume_role_arn",
+				},
+				{
+					Label:        "External ID",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Optional external ID of the role to assume",
+					PropertyName: "external_id",
+				},
+				{
+					Label:        "Subject",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Templated subject of the message, used by email subscriptions. At most 100 characters on a single line.",
+					Placeholder:  `{{ template "sns.default.subject" . }}`,
+					PropertyName: "subject",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "sns.default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
 	}
 }
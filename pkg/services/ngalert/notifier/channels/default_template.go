@@ -11,6 +11,42 @@ import (
 
 const DefaultMessageTitleEmbed = `{{ template "default.title" . }}`
 
+const DefaultMessageEmbed = `{{ template "default.message" . }}`
+
+// IntegrationTemplates are the templates of the title and the body of the notifications of an integration.
+// They are empty if the notifications of the integration do not have a title or a body.
+type IntegrationTemplates struct {
+	Title string
+	Body  string
+}
+
+// DefaultIntegrationTemplates are the templates that the integrations use when their settings do not
+// replace them, by type of integration. The email integration renders the alerts with its own HTML
+// template, so only its optional message is templated.
+var DefaultIntegrationTemplates = map[string]IntegrationTemplates{
+	"dingding":        {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"discord":         {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"email":           {Title: DefaultMessageTitleEmbed},
+	"googlechat":      {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"kafka":           {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"LINE":            {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"mattermost":      {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"opsgenie":        {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"pagerduty":       {Title: DefaultMessageTitleEmbed},
+	"pushover":        {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"sensugo":         {Body: DefaultMessageEmbed},
+	"slack":           {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"sns":             {Title: `{{ template "sns.default.subject" . }}`, Body: `{{ template "sns.default.message" . }}`},
+	"teams":           {Title: DefaultMessageTitleEmbed, Body: `{{ template "teams.default.message" . }}`},
+	"teams-workflows": {Title: DefaultMessageTitleEmbed, Body: `{{ template "teams.default.message" . }}`},
+	"telegram":        {Body: DefaultMessageEmbed},
+	"threema":         {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"victorops":       {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"webex":           {Body: `{{ template "webex.default.message" . }}`},
+	"webhook":         {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+	"wecom":           {Title: DefaultMessageTitleEmbed, Body: DefaultMessageEmbed},
+}
+
 var DefaultTemplateString = `
 {{ define "__subject" }}[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .GroupLabels.SortedPairs.Values | join " " }} {{ if gt (len .CommonLabels) (len .GroupLabels) }}({{ with .CommonLabels.Remove .GroupLabels.Names }}{{ .Values | join " " }}{{ end }}){{ end }}{{ end }}
 
@@ -60,6 +96,14 @@ Annotations:
 
 {{ end }}{{ end }}{{ if gt (len .Alerts.Resolved) 0 }}**Resolved**
 {{ template "__teams_text_alert_list" .Alerts.Resolved }}{{ end }}{{ end }}
+
+{{ define "webex.default.message" }}**{{ template "default.title" . }}**
+
+{{ template "default.message" . }}{{ end }}
+
+{{ define "sns.default.subject" }}{{ template "default.title" . }}{{ end }}
+
+{{ define "sns.default.message" }}{{ template "default.message" . }}{{ end }}
 `
 
 // TemplateForTestsString is the template used for unit tests and integration tests.
@@ -89,6 +133,14 @@ Labels:
 {{ template "__text_alert_list" .Alerts.Resolved }}{{ end }}{{ end }}
 
 {{ define "teams.default.message" }}{{ template "default.message" . }}{{ end }}
+
+{{ define "webex.default.message" }}**{{ template "default.title" . }}**
+
+{{ template "default.message" . }}{{ end }}
+
+{{ define "sns.default.subject" }}{{ template "default.title" . }}{{ end }}
+
+{{ define "sns.default.message" }}{{ template "default.message" . }}{{ end }}
 `
 
 func templateForTests(t *testing.T) *template.Template {
@@ -105,7 +157,7 @@ func templateForTests(t *testing.T) *template.Template {
 	_, err = f.WriteString(TemplateForTestsString)
 	require.NoError(t, err)
 
-	tmpl, err := template.FromGlobs(f.Name())
+	tmpl, err := FromGlobs(f.Name())
 	require.NoError(t, err)
 
 	return tmpl
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
@@ -69,7 +68,7 @@ func TestDefaultTemplateString(t *testing.T) {
 	_, err = f.WriteString(DefaultTemplateString)
 	require.NoError(t, err)
 
-	tmpl, err := template.FromGlobs(f.Name())
+	tmpl, err := FromGlobs(f.Name())
 	require.NoError(t, err)
 
 	externalURL, err := url.Parse("http://localhost/grafana")
@@ -0,0 +1,124 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// MattermostNotifier is responsible for sending
+// alert notifications to Mattermost incoming webhooks.
+type MattermostNotifier struct {
+	*Base
+	URL      string
+	Channel  string
+	Username string
+	IconURL  string
+	Title    string
+	Text     string
+	log      log.Logger
+	ns       notifications.WebhookSender
+	tmpl     *template.Template
+}
+
+// NewMattermostNotifier is the constructor for the Mattermost notifier.
+func NewMattermostNotifier(model *NotificationChannelConfig, ns notifications.WebhookSender, t *template.Template) (*MattermostNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	url := model.Settings.Get("url").MustString()
+	if url == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
+	}
+
+	return &MattermostNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:      url,
+		Channel:  model.Settings.Get("channel").MustString(),
+		Username: model.Settings.Get("username").MustString(),
+		IconURL:  model.Settings.Get("icon_url").MustString(),
+		Title:    model.Settings.Get("title").MustString(DefaultMessageTitleEmbed),
+		Text:     model.Settings.Get("text").MustString(`{{ template "default.message" . }}`),
+		log:      log.New("alerting.notifier.mattermost"),
+		ns:       ns,
+		tmpl:     t,
+	}, nil
+}
+
+// mattermostMessage is the body of a request to a Mattermost incoming webhook.
+// Mattermost renders the attachments the same way as Slack does.
+// See: https://developers.mattermost.com/integrate/webhooks/incoming/
+type mattermostMessage struct {
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconURL     string       `json:"icon_url,omitempty"`
+	Attachments []attachment `json:"attachments"`
+}
+
+// Notify sends an alert notification to Mattermost.
+func (mn *MattermostNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+
+	ruleURL := joinUrlPath(mn.tmpl.ExternalURL.String(), "/alerting/list", mn.log)
+
+	msg := mattermostMessage{
+		Channel:  tmpl(mn.Channel),
+		Username: tmpl(mn.Username),
+		IconURL:  tmpl(mn.IconURL),
+		Attachments: []attachment{
+			{
+				Color:      getAlertStatusColor(types.Alerts(as...).Status()),
+				Title:      tmpl(mn.Title),
+				TitleLink:  ruleURL,
+				Fallback:   tmpl(mn.Title),
+				Text:       tmpl(mn.Text),
+				Footer:     "Grafana v" + setting.BuildVersion,
+				FooterIcon: FooterIconURL,
+				Ts:         timeNow().Unix(),
+			},
+		},
+	}
+
+	u := tmpl(mn.URL)
+	if tmplErr != nil {
+		mn.log.Warn("failed to template Mattermost message", "err", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        u,
+		Body:       string(body),
+		HttpMethod: "POST",
+	}
+
+	if err := mn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		mn.log.Error("Failed to send Mattermost message", "error", err, "webhook", mn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (mn *MattermostNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,159 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMattermostNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	defer mockTimeNow(time.Unix(1629984000, 0))()
+
+	cases := []struct {
+		name         string
+		settings     string
+		statusCode   int
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError string
+		expMsgError  string
+	}{
+		{
+			name:       "Default config with one alert",
+			settings:   `{}`,
+			statusCode: http.StatusOK,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expMsg: `{
+				"attachments": [{
+					"title": "[FIRING:1]  (val1)",
+					"title_link": "http://localhost/alerting/list",
+					"text": "**Firing**\n\nValue: [no value]\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n",
+					"fallback": "[FIRING:1]  (val1)",
+					"footer": "Grafana v",
+					"footer_icon": "https://grafana.com/assets/img/fav32.png",
+					"color": "#D63232",
+					"ts": 1629984000
+				}]
+			}`,
+		}, {
+			name: "Custom config with multiple alerts",
+			settings: `{
+				"channel": "town-square",
+				"username": "grafana",
+				"icon_url": "https://grafana.com/assets/img/fav32.png",
+				"title": "{{ len .Alerts }} alerts",
+				"text": "{{ len .Alerts.Firing }} alerts are firing, {{ len .Alerts.Resolved }} are resolved"
+			}`,
+			statusCode: http.StatusOK,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expMsg: `{
+				"channel": "town-square",
+				"username": "grafana",
+				"icon_url": "https://grafana.com/assets/img/fav32.png",
+				"attachments": [{
+					"title": "2 alerts",
+					"title_link": "http://localhost/alerting/list",
+					"text": "2 alerts are firing, 0 are resolved",
+					"fallback": "2 alerts",
+					"footer": "Grafana v",
+					"footer_icon": "https://grafana.com/assets/img/fav32.png",
+					"color": "#D63232",
+					"ts": 1629984000
+				}]
+			}`,
+		}, {
+			name:       "Error response from the webhook",
+			settings:   `{}`,
+			statusCode: http.StatusBadRequest,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsgError: "Webhook response status 400 Bad Request",
+		}, {
+			name:         "Error in initing",
+			settings:     `{"url": ""}`,
+			expInitError: `failed to validate receiver "mattermost_testing" of type "mattermost": could not find url property in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newStandInServer(t, c.statusCode)
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			if _, ok := settingsJSON.CheckGet("url"); !ok {
+				settingsJSON.Set("url", server.URL)
+			}
+
+			m := &NotificationChannelConfig{
+				Name:     "mattermost_testing",
+				Type:     "mattermost",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewMattermostNotifier(m, httpWebhookSender{}, tmpl)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != "" {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError, err.Error())
+				return
+			}
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			require.Equal(t, http.MethodPost, requests[0].Method)
+			require.JSONEq(t, c.expMsg, requests[0].Body)
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/template"
@@ -203,6 +204,7 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 	if err != nil {
 		return err
 	}
+	notifications.RecordResponseStatus(request.Context(), resp.StatusCode)
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			logger.Warn("Failed to close response body", "err", err)
@@ -0,0 +1,198 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// snsMaxSubjectLen is the maximum number of characters of the subject of a message.
+	snsMaxSubjectLen = 100
+	// snsMaxMessageLen is the maximum size in bytes of a message.
+	snsMaxMessageLen = 256 * 1024
+)
+
+var (
+	SNSAPIURL = "https://sns.%s.amazonaws.com/"
+
+	// snsAuthProviders are the supported ways to get the AWS credentials to sign the requests with.
+	snsAuthProviders = map[string]bool{"default": true, "keys": true, "credentials": true, "ec2_iam_role": true}
+)
+
+// SNSNotifier is responsible for publishing
+// alert notifications to AWS SNS topics.
+type SNSNotifier struct {
+	*Base
+	APIURL   string
+	TopicARN string
+	Subject  string
+	Message  string
+	client   *http.Client
+	log      log.Logger
+	tmpl     *template.Template
+}
+
+// NewSNSNotifier is the constructor for the AWS SNS notifier. The requests are signed with
+// AWS Signature Version 4 by the SigV4 middleware of the HTTP client, so the notifier is subject
+// to the SigV4 and AWS settings of the server.
+func NewSNSNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn, cfg *setting.Cfg) (*SNSNotifier, error) {
+	if !cfg.SigV4AuthEnabled {
+		return nil, receiverInitError{Cfg: *model, Reason: "SigV4 authentication is not enabled"}
+	}
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+	if model.SecureSettings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no secure settings supplied"}
+	}
+
+	topicARN := model.Settings.Get("topic_arn").MustString()
+	if topicARN == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find topic_arn property in settings"}
+	}
+
+	// The region is part of the ARN of the topic, such as arn:aws:sns:us-east-1:123456789012:my-topic.
+	region := model.Settings.Get("region").MustString()
+	if region == "" {
+		if parts := strings.Split(topicARN, ":"); len(parts) == 6 {
+			region = parts[3]
+		}
+	}
+	if region == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find region in settings or in the topic ARN"}
+	}
+
+	authProvider := model.Settings.Get("auth_provider").MustString("default")
+	if !snsAuthProviders[authProvider] {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid auth_provider %q", authProvider)}
+	}
+	if !snsAuthProviderAllowed(cfg, authProvider) {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("auth_provider %q is not allowed", authProvider)}
+	}
+	assumeRoleARN := model.Settings.Get("assume_role_arn").MustString()
+	if assumeRoleARN != "" && !cfg.AWSAssumeRoleEnabled {
+		return nil, receiverInitError{Cfg: *model, Reason: "assuming a role is not enabled"}
+	}
+	accessKey := fn(context.Background(), model.SecureSettings, "access_key", model.Settings.Get("access_key").MustString())
+	secretKey := fn(context.Background(), model.SecureSettings, "secret_key", model.Settings.Get("secret_key").MustString())
+	if authProvider == "keys" && (accessKey == "" || secretKey == "") {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find access_key and secret_key in settings"}
+	}
+
+	client, err := sdkhttpclient.New(sdkhttpclient.Options{
+		SigV4: &sdkhttpclient.SigV4Config{
+			AuthType:      authProvider,
+			Profile:       model.Settings.Get("profile").MustString(),
+			Service:       "sns",
+			AccessKey:     accessKey,
+			SecretKey:     secretKey,
+			AssumeRoleARN: assumeRoleARN,
+			ExternalID:    model.Settings.Get("external_id").MustString(),
+			Region:        region,
+		},
+		Middlewares: []sdkhttpclient.Middleware{httpclientprovider.SigV4Middleware(cfg.SigV4VerboseLogging)},
+	})
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "failed to create HTTP client", Err: err}
+	}
+
+	return &SNSNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		APIURL:   fmt.Sprintf(SNSAPIURL, region),
+		TopicARN: topicARN,
+		Subject:  model.Settings.Get("subject").MustString(`{{ template "sns.default.subject" . }}`),
+		Message:  model.Settings.Get("message").MustString(`{{ template "sns.default.message" . }}`),
+		client:   client,
+		log:      log.New("alerting.notifier.sns"),
+		tmpl:     t,
+	}, nil
+}
+
+// Notify publishes an alert notification to the AWS SNS topic.
+// See: https://docs.aws.amazon.com/sns/latest/api/API_Publish.html
+func (sn *SNSNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+
+	// The subject must be a single line of at most 100 characters.
+	subject, _ := notify.Truncate(strings.Join(strings.Fields(tmpl(sn.Subject)), " "), snsMaxSubjectLen)
+	message, truncated := notify.Truncate(tmpl(sn.Message), snsMaxMessageLen)
+	if truncated {
+		sn.log.Warn("Truncated message", "topic", sn.TopicARN, "maxLength", snsMaxMessageLen)
+	}
+	if tmplErr != nil {
+		sn.log.Warn("failed to template AWS SNS message", "err", tmplErr.Error())
+	}
+
+	form := url.Values{}
+	form.Set("Action", "Publish")
+	form.Set("Version", "2010-03-31")
+	form.Set("TopicArn", sn.TopicARN)
+	form.Set("Message", message)
+	if subject != "" {
+		form.Set("Subject", subject)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sn.APIURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	request.Header.Set("User-Agent", "Grafana")
+
+	resp, err := sn.client.Do(request)
+	if err != nil {
+		return false, fmt.Errorf("failed to publish to AWS SNS: %w", err)
+	}
+	notifications.RecordResponseStatus(ctx, resp.StatusCode)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			sn.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := io.ReadAll(resp.Body)
+		sn.log.Warn("AWS SNS request failed", "topic", sn.TopicARN, "statusCode", resp.Status, "body", string(respBody))
+		return false, fmt.Errorf("failed to publish to AWS SNS - status code %d", resp.StatusCode)
+	}
+
+	sn.log.Debug("Publishing to AWS SNS succeeded", "topic", sn.TopicARN, "statusCode", resp.Status)
+	return true, nil
+}
+
+// snsAuthProviderAllowed returns whether the auth provider is one of the allowed_auth_providers
+// of the AWS settings of the server.
+func snsAuthProviderAllowed(cfg *setting.Cfg, authProvider string) bool {
+	for _, allowed := range cfg.AWSAllowedAuthProviders {
+		if allowed == authProvider {
+			return true
+		}
+	}
+	return false
+}
+
+func (sn *SNSNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
@@ -0,0 +1,219 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSNSNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	defaultCfg := func() *setting.Cfg {
+		return &setting.Cfg{
+			SigV4AuthEnabled:        true,
+			AWSAllowedAuthProviders: []string{"default", "keys", "credentials"},
+			AWSAssumeRoleEnabled:    true,
+		}
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		cfg          func(cfg *setting.Cfg)
+		statusCode   int
+		alerts       []*types.Alert
+		expForm      url.Values
+		expInitError string
+		expMsgError  string
+	}{
+		{
+			name: "Default config with one alert",
+			settings: `{
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"auth_provider": "keys",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret"
+			}`,
+			statusCode: http.StatusOK,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"Action":   {"Publish"},
+				"Version":  {"2010-03-31"},
+				"TopicArn": {"arn:aws:sns:us-east-1:123456789012:alerts"},
+				"Subject":  {"[FIRING:1] (val1)"},
+				"Message":  {"**Firing**\n\nValue: [no value]\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n"},
+			},
+		}, {
+			name: "Custom config with a long subject on multiple lines",
+			settings: `{
+				"topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts",
+				"auth_provider": "keys",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"subject": "{{ len .Alerts }} alerts\n{{ range .Alerts }}{{ .Labels.lbl1 }} {{ end }}",
+				"message": "{{ len .Alerts.Firing }} alerts are firing, {{ len .Alerts.Resolved }} are resolved"
+			}`,
+			statusCode: http.StatusOK,
+			alerts: func() []*types.Alert {
+				var alerts []*types.Alert
+				for i := 0; i < 20; i++ {
+					alerts = append(alerts, &types.Alert{Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": model.LabelValue(strings.Repeat("v", 10) + string(rune('a'+i)))},
+					}})
+				}
+				return alerts
+			}(),
+			expForm: url.Values{
+				"Action":   {"Publish"},
+				"Version":  {"2010-03-31"},
+				"TopicArn": {"arn:aws:sns:eu-west-1:123456789012:alerts"},
+				"Subject":  {"20 alerts vvvvvvvvvva vvvvvvvvvvb vvvvvvvvvvc vvvvvvvvvvd vvvvvvvvvve vvvvvvvvvvf vvvvvvvvvvg vvv..."},
+				"Message":  {"20 alerts are firing, 0 are resolved"},
+			},
+		}, {
+			name: "Error response from AWS SNS",
+			settings: `{
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"auth_provider": "keys",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret"
+			}`,
+			statusCode: http.StatusForbidden,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsgError: "failed to publish to AWS SNS - status code 403",
+		}, {
+			name:         "Error in initing, missing topic ARN",
+			settings:     `{}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": could not find topic_arn property in settings`,
+		}, {
+			name:         "Error in initing, missing region",
+			settings:     `{"topic_arn": "alerts"}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": could not find region in settings or in the topic ARN`,
+		}, {
+			name:         "Error in initing, invalid auth provider",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts", "auth_provider": "password"}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": invalid auth_provider "password"`,
+		}, {
+			name:         "Error in initing, missing keys",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts", "auth_provider": "keys"}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": could not find access_key and secret_key in settings`,
+		}, {
+			name:         "Error in initing, SigV4 authentication is not enabled",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts"}`,
+			cfg:          func(cfg *setting.Cfg) { cfg.SigV4AuthEnabled = false },
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": SigV4 authentication is not enabled`,
+		}, {
+			name:         "Error in initing, auth provider is not allowed",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts", "auth_provider": "ec2_iam_role"}`,
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": auth_provider "ec2_iam_role" is not allowed`,
+		}, {
+			name:         "Error in initing, default auth provider is not allowed",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts"}`,
+			cfg:          func(cfg *setting.Cfg) { cfg.AWSAllowedAuthProviders = []string{"keys"} },
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": auth_provider "default" is not allowed`,
+		}, {
+			name: "Error in initing, assume role is not enabled",
+			settings: `{
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"auth_provider": "keys",
+				"access_key": "AKIDEXAMPLE",
+				"secret_key": "secret",
+				"assume_role_arn": "arn:aws:iam::123456789012:role/admin"
+			}`,
+			cfg:          func(cfg *setting.Cfg) { cfg.AWSAssumeRoleEnabled = false },
+			expInitError: `failed to validate receiver "sns_testing" of type "sns": assuming a role is not enabled`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newStandInServer(t, c.statusCode)
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			secureSettings := make(map[string][]byte)
+
+			m := &NotificationChannelConfig{
+				Name:           "sns_testing",
+				Type:           "sns",
+				Settings:       settingsJSON,
+				SecureSettings: secureSettings,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			cfg := defaultCfg()
+			if c.cfg != nil {
+				c.cfg(cfg)
+			}
+			origSNSAPIURL := SNSAPIURL
+			t.Cleanup(func() { SNSAPIURL = origSNSAPIURL })
+			SNSAPIURL = server.URL + "/%s"
+
+			pn, err := NewSNSNotifier(m, tmpl, decryptFn, cfg)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != "" {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError, err.Error())
+				return
+			}
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			require.Equal(t, http.MethodPost, requests[0].Method)
+
+			// The request is signed for the region of the topic.
+			region := strings.Split(c.expForm.Get("TopicArn"), ":")[3]
+			auth := requests[0].Header.Get("Authorization")
+			require.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), auth)
+			require.Contains(t, auth, "/"+region+"/sns/aws4_request")
+			require.NotEmpty(t, requests[0].Header.Get("X-Amz-Date"))
+
+			form, err := url.ParseQuery(requests[0].Body)
+			require.NoError(t, err)
+			require.Equal(t, c.expForm, form)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+// TeamsWorkflowsNotifier is responsible for sending
+// alert notifications as adaptive cards to Microsoft Teams Workflows webhooks.
+type TeamsWorkflowsNotifier struct {
+	*Base
+	URL     string
+	Title   string
+	Message string
+	tmpl    *template.Template
+	log     log.Logger
+	ns      notifications.WebhookSender
+}
+
+// NewTeamsWorkflowsNotifier is the constructor for the Teams Workflows notifier.
+func NewTeamsWorkflowsNotifier(model *NotificationChannelConfig, ns notifications.WebhookSender, t *template.Template) (*TeamsWorkflowsNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+
+	u := model.Settings.Get("url").MustString()
+	if u == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
+	}
+
+	return &TeamsWorkflowsNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:     u,
+		Title:   model.Settings.Get("title").MustString(DefaultMessageTitleEmbed),
+		Message: model.Settings.Get("message").MustString(`{{ template "teams.default.message" .}}`),
+		log:     log.New("alerting.notifier.teams-workflows"),
+		ns:      ns,
+		tmpl:    t,
+	}, nil
+}
+
+// Notify sends an alert notification to a Microsoft Teams Workflows webhook.
+func (tn *TeamsWorkflowsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, tn.tmpl, as, tn.log, &tmplErr)
+
+	ruleURL := joinUrlPath(tn.tmpl.ExternalURL.String(), "/alerting/list", tn.log)
+
+	titleColor := "Good"
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		titleColor = "Attention"
+	}
+
+	msg := adaptiveCardMessage{
+		Type: "message",
+		Attachments: []adaptiveCardAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: adaptiveCard{
+					Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+					Type:    "AdaptiveCard",
+					Version: "1.4",
+					MSTeams: map[string]string{"width": "Full"},
+					Body: []adaptiveCardTextBlock{
+						{
+							Type:   "TextBlock",
+							Text:   tmpl(tn.Title),
+							Weight: "Bolder",
+							Size:   "Medium",
+							Color:  titleColor,
+							Wrap:   true,
+						},
+						{
+							Type: "TextBlock",
+							Text: tmpl(tn.Message),
+							Wrap: true,
+						},
+					},
+					Actions: []adaptiveCardOpenURLAction{
+						{
+							Type:  "Action.OpenUrl",
+							Title: "View Rule",
+							URL:   ruleURL,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	u := tmpl(tn.URL)
+	if tmplErr != nil {
+		tn.log.Warn("failed to template Teams Workflows message", "err", tmplErr.Error())
+	}
+
+	b, err := json.Marshal(&msg)
+	if err != nil {
+		return false, errors.Wrap(err, "marshal json")
+	}
+	cmd := &models.SendWebhookSync{Url: u, Body: string(b)}
+
+	if err := tn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		return false, errors.Wrap(err, "send notification to Teams Workflows")
+	}
+
+	return true, nil
+}
+
+func (tn *TeamsWorkflowsNotifier) SendResolved() bool {
+	return !tn.GetDisableResolveMessage()
+}
+
+// Structs used to build a message with an adaptive card.
+// See: https://learn.microsoft.com/en-us/microsoftteams/platform/task-modules-and-cards/cards/cards-reference#adaptive-card
+type adaptiveCardMessage struct {
+	Type        string                   `json:"type"`
+	Attachments []adaptiveCardAttachment `json:"attachments"`
+}
+
+type adaptiveCardAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string                      `json:"$schema"`
+	Type    string                      `json:"type"`
+	Version string                      `json:"version"`
+	MSTeams map[string]string           `json:"msteams,omitempty"`
+	Body    []adaptiveCardTextBlock     `json:"body"`
+	Actions []adaptiveCardOpenURLAction `json:"actions,omitempty"`
+}
+
+type adaptiveCardTextBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap"`
+}
+
+type adaptiveCardOpenURLAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
@@ -0,0 +1,204 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestTeamsWorkflowsNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		statusCode   int
+		alerts       []*types.Alert
+		expMsg       map[string]interface{}
+		expInitError string
+		expMsgError  string
+	}{
+		{
+			name:       "Default config with one alert",
+			settings:   `{}`,
+			statusCode: http.StatusAccepted,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"type": "message",
+				"attachments": []map[string]interface{}{
+					{
+						"contentType": "application/vnd.microsoft.card.adaptive",
+						"content": map[string]interface{}{
+							"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+							"type":    "AdaptiveCard",
+							"version": "1.4",
+							"msteams": map[string]interface{}{"width": "Full"},
+							"body": []map[string]interface{}{
+								{
+									"type":   "TextBlock",
+									"text":   "[FIRING:1]  (val1)",
+									"weight": "Bolder",
+									"size":   "Medium",
+									"color":  "Attention",
+									"wrap":   true,
+								},
+								{
+									"type": "TextBlock",
+									"text": "**Firing**\n\nValue: [no value]\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n",
+									"wrap": true,
+								},
+							},
+							"actions": []map[string]interface{}{
+								{
+									"type":  "Action.OpenUrl",
+									"title": "View Rule",
+									"url":   "http://localhost/alerting/list",
+								},
+							},
+						},
+					},
+				},
+			},
+		}, {
+			name: "Custom config with multiple resolved alerts",
+			settings: `{
+				"title": "{{ len .Alerts }} alerts",
+				"message": "{{ len .Alerts.Firing }} alerts are firing, {{ len .Alerts.Resolved }} are resolved"
+			}`,
+			statusCode: http.StatusAccepted,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:   model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt:   timeNow().Add(-1),
+						StartsAt: timeNow().Add(-2),
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:   model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						EndsAt:   timeNow().Add(-1),
+						StartsAt: timeNow().Add(-2),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"type": "message",
+				"attachments": []map[string]interface{}{
+					{
+						"contentType": "application/vnd.microsoft.card.adaptive",
+						"content": map[string]interface{}{
+							"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+							"type":    "AdaptiveCard",
+							"version": "1.4",
+							"msteams": map[string]interface{}{"width": "Full"},
+							"body": []map[string]interface{}{
+								{
+									"type":   "TextBlock",
+									"text":   "2 alerts",
+									"weight": "Bolder",
+									"size":   "Medium",
+									"color":  "Good",
+									"wrap":   true,
+								},
+								{
+									"type": "TextBlock",
+									"text": "0 alerts are firing, 2 are resolved",
+									"wrap": true,
+								},
+							},
+							"actions": []map[string]interface{}{
+								{
+									"type":  "Action.OpenUrl",
+									"title": "View Rule",
+									"url":   "http://localhost/alerting/list",
+								},
+							},
+						},
+					},
+				},
+			},
+		}, {
+			name:       "Error response from the webhook",
+			settings:   `{}`,
+			statusCode: http.StatusBadRequest,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsgError: "send notification to Teams Workflows: Webhook response status 400 Bad Request",
+		}, {
+			name:         "Error in initing",
+			settings:     `{"url": ""}`,
+			expInitError: `failed to validate receiver "teams_workflows_testing" of type "teams-workflows": could not find url property in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newStandInServer(t, c.statusCode)
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			if _, ok := settingsJSON.CheckGet("url"); !ok {
+				settingsJSON.Set("url", server.URL)
+			}
+
+			m := &NotificationChannelConfig{
+				Name:     "teams_workflows_testing",
+				Type:     "teams-workflows",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewTeamsWorkflowsNotifier(m, httpWebhookSender{}, tmpl)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != "" {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError, err.Error())
+				return
+			}
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			require.Equal(t, http.MethodPost, requests[0].Method)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expBody), requests[0].Body)
+		})
+	}
+}
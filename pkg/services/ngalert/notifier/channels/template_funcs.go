@@ -0,0 +1,223 @@
+package channels
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+)
+
+// TemplateFuncs are the functions that the notification templates of Grafana can use on top of
+// the functions of the Alertmanager, such as toUpper and join. The templates must be created with
+// FromGlobs to use them.
+var TemplateFuncs = template.FuncMap{
+	"humanize":           humanize,
+	"humanize1024":       humanize1024,
+	"humanizeDuration":   humanizeDuration,
+	"humanizePercentage": humanizePercentage,
+	// reReplaceAll replaces the function of the Alertmanager, which panics on invalid regular expressions.
+	"reReplaceAll": reReplaceAll,
+	"toJSON":       toJSON,
+	"date":         date,
+	"tz":           tz,
+}
+
+// fromGlobsMtx serializes the creation of the templates by FromGlobs.
+var fromGlobsMtx sync.Mutex
+
+// FromGlobs returns the templates parsed from the files of the path globs, like the function of
+// the Alertmanager, with TemplateFuncs. The Alertmanager creates the templates only with its
+// default functions, and its templates cannot be created otherwise as their fields are not
+// exported, so the default functions are replaced with a copy that includes TemplateFuncs while
+// the templates are created, and are restored afterwards.
+//
+// The Alertmanager reads its default functions only when it creates templates, and Grafana
+// creates all of its templates with FromGlobs, so no other template can get or miss TemplateFuncs
+// while they are replaced. TestFromGlobsIsTheOnlyCaller checks that it stays that way.
+func FromGlobs(paths ...string) (*template.Template, error) {
+	fromGlobsMtx.Lock()
+	defer fromGlobsMtx.Unlock()
+
+	defaultFuncs := template.DefaultFuncs
+	funcs := make(template.FuncMap, len(defaultFuncs)+len(TemplateFuncs))
+	for name, fn := range defaultFuncs {
+		funcs[name] = fn
+	}
+	for name, fn := range TemplateFuncs {
+		funcs[name] = fn
+	}
+	template.DefaultFuncs = funcs
+	defer func() {
+		template.DefaultFuncs = defaultFuncs
+	}()
+
+	return template.FromGlobs(paths...)
+}
+
+// humanize formats a number with a metric prefix, such as 1.5k or 2.3m.
+func humanize(i interface{}) (string, error) {
+	v, err := toFloat64(i)
+	if err != nil {
+		return "", err
+	}
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v), nil
+	}
+	if math.Abs(v) >= 1 {
+		prefix := ""
+		for _, p := range []string{"k", "M", "G", "T", "P", "E", "Z", "Y"} {
+			if math.Abs(v) < 1000 {
+				break
+			}
+			prefix = p
+			v /= 1000
+		}
+		return fmt.Sprintf("%.4g%s", v, prefix), nil
+	}
+	prefix := ""
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+		prefix = p
+		v *= 1000
+	}
+	return fmt.Sprintf("%.4g%s", v, prefix), nil
+}
+
+// humanize1024 formats a number with a binary prefix, such as 1.5Ki or 2Gi.
+func humanize1024(i interface{}) (string, error) {
+	v, err := toFloat64(i)
+	if err != nil {
+		return "", err
+	}
+	if math.Abs(v) <= 1 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v), nil
+	}
+	prefix := ""
+	for _, p := range []string{"ki", "Mi", "Gi", "Ti", "Pi", "Ei", "Zi", "Yi"} {
+		if math.Abs(v) < 1024 {
+			break
+		}
+		prefix = p
+		v /= 1024
+	}
+	return fmt.Sprintf("%.4g%s", v, prefix), nil
+}
+
+// humanizeDuration formats a number of seconds as a duration, such as 1d 2h 3m 4s or 500ms.
+func humanizeDuration(i interface{}) (string, error) {
+	v, err := toFloat64(i)
+	if err != nil {
+		return "", err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v), nil
+	}
+	if v == 0 {
+		return "0s", nil
+	}
+	if math.Abs(v) >= 1 {
+		sign := ""
+		if v < 0 {
+			sign = "-"
+			v = -v
+		}
+		seconds := int64(v) % 60
+		minutes := (int64(v) / 60) % 60
+		hours := (int64(v) / 60 / 60) % 24
+		days := int64(v) / 60 / 60 / 24
+		if days != 0 {
+			return fmt.Sprintf("%s%dd %dh %dm %ds", sign, days, hours, minutes, seconds), nil
+		}
+		if hours != 0 {
+			return fmt.Sprintf("%s%dh %dm %ds", sign, hours, minutes, seconds), nil
+		}
+		if minutes != 0 {
+			return fmt.Sprintf("%s%dm %ds", sign, minutes, seconds), nil
+		}
+		return fmt.Sprintf("%s%.4gs", sign, v), nil
+	}
+	prefix := ""
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+		prefix = p
+		v *= 1000
+	}
+	return fmt.Sprintf("%.4g%ss", v, prefix), nil
+}
+
+// humanizePercentage formats a ratio as a percentage, such as 0.25 as 25%.
+func humanizePercentage(i interface{}) (string, error) {
+	v, err := toFloat64(i)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%.4g%%", v*100), nil
+}
+
+func reReplaceAll(pattern, repl, text string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(text, repl), nil
+}
+
+// toJSON returns the JSON encoding of a value, such as the labels of an alert.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// date formats a time with a Go layout, such as "2006-01-02 15:04:05 MST".
+// Use tz first to format the time in a timezone other than UTC.
+func date(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// tz returns the time in a timezone of the IANA database, such as Europe/Paris.
+func tz(name string, t time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+func toFloat64(i interface{}) (float64, error) {
+	switch v := i.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case time.Duration:
+		return v.Seconds(), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("can't convert %T to float", i)
+	}
+}
@@ -0,0 +1,165 @@
+package channels
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := FromGlobs()
+	require.NoError(t, err)
+
+	data := map[string]interface{}{
+		"StartsAt": time.Date(2021, 12, 1, 10, 30, 0, 0, time.UTC),
+		"Labels":   map[string]string{"alertname": "HighCPU", "instance": "host-1:9100"},
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		expected string
+		expError string
+	}{
+		{
+			name:     "humanize large number",
+			template: `{{ humanize 1234567 }}`,
+			expected: "1.235M",
+		}, {
+			name:     "humanize small number from a string",
+			template: `{{ humanize "0.00123" }}`,
+			expected: "1.23m",
+		}, {
+			name:     "humanize zero",
+			template: `{{ humanize 0 }}`,
+			expected: "0",
+		}, {
+			name:     "humanize invalid number",
+			template: `{{ humanize "abc" }}`,
+			expError: `strconv.ParseFloat: parsing "abc": invalid syntax`,
+		}, {
+			name:     "humanize1024",
+			template: `{{ humanize1024 1048576 }}`,
+			expected: "1Mi",
+		}, {
+			name:     "humanizeDuration",
+			template: `{{ humanizeDuration 93784 }} {{ humanizeDuration 0.25 }}`,
+			expected: "1d 2h 3m 4s 250ms",
+		}, {
+			name:     "humanizePercentage",
+			template: `{{ humanizePercentage 0.256 }}`,
+			expected: "25.6%",
+		}, {
+			name:     "reReplaceAll",
+			template: `{{ reReplaceAll ":[0-9]+$" "" .Labels.instance }}`,
+			expected: "host-1",
+		}, {
+			name:     "reReplaceAll with an invalid regular expression",
+			template: `{{ reReplaceAll "(" "" .Labels.instance }}`,
+			expError: "error parsing regexp: missing closing ): `(`",
+		}, {
+			name:     "toJSON",
+			template: `{{ toJSON .Labels }}`,
+			expected: `{"alertname":"HighCPU","instance":"host-1:9100"}`,
+		}, {
+			name:     "date in UTC",
+			template: `{{ .StartsAt | date "2006-01-02 15:04 MST" }}`,
+			expected: "2021-12-01 10:30 UTC",
+		}, {
+			name:     "date in a timezone",
+			template: `{{ .StartsAt | tz "Asia/Tokyo" | date "2006-01-02 15:04 MST" }}`,
+			expected: "2021-12-01 19:30 JST",
+		}, {
+			name:     "invalid timezone",
+			template: `{{ .StartsAt | tz "Mars/Olympus_Mons" | date "15:04" }}`,
+			expError: "unknown time zone Mars/Olympus_Mons",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := tmpl.ExecuteTextString(c.template, data)
+			if c.expError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, s)
+		})
+	}
+}
+
+func TestFromGlobsDoesNotChangeDefaultFuncs(t *testing.T) {
+	_, err := FromGlobs()
+	require.NoError(t, err)
+	for name := range TemplateFuncs {
+		if name == "reReplaceAll" {
+			continue
+		}
+		require.NotContains(t, template.DefaultFuncs, name)
+	}
+
+	// the templates of the Alertmanager do not have the functions of Grafana.
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+	_, err = tmpl.ExecuteTextString(`{{ humanize 1234 }}`, nil)
+	require.Error(t, err)
+}
+
+// TestFromGlobsIsTheOnlyCaller checks that Grafana does not create the templates of the Alertmanager,
+// or use its default functions, other than with FromGlobs, as FromGlobs replaces them while it
+// creates the templates.
+func TestFromGlobsIsTheOnlyCaller(t *testing.T) {
+	const templatePkg = "github.com/prometheus/alertmanager/template"
+	root := filepath.Join("..", "..", "..", "..")
+	var callers []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		if filepath.Base(path) == "template_funcs.go" && filepath.Base(filepath.Dir(path)) == "channels" {
+			return nil
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		name := ""
+		for _, imp := range f.Imports {
+			if p, _ := strconv.Unquote(imp.Path.Value); p == templatePkg {
+				name = "template"
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+			}
+		}
+		if name == "" {
+			return nil
+		}
+		if f, err = parser.ParseFile(fset, path, nil, 0); err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == name && (sel.Sel.Name == "FromGlobs" || sel.Sel.Name == "DefaultFuncs") {
+					callers = append(callers, fset.Position(sel.Pos()).String())
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, callers, "the templates of the Alertmanager must be created with channels.FromGlobs")
+}
@@ -0,0 +1,82 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// httpWebhookSender sends the webhooks over HTTP, so that tests can use local HTTP servers
+// as stand-ins of the services.
+type httpWebhookSender struct{}
+
+func (httpWebhookSender) SendWebhookSync(ctx context.Context, cmd *models.SendWebhookSync) error {
+	method := cmd.HttpMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+	request, err := http.NewRequestWithContext(ctx, method, cmd.Url, bytes.NewReader([]byte(cmd.Body)))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for k, v := range cmd.HttpHeader {
+		request.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Webhook response status %v", resp.Status)
+	}
+	return nil
+}
+
+// receivedRequest is a request received by a stand-in server.
+type receivedRequest struct {
+	Method string
+	Header http.Header
+	Body   string
+}
+
+// standInServer is a local HTTP server that records the requests it receives and
+// responds to them with a fixed status code.
+type standInServer struct {
+	*httptest.Server
+	mtx        sync.Mutex
+	requests   []receivedRequest
+	statusCode int
+}
+
+func newStandInServer(t *testing.T, statusCode int) *standInServer {
+	t.Helper()
+	s := &standInServer{statusCode: statusCode}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		s.mtx.Lock()
+		s.requests = append(s.requests, receivedRequest{Method: r.Method, Header: r.Header.Clone(), Body: string(b)})
+		s.mtx.Unlock()
+		w.WriteHeader(s.statusCode)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns the requests received by the server.
+func (s *standInServer) Requests() []receivedRequest {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]receivedRequest(nil), s.requests...)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -111,6 +112,7 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 	if err != nil {
 		return nil, err
 	}
+	notifications.RecordResponseStatus(ctx, resp.StatusCode)
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			logger.Warn("Failed to close response body", "err", err)
@@ -0,0 +1,114 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+var (
+	WebexAPIURL = "https://webexapis.com/v1/messages"
+)
+
+// WebexNotifier is responsible for sending
+// alert notifications to Webex rooms.
+type WebexNotifier struct {
+	*Base
+	APIURL   string
+	BotToken string
+	RoomID   string
+	Message  string
+	log      log.Logger
+	ns       notifications.WebhookSender
+	tmpl     *template.Template
+}
+
+// NewWebexNotifier is the constructor for the Webex notifier.
+func NewWebexNotifier(model *NotificationChannelConfig, ns notifications.WebhookSender, t *template.Template, fn GetDecryptedValueFn) (*WebexNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
+	}
+	if model.SecureSettings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "no secure settings supplied"}
+	}
+
+	botToken := fn(context.Background(), model.SecureSettings, "bot_token", model.Settings.Get("bot_token").MustString())
+	if botToken == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find Bot Token in settings"}
+	}
+
+	roomID := model.Settings.Get("room_id").MustString()
+	if roomID == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find Room ID in settings"}
+	}
+
+	return &WebexNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		APIURL:   model.Settings.Get("api_url").MustString(WebexAPIURL),
+		BotToken: botToken,
+		RoomID:   roomID,
+		Message:  model.Settings.Get("message").MustString(`{{ template "webex.default.message" . }}`),
+		log:      log.New("alerting.notifier.webex"),
+		ns:       ns,
+		tmpl:     t,
+	}, nil
+}
+
+// webexMessage is the body of a request to create a message in a room.
+// See: https://developer.webex.com/docs/api/v1/messages/create-a-message
+type webexMessage struct {
+	RoomID   string `json:"roomId"`
+	Markdown string `json:"markdown"`
+}
+
+// Notify sends an alert notification to Webex.
+func (wn *WebexNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, wn.tmpl, as, wn.log, &tmplErr)
+
+	msg := webexMessage{
+		RoomID:   tmpl(wn.RoomID),
+		Markdown: tmpl(wn.Message),
+	}
+	if tmplErr != nil {
+		wn.log.Warn("failed to template Webex message", "err", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        wn.APIURL,
+		Body:       string(body),
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Authorization": "Bearer " + wn.BotToken,
+		},
+	}
+
+	if err := wn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		wn.log.Error("Failed to send Webex message", "error", err, "webhook", wn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (wn *WebexNotifier) SendResolved() bool {
+	return !wn.GetDisableResolveMessage()
+}
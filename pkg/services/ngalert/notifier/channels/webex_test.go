@@ -0,0 +1,142 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestWebexNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		statusCode   int
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError string
+		expMsgError  string
+	}{
+		{
+			name:       "Default config with one alert",
+			settings:   `{"bot_token": "abcdefgh0123456789", "room_id": "room1"}`,
+			statusCode: http.StatusOK,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expMsg: `{
+				"roomId": "room1",
+				"markdown": "**[FIRING:1]  (val1)**\n\n**Firing**\n\nValue: [no value]\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n"
+			}`,
+		}, {
+			name: "Custom config with multiple alerts",
+			settings: `{
+				"bot_token": "abcdefgh0123456789",
+				"room_id": "room1",
+				"message": "{{ len .Alerts.Firing }} alerts are firing, {{ len .Alerts.Resolved }} are resolved"
+			}`,
+			statusCode: http.StatusOK,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expMsg: `{"roomId": "room1", "markdown": "2 alerts are firing, 0 are resolved"}`,
+		}, {
+			name:       "Error response from Webex",
+			settings:   `{"bot_token": "invalid", "room_id": "room1"}`,
+			statusCode: http.StatusUnauthorized,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsgError: "Webhook response status 401 Unauthorized",
+		}, {
+			name:         "Error in initing, missing bot token",
+			settings:     `{"room_id": "room1"}`,
+			expInitError: `failed to validate receiver "webex_testing" of type "webex": could not find Bot Token in settings`,
+		}, {
+			name:         "Error in initing, missing room ID",
+			settings:     `{"bot_token": "abcdefgh0123456789"}`,
+			expInitError: `failed to validate receiver "webex_testing" of type "webex": could not find Room ID in settings`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newStandInServer(t, c.statusCode)
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			settingsJSON.Set("api_url", server.URL)
+			secureSettings := make(map[string][]byte)
+
+			m := &NotificationChannelConfig{
+				Name:           "webex_testing",
+				Type:           "webex",
+				Settings:       settingsJSON,
+				SecureSettings: secureSettings,
+			}
+
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			decryptFn := secretsService.GetDecryptedValue
+			pn, err := NewWebexNotifier(m, httpWebhookSender{}, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != "" {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError, err.Error())
+				return
+			}
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			require.Equal(t, http.MethodPost, requests[0].Method)
+			require.Equal(t, "Bearer "+pn.BotToken, requests[0].Header.Get("Authorization"))
+			require.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+			require.JSONEq(t, c.expMsg, requests[0].Body)
+		})
+	}
+}
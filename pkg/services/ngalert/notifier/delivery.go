@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type deliveryKey struct{}
+
+// deliveryNotifier records the attempts of a notification channel to send a notification in the
+// delivery of the context, if there is one.
+type deliveryNotifier struct {
+	NotificationChannel
+	uid string
+}
+
+func (n *deliveryNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	delivery, ok := ctx.Value(deliveryKey{}).(*ngmodels.NotificationDelivery)
+	if !ok {
+		return n.NotificationChannel.Notify(ctx, as...)
+	}
+
+	ctx, statusCode := notifications.WithResponseStatusRecorder(ctx)
+	retry, err := n.NotificationChannel.Notify(ctx, as...)
+
+	delivery.IntegrationUID = n.uid
+	delivery.Alerts = len(as)
+	delivery.Attempts++
+	delivery.StatusCode = statusCode()
+	delivery.Error = ""
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	return retry, err
+}
+
+// deliveryStage saves the delivery of the notifications by an integration, once the stage that
+// sends them with retries is done.
+type deliveryStage struct {
+	next        notify.Stage
+	store       store.NotificationDeliveryStore
+	orgID       int64
+	receiver    string
+	integration notify.Integration
+	logger      log.Logger
+}
+
+func (s *deliveryStage) Exec(ctx context.Context, l gokitlog.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	delivery := &ngmodels.NotificationDelivery{
+		OrgID:            s.orgID,
+		Receiver:         s.receiver,
+		Integration:      s.integration.Name(),
+		IntegrationIndex: s.integration.Index(),
+		SentAt:           time.Now(),
+	}
+	delivery.GroupKey, _ = notify.GroupKey(ctx)
+
+	ctx, res, err := s.next.Exec(context.WithValue(ctx, deliveryKey{}, delivery), l, alerts...)
+
+	// The integration did not try to send a notification, such as when all the alerts are resolved
+	// and the integration does not send resolved notifications.
+	if delivery.Attempts == 0 && err == nil {
+		return ctx, res, err
+	}
+	delivery.Duration = time.Since(delivery.SentAt)
+	if err != nil && delivery.Error == "" {
+		delivery.Error = err.Error()
+	}
+
+	// The context of the pipeline might be done, such as when the retries time out.
+	if saveErr := s.store.SaveNotificationDelivery(context.Background(), delivery); saveErr != nil {
+		s.logger.Error("failed to save the delivery of the notification", "receiver", s.receiver, "integration", delivery.Integration, "err", saveErr)
+	}
+	return ctx, res, err
+}
@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type fakeNotificationChannel struct {
+	statusCodes []int
+	errs        []error
+	calls       int
+	resolved    bool
+}
+
+// Notify returns the status code and error of the call, or of the last call once they are all used.
+func (f *fakeNotificationChannel) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	i := f.calls
+	if i >= len(f.errs) {
+		i = len(f.errs) - 1
+	}
+	f.calls++
+	notifications.RecordResponseStatus(ctx, f.statusCodes[i])
+	return true, f.errs[i]
+}
+
+func (f *fakeNotificationChannel) SendResolved() bool {
+	return f.resolved
+}
+
+func TestDeliveryStage(t *testing.T) {
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: time.Now()}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(-time.Minute)}}
+
+	newStage := func(ch *fakeNotificationChannel, deliveryStore store.NotificationDeliveryStore) notify.Stage {
+		integration := notify.NewIntegration(&deliveryNotifier{NotificationChannel: ch, uid: "pd"}, ch, "pagerduty", 1)
+		return &deliveryStage{
+			next:        notify.NewRetryStage(integration, "ops", notify.NewMetrics(prometheus.NewRegistry())),
+			store:       deliveryStore,
+			orgID:       1,
+			receiver:    "ops",
+			integration: integration,
+			logger:      log.New("test"),
+		}
+	}
+	newContext := func(firing ...uint64) context.Context {
+		ctx := notify.WithGroupKey(context.Background(), `{}:{alertname="a"}`)
+		return notify.WithFiringAlerts(ctx, firing)
+	}
+
+	t.Run("should save the delivery with the attempts", func(t *testing.T) {
+		deliveryStore := &store.FakeNotificationDeliveryStore{}
+		ch := &fakeNotificationChannel{
+			statusCodes: []int{http.StatusInternalServerError, http.StatusAccepted},
+			errs:        []error{errors.New("failed to send HTTP request - status code 500"), nil},
+		}
+
+		_, _, err := newStage(ch, deliveryStore).Exec(newContext(1), gokitlog.NewNopLogger(), firing)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, deliveryStore.Len())
+		delivery := deliveryStore.Deliveries[0]
+		require.Equal(t, int64(1), delivery.OrgID)
+		require.Equal(t, "ops", delivery.Receiver)
+		require.Equal(t, "pagerduty", delivery.Integration)
+		require.Equal(t, "pd", delivery.IntegrationUID)
+		require.Equal(t, 1, delivery.IntegrationIndex)
+		require.Equal(t, `{}:{alertname="a"}`, delivery.GroupKey)
+		require.Equal(t, 1, delivery.Alerts)
+		require.Equal(t, 2, delivery.Attempts)
+		require.Equal(t, http.StatusAccepted, delivery.StatusCode)
+		require.True(t, delivery.Delivered())
+		require.NotZero(t, delivery.Duration)
+		require.False(t, delivery.SentAt.IsZero())
+	})
+
+	t.Run("should save the error of the last attempt", func(t *testing.T) {
+		deliveryStore := &store.FakeNotificationDeliveryStore{}
+		ch := &fakeNotificationChannel{
+			statusCodes: []int{http.StatusInternalServerError, http.StatusBadGateway},
+			errs: []error{
+				errors.New("failed to send HTTP request - status code 500"),
+				errors.New("failed to send HTTP request - status code 502"),
+			},
+		}
+		ctx, cancel := context.WithTimeout(newContext(1), 2*time.Second)
+		defer cancel()
+
+		_, _, err := newStage(ch, deliveryStore).Exec(ctx, gokitlog.NewNopLogger(), firing)
+		require.Error(t, err)
+
+		require.Equal(t, 1, deliveryStore.Len())
+		delivery := deliveryStore.Deliveries[0]
+		require.GreaterOrEqual(t, delivery.Attempts, 2)
+		require.Equal(t, http.StatusBadGateway, delivery.StatusCode)
+		require.Equal(t, "failed to send HTTP request - status code 502", delivery.Error)
+		require.False(t, delivery.Delivered())
+	})
+
+	t.Run("should not save the delivery when nothing is sent", func(t *testing.T) {
+		deliveryStore := &store.FakeNotificationDeliveryStore{}
+		ch := &fakeNotificationChannel{}
+
+		_, _, err := newStage(ch, deliveryStore).Exec(newContext(), gokitlog.NewNopLogger(), resolved)
+		require.NoError(t, err)
+
+		require.Equal(t, 0, ch.calls)
+		require.Equal(t, 0, deliveryStore.Len())
+	})
+}
@@ -39,9 +39,10 @@ type MultiOrgAlertmanager struct {
 	peer         ClusterPeer
 	settleCancel context.CancelFunc
 
-	configStore store.AlertingStore
-	orgStore    store.OrgStore
-	kvStore     kvstore.KVStore
+	configStore   store.AlertingStore
+	orgStore      store.OrgStore
+	deliveryStore store.NotificationDeliveryStore
+	kvStore       kvstore.KVStore
 
 	decryptFn channels.GetDecryptedValueFn
 
@@ -50,7 +51,7 @@ type MultiOrgAlertmanager struct {
 }
 
 func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore,
-	kvStore kvstore.KVStore, decryptFn channels.GetDecryptedValueFn, m *metrics.MultiOrgAlertmanager,
+	deliveryStore store.NotificationDeliveryStore, kvStore kvstore.KVStore, decryptFn channels.GetDecryptedValueFn, m *metrics.MultiOrgAlertmanager,
 	ns notifications.Service, l log.Logger,
 ) (*MultiOrgAlertmanager, error) {
 	moa := &MultiOrgAlertmanager{
@@ -59,6 +60,7 @@ func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore,
 		alertmanagers: map[int64]*Alertmanager{},
 		configStore:   configStore,
 		orgStore:      orgStore,
+		deliveryStore: deliveryStore,
 		kvStore:       kvStore,
 		decryptFn:     decryptFn,
 		metrics:       m,
@@ -106,6 +108,9 @@ func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore,
 func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 	moa.logger.Info("starting MultiOrg Alertmanager")
 
+	maintenance := time.NewTicker(maintenanceNotificationAndSilences)
+	defer maintenance.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -115,10 +120,26 @@ func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 			if err := moa.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
 				moa.logger.Error("error while synchronizing Alertmanager orgs", "err", err)
 			}
+		case <-maintenance.C:
+			moa.deleteOldNotificationDeliveries(ctx)
 		}
 	}
 }
 
+// deleteOldNotificationDeliveries deletes the deliveries of notifications that are older than the
+// retention of the notification log.
+func (moa *MultiOrgAlertmanager) deleteOldNotificationDeliveries(ctx context.Context) {
+	if moa.deliveryStore == nil {
+		return
+	}
+	deleted, err := moa.deliveryStore.DeleteNotificationDeliveries(ctx, time.Now().Add(-retentionNotificationsAndSilences))
+	if err != nil {
+		moa.logger.Error("failed to delete old notification deliveries", "err", err)
+		return
+	}
+	moa.logger.Debug("deleted old notification deliveries", "count", deleted)
+}
+
 func (moa *MultiOrgAlertmanager) LoadAndSyncAlertmanagersForOrgs(ctx context.Context) error {
 	moa.logger.Debug("synchronizing Alertmanagers for orgs")
 	// First, load all the organizations from the database.
@@ -174,7 +195,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 			// To export them, we need to translate the metrics from each individual registry and,
 			// then aggregate them on the main registry.
 			m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID))
-			am, err := newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m)
+			am, err := newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.deliveryStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m)
 			if err != nil {
 				moa.logger.Error("unable to create Alertmanager for org", "org", orgID, "err", err)
 			}
@@ -188,7 +209,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 				// This means that the configuration is gone but the organization, as well as the Alertmanager, exists.
 				moa.logger.Warn("Alertmanager exists for org but the configuration is gone. Applying the default configuration", "org", orgID)
 			}
-			err := alertmanager.SaveAndApplyDefaultConfig(ctx)
+			err := alertmanager.SaveAndApplyDefaultConfig(ctx, 0)
 			if err != nil {
 				moa.logger.Error("failed to apply the default Alertmanager configuration", "org", orgID)
 				continue
@@ -301,6 +322,22 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembers returns the name of this instance in the Alertmanager gossip cluster
+// and the names of all the alive members of the cluster, including itself. It returns
+// no members if high availability is not configured or the cluster has not settled.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok || !p.Ready() {
+		return "", nil
+	}
+	peers := p.Peers()
+	members := make([]string, 0, len(peers))
+	for _, member := range peers {
+		members = append(members, member.Name())
+	}
+	return p.Name(), members
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
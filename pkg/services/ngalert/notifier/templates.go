@@ -0,0 +1,207 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+var (
+	ErrInvalidTemplate    = errors.New("invalid template")
+	ErrUnknownIntegration = errors.New("unknown integration")
+	ErrAlertGroupNotFound = errors.New("alert group not found")
+)
+
+// TestTemplate renders the title and the body of the notifications of the integrations with the
+// templates of the configuration and the template of the request, without saving it.
+func (am *Alertmanager) TestTemplate(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*apimodels.TestTemplatesResults, error) {
+	integrations := c.Integrations
+	if len(integrations) == 0 {
+		integrations = make([]string, 0, len(channels.DefaultIntegrationTemplates))
+		for integration := range channels.DefaultIntegrationTemplates {
+			integrations = append(integrations, integration)
+		}
+		sort.Strings(integrations)
+	}
+	for _, integration := range integrations {
+		if _, ok := channels.DefaultIntegrationTemplates[integration]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownIntegration, integration)
+		}
+	}
+
+	receiver, groupLabels, alerts, err := am.testTemplateAlerts(c)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := am.getTemplateWithText(c.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = notify.WithReceiverName(ctx, receiver)
+	ctx = notify.WithGroupLabels(ctx, groupLabels)
+	var tmplErr error
+	_, data := channels.TmplText(ctx, tmpl, alerts, am.logger, &tmplErr)
+
+	res := &apimodels.TestTemplatesResults{Results: make([]apimodels.TestTemplatesResult, 0, len(integrations))}
+	for _, integration := range integrations {
+		templates := channels.DefaultIntegrationTemplates[integration]
+		// The title replaces only the title of the integrations that have one, as not all of them
+		// have a setting for it.
+		if c.Title != "" && templates.Title != "" {
+			templates.Title = c.Title
+		}
+		if c.Body != "" {
+			templates.Body = c.Body
+		}
+
+		result := apimodels.TestTemplatesResult{Integration: integration}
+		var err error
+		if result.Title, err = tmpl.ExecuteTextString(templates.Title, data); err != nil {
+			result.Error = fmt.Sprintf("failed to render the title: %s", err)
+		} else if result.Body, err = tmpl.ExecuteTextString(templates.Body, data); err != nil {
+			result.Error = fmt.Sprintf("failed to render the body: %s", err)
+		}
+		res.Results = append(res.Results, result)
+	}
+	return res, nil
+}
+
+// getTemplateWithText returns the templates of the configuration followed by the template text,
+// so that the definitions of the template text replace theirs.
+func (am *Alertmanager) getTemplateWithText(text string) (*template.Template, error) {
+	if text == "" {
+		return am.getTemplate()
+	}
+
+	// The templates can only be parsed from files, so the template text is written in the working
+	// directory of the Alertmanager. Applying a configuration removes the files of the working
+	// directory that are not templates of the configuration, so it cannot be applied until the
+	// file is removed.
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+	if !am.ready() {
+		return nil, errors.New("alertmanager is not initialized")
+	}
+
+	if err := os.MkdirAll(am.WorkingDirPath(), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create template directory: %w", err)
+	}
+	f, err := os.CreateTemp(am.WorkingDirPath(), "template-preview-*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template file: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(f.Name()); err != nil {
+			am.logger.Warn("failed to remove template file", "file", f.Name(), "err", err)
+		}
+	}()
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write template file: %w", err)
+	}
+
+	tmpl, err := am.configTemplate(f.Name())
+	if err != nil {
+		// The templates of the configuration are validated when it is saved.
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// testTemplateAlerts returns the receiver, the group labels and the alerts of the notifications to
+// render: the alert group of the request, its alerts, or a test alert.
+func (am *Alertmanager) testTemplateAlerts(c apimodels.TestTemplatesConfigBodyParams) (string, model.LabelSet, []*types.Alert, error) {
+	now := time.Now()
+
+	if c.AlertGroup != nil {
+		groups, err := am.GetAlertGroups(true, true, true, nil, "")
+		if err != nil {
+			return "", nil, nil, err
+		}
+		for _, group := range groups {
+			if group.Receiver == nil || group.Receiver.Name == nil || *group.Receiver.Name != c.AlertGroup.Receiver {
+				continue
+			}
+			groupLabels := make(model.LabelSet, len(group.Labels))
+			for k, v := range group.Labels {
+				groupLabels[model.LabelName(k)] = model.LabelValue(v)
+			}
+			if !groupLabels.Equal(c.AlertGroup.Labels) {
+				continue
+			}
+
+			alerts := make([]*types.Alert, 0, len(group.Alerts))
+			for _, a := range group.Alerts {
+				alert := &types.Alert{
+					Alert: model.Alert{
+						Labels:       make(model.LabelSet, len(a.Labels)),
+						Annotations:  make(model.LabelSet, len(a.Annotations)),
+						GeneratorURL: a.GeneratorURL.String(),
+					},
+					UpdatedAt: now,
+				}
+				for k, v := range a.Labels {
+					alert.Labels[model.LabelName(k)] = model.LabelValue(v)
+				}
+				for k, v := range a.Annotations {
+					alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
+				}
+				if a.StartsAt != nil {
+					alert.StartsAt = time.Time(*a.StartsAt)
+				}
+				if a.EndsAt != nil {
+					alert.EndsAt = time.Time(*a.EndsAt)
+				}
+				alerts = append(alerts, alert)
+			}
+			return c.AlertGroup.Receiver, groupLabels, alerts, nil
+		}
+		return "", nil, nil, ErrAlertGroupNotFound
+	}
+
+	if len(c.Alerts) == 0 {
+		alert := newTestAlert(apimodels.TestReceiversConfigBodyParams{}, now, now)
+		return "", model.LabelSet{}, []*types.Alert{&alert}, nil
+	}
+
+	alerts := make([]*types.Alert, 0, len(c.Alerts))
+	for _, a := range c.Alerts {
+		alert := &types.Alert{
+			Alert: model.Alert{
+				Labels:       make(model.LabelSet, len(a.Labels)),
+				Annotations:  make(model.LabelSet, len(a.Annotations)),
+				StartsAt:     time.Time(a.StartsAt),
+				EndsAt:       time.Time(a.EndsAt),
+				GeneratorURL: a.GeneratorURL.String(),
+			},
+			UpdatedAt: now,
+		}
+		for k, v := range a.Labels {
+			alert.Labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		for k, v := range a.Annotations {
+			alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if alert.StartsAt.IsZero() {
+			alert.StartsAt = now
+		}
+		alerts = append(alerts, alert)
+	}
+	return "", model.LabelSet{}, alerts, nil
+}
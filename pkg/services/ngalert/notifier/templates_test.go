@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+const testTemplateConfig = `{
+	"template_files": {
+		"custom": "{{ define \"custom.title\" }}Alerts: {{ len .Alerts }}{{ end }}"
+	},
+	"alertmanager_config": {
+		"route": {
+			"receiver": "grafana-default-email",
+			"group_by": ["alertname"],
+			"group_wait": "1h"
+		},
+		"receivers": [{
+			"name": "grafana-default-email",
+			"grafana_managed_receiver_configs": [{
+				"uid": "",
+				"name": "email receiver",
+				"type": "email",
+				"settings": {
+					"addresses": "<example@email.com>"
+				}
+			}]
+		}]
+	}
+}`
+
+func TestTestTemplate(t *testing.T) {
+	am := setupAMTest(t)
+
+	cfg, err := Load([]byte(testTemplateConfig))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(context.Background(), cfg, 0))
+
+	now := time.Now()
+	require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []amv2.PostableAlert{{
+		Annotations: amv2.LabelSet{"summary": "CPU is high"},
+		StartsAt:    strfmt.DateTime(now),
+		EndsAt:      strfmt.DateTime(now.Add(time.Hour)),
+		Alert: amv2.Alert{
+			Labels: amv2.LabelSet{"alertname": "HighCPU", "instance": "host-1:9100"},
+		},
+	}}}))
+	// The dispatcher groups the alerts asynchronously.
+	require.Eventually(t, func() bool {
+		groups, err := am.GetAlertGroups(true, true, true, nil, "")
+		return err == nil && len(groups) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	alerts := []amv2.PostableAlert{{
+		Annotations: amv2.LabelSet{"summary": "Disk is full"},
+		StartsAt:    strfmt.DateTime(now),
+		Alert: amv2.Alert{
+			Labels: amv2.LabelSet{"alertname": "DiskFull"},
+		},
+	}}
+
+	cases := []struct {
+		name     string
+		params   apimodels.TestTemplatesConfigBodyParams
+		expected []apimodels.TestTemplatesResult
+		expError error
+	}{
+		{
+			name: "test alert with the default templates",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Integrations: []string{"slack"},
+			},
+			expected: []apimodels.TestTemplatesResult{{
+				Integration: "slack",
+				Title:       "[FIRING:1]  (TestAlert Grafana)",
+				Body:        "**Firing**\n\nValue: [no value]\nLabels:\n - alertname = TestAlert\n - instance = Grafana\nAnnotations:\n - summary = Notification test\n",
+			}},
+		}, {
+			name: "alerts with the templates of the configuration",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Title:        `{{ template "custom.title" . }}`,
+				Body:         `{{ range .Alerts }}{{ .Annotations.summary }}{{ end }}`,
+				Integrations: []string{"slack", "telegram"},
+				Alerts:       alerts,
+			},
+			expected: []apimodels.TestTemplatesResult{{
+				Integration: "slack",
+				Title:       "Alerts: 1",
+				Body:        "Disk is full",
+			}, {
+				// The notifications of telegram do not have a title.
+				Integration: "telegram",
+				Body:        "Disk is full",
+			}},
+		}, {
+			name: "template replaces the default templates",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Template:     `{{ define "default.title" }}{{ .CommonLabels.alertname | toUpper }}{{ end }}`,
+				Integrations: []string{"sns"},
+				Alerts:       alerts,
+			},
+			expected: []apimodels.TestTemplatesResult{{
+				Integration: "sns",
+				Title:       "DISKFULL",
+				Body:        "**Firing**\n\nValue: [no value]\nLabels:\n - alertname = DiskFull\nAnnotations:\n - summary = Disk is full\n",
+			}},
+		}, {
+			name: "alert group of the Alertmanager",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Title:        `{{ .Receiver }} {{ .GroupLabels.alertname }}`,
+				Body:         `{{ range .Alerts }}{{ reReplaceAll ":[0-9]+$" "" .Labels.instance }}{{ end }}`,
+				Integrations: []string{"webhook"},
+				AlertGroup: &apimodels.TestTemplatesAlertGroup{
+					Receiver: "grafana-default-email",
+					Labels:   model.LabelSet{"alertname": "HighCPU"},
+				},
+			},
+			expected: []apimodels.TestTemplatesResult{{
+				Integration: "webhook",
+				Title:       "grafana-default-email HighCPU",
+				Body:        "host-1",
+			}},
+		}, {
+			name: "error of the rendering",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Body:         `{{ template "missing" . }}`,
+				Integrations: []string{"webhook"},
+			},
+			expected: []apimodels.TestTemplatesResult{{
+				Integration: "webhook",
+				Title:       "[FIRING:1]  (TestAlert Grafana)",
+				Error:       `failed to render the body: template: :1:12: executing "" at <{{template "missing" .}}>: template "missing" not defined`,
+			}},
+		}, {
+			name: "invalid template",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Template: `{{ define "default.title" }}`,
+			},
+			expError: ErrInvalidTemplate,
+		}, {
+			name: "unknown integration",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Integrations: []string{"carrier-pigeon"},
+			},
+			expError: ErrUnknownIntegration,
+		}, {
+			name: "unknown alert group",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				AlertGroup: &apimodels.TestTemplatesAlertGroup{
+					Receiver: "grafana-default-email",
+					Labels:   model.LabelSet{"alertname": "DiskFull"},
+				},
+			},
+			expError: ErrAlertGroupNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := am.TestTemplate(context.Background(), c.params)
+			if c.expError != nil {
+				require.ErrorIs(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, res.Results)
+		})
+	}
+
+	t.Run("the template file is removed from the working directory", func(t *testing.T) {
+		_, err := am.TestTemplate(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Template:     `{{ define "default.title" }}title{{ end }}`,
+			Integrations: []string{"slack"},
+		})
+		require.NoError(t, err)
+		files, err := os.ReadDir(am.WorkingDirPath())
+		require.NoError(t, err)
+		for _, f := range files {
+			require.NotContains(t, f.Name(), "template-preview")
+		}
+	})
+
+	t.Run("renders all the integrations by default", func(t *testing.T) {
+		res, err := am.TestTemplate(context.Background(), apimodels.TestTemplatesConfigBodyParams{})
+		require.NoError(t, err)
+		require.Len(t, res.Results, len(channels.DefaultIntegrationTemplates))
+		for _, r := range res.Results {
+			require.Empty(t, r.Error, r.Integration)
+		}
+	})
+}
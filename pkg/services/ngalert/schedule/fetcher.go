@@ -2,24 +2,141 @@ package schedule
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
+const (
+	// fullResyncInterval is the interval after which all the alert rules are fetched again,
+	// instead of only the alert rules that were updated since the previous fetch.
+	fullResyncInterval = 5 * time.Minute
+
+	// incrementalFetchOverlap is subtracted from the latest update of the alert rules in the
+	// index when fetching the updated alert rules, so that the alert rules saved by a replica
+	// whose clock is behind are not missed.
+	incrementalFetchOverlap = time.Minute
+)
+
+// alertRulesIndex contains the alert rules to schedule. It is updated with the alert rules
+// that were updated or deleted since the previous fetch, and resynced with all the alert rules
+// periodically.
+type alertRulesIndex struct {
+	mtx   sync.Mutex
+	rules map[models.AlertRuleKey]*models.AlertRule
+	// lastUpdated is the latest update of the alert rules in the index.
+	lastUpdated time.Time
+	// lastResync is the time of the last full fetch. It is zero when the index must be resynced.
+	lastResync time.Time
+}
+
+func newAlertRulesIndex() *alertRulesIndex {
+	return &alertRulesIndex{rules: make(map[models.AlertRuleKey]*models.AlertRule)}
+}
+
+func (idx *alertRulesIndex) needsResync(now time.Time) bool {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return idx.lastResync.IsZero() || now.Sub(idx.lastResync) >= fullResyncInterval
+}
+
+// updatedAfter returns the time from which the updated alert rules must be fetched.
+func (idx *alertRulesIndex) updatedAfter() time.Time {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return idx.lastUpdated.Add(-incrementalFetchOverlap)
+}
+
+// resync replaces the alert rules in the index.
+func (idx *alertRulesIndex) resync(now time.Time, rules []*models.AlertRule) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	idx.rules = make(map[models.AlertRuleKey]*models.AlertRule, len(rules))
+	idx.lastUpdated = time.Time{}
+	idx.addLocked(rules)
+	idx.lastResync = now
+}
+
+// update removes the deleted alert rules from the index, and then adds the updated alert rules
+// or replaces the existing ones, as an alert rule can be deleted and saved again with the same UID.
+func (idx *alertRulesIndex) update(rules []*models.AlertRule, deleted []models.AlertRuleKey) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	for _, key := range deleted {
+		delete(idx.rules, key)
+	}
+	idx.addLocked(rules)
+}
+
+func (idx *alertRulesIndex) addLocked(rules []*models.AlertRule) {
+	for _, rule := range rules {
+		idx.rules[rule.GetKey()] = rule
+		if rule.Updated.After(idx.lastUpdated) {
+			idx.lastUpdated = rule.Updated
+		}
+	}
+}
+
+// delete removes the alert rule from the index.
+func (idx *alertRulesIndex) delete(key models.AlertRuleKey) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	delete(idx.rules, key)
+}
+
+// invalidate forces the index to be resynced on the next fetch.
+func (idx *alertRulesIndex) invalidate() {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	idx.lastResync = time.Time{}
+}
+
+// all returns a copy of the alert rules in the index.
+func (idx *alertRulesIndex) all() []*models.AlertRule {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	result := make([]*models.AlertRule, 0, len(idx.rules))
+	for _, rule := range idx.rules {
+		r := *rule
+		result = append(result, &r)
+	}
+	return result
+}
+
+// getAlertRules returns the alert rules to schedule. Only the alert rules that were updated or
+// deleted since the previous call are fetched, unless the index must be resynced.
 func (sch *schedule) getAlertRules(ctx context.Context, disabledOrgs []int64) []*models.AlertRule {
 	start := time.Now()
 	defer func() {
 		sch.metrics.GetAlertRulesDuration.Observe(time.Since(start).Seconds())
 	}()
 
-	q := models.ListAlertRulesQuery{
+	now := sch.clock.Now()
+	if !sch.alertRules.needsResync(now) {
+		q := models.GetAlertRulesForSchedulingQuery{
+			ExcludeOrgs:  disabledOrgs,
+			UpdatedAfter: sch.alertRules.updatedAfter(),
+		}
+		if err := sch.ruleStore.GetAlertRulesForScheduling(ctx, &q); err != nil {
+			sch.log.Error("failed to fetch updated alert definitions", "err", err)
+			sch.alertRules.invalidate()
+			return nil
+		}
+		sch.alertRules.update(q.Result, q.ResultDeleted)
+		sch.log.Debug("updated alert rules fetched", "count", len(q.Result), "deleted", len(q.ResultDeleted))
+		return sch.alertRules.all()
+	}
+
+	q := models.GetAlertRulesForSchedulingQuery{
 		ExcludeOrgs: disabledOrgs,
 	}
 	err := sch.ruleStore.GetAlertRulesForScheduling(ctx, &q)
 	if err != nil {
 		sch.log.Error("failed to fetch alert definitions", "err", err)
+		sch.alertRules.invalidate()
 		return nil
 	}
-	return q.Result
+	sch.alertRules.resync(now, q.Result)
+	return sch.alertRules.all()
 }
@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestSchedule_getAlertRules(t *testing.T) {
+	sch, mockedClock := setupScheduler(t, store.NewFakeRuleStore(t), &store.FakeInstanceStore{}, store.NewFakeAdminConfigStore(t), nil)
+	ruleStore := sch.ruleStore.(*store.FakeRuleStore)
+	const orgID int64 = 1
+
+	// fetch returns the keys of the fetched alert rules, and the queries made to the store.
+	fetch := func() ([]models.AlertRuleKey, []models.GetAlertRulesForSchedulingQuery) {
+		ruleStore.RecordedOps = nil
+		rules := sch.getAlertRules(context.Background(), nil)
+		keys := make([]models.AlertRuleKey, 0, len(rules))
+		for _, rule := range rules {
+			keys = append(keys, rule.GetKey())
+		}
+		var queries []models.GetAlertRulesForSchedulingQuery
+		for _, op := range ruleStore.RecordedOps {
+			if q, ok := op.(models.GetAlertRulesForSchedulingQuery); ok {
+				queries = append(queries, q)
+			}
+		}
+		return keys, queries
+	}
+
+	rule1 := CreateTestAlertRule(t, ruleStore, 10, orgID, eval.Alerting)
+	t.Run("should fetch all alert rules the first time", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.True(t, queries[0].UpdatedAfter.IsZero())
+	})
+
+	rule2 := CreateTestAlertRule(t, ruleStore, 10, orgID, eval.Alerting)
+	t.Run("should fetch only the updated alert rules", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey(), rule2.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.False(t, queries[0].UpdatedAfter.IsZero())
+		require.True(t, queries[0].UpdatedAfter.Before(rule2.Updated))
+	})
+
+	t.Run("should only fetch the updated alert rules when none was updated", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey(), rule2.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.False(t, queries[0].UpdatedAfter.IsZero())
+	})
+
+	// the alert rule is deleted by another replica, so the scheduler is not notified.
+	require.NoError(t, ruleStore.DeleteAlertRuleByUID(context.Background(), orgID, rule2.UID))
+	t.Run("should remove the alert rules that were deleted", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.False(t, queries[0].UpdatedAfter.IsZero())
+	})
+
+	deleted := CreateTestAlertRule(t, ruleStore, 10, orgID, eval.Alerting)
+	_, _ = fetch()
+	require.NoError(t, ruleStore.DeleteAlertRuleByUID(context.Background(), orgID, deleted.UID))
+	inserted := CreateTestAlertRule(t, ruleStore, 10, orgID, eval.Alerting)
+	t.Run("should remove the deleted alert rules and add the inserted ones at once", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey(), inserted.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.False(t, queries[0].UpdatedAfter.IsZero())
+	})
+
+	require.NoError(t, ruleStore.DeleteAlertRuleByUID(context.Background(), orgID, inserted.UID))
+	sch.DeleteAlertRule(inserted.GetKey())
+	t.Run("should not fetch all alert rules when the deletion was notified", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.False(t, queries[0].UpdatedAfter.IsZero())
+	})
+
+	rule3 := CreateTestAlertRule(t, ruleStore, 10, orgID, eval.Alerting)
+	mockedClock.Add(fullResyncInterval)
+	t.Run("should fetch all alert rules periodically", func(t *testing.T) {
+		keys, queries := fetch()
+		require.ElementsMatch(t, []models.AlertRuleKey{rule1.GetKey(), rule3.GetKey()}, keys)
+		require.Len(t, queries, 1)
+		require.True(t, queries[0].UpdatedAfter.IsZero())
+	})
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// evaluationLimiter limits the number of concurrent evaluations of alert rules, globally and
+// for each organisation. A limit of 0 means no limit.
+type evaluationLimiter struct {
+	global    *semaphore.Weighted
+	perOrgMax int64
+
+	orgsMtx sync.Mutex
+	orgs    map[int64]*semaphore.Weighted
+}
+
+func newEvaluationLimiter(max, perOrgMax int64) *evaluationLimiter {
+	l := &evaluationLimiter{
+		perOrgMax: perOrgMax,
+		orgs:      make(map[int64]*semaphore.Weighted),
+	}
+	if max > 0 {
+		l.global = semaphore.NewWeighted(max)
+	}
+	return l
+}
+
+// enabled returns true if any of the limits is set.
+func (l *evaluationLimiter) enabled() bool {
+	return l.global != nil || l.perOrgMax > 0
+}
+
+func (l *evaluationLimiter) org(orgID int64) *semaphore.Weighted {
+	if l.perOrgMax <= 0 {
+		return nil
+	}
+	l.orgsMtx.Lock()
+	defer l.orgsMtx.Unlock()
+	s, ok := l.orgs[orgID]
+	if !ok {
+		s = semaphore.NewWeighted(l.perOrgMax)
+		l.orgs[orgID] = s
+	}
+	return s
+}
+
+// acquire waits until an evaluation of an alert rule of the organisation is allowed, and returns
+// the function that releases it once the evaluation is done. It returns the error of ctx if ctx
+// is done before. The limit of the organisation is acquired first, so that the evaluations that
+// wait for the limit of their organisation do not hold the global limit.
+func (l *evaluationLimiter) acquire(ctx context.Context, orgID int64) (func(), error) {
+	org := l.org(orgID)
+	if org != nil {
+		if err := org.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+	}
+	if l.global != nil {
+		if err := l.global.Acquire(ctx, 1); err != nil {
+			if org != nil {
+				org.Release(1)
+			}
+			return nil, err
+		}
+	}
+	return func() {
+		if l.global != nil {
+			l.global.Release(1)
+		}
+		if org != nil {
+			org.Release(1)
+		}
+	}, nil
+}
@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluationLimiter(t *testing.T) {
+	// tryAcquire acquires the limits, failing if they are not available within a short time.
+	tryAcquire := func(l *evaluationLimiter, orgID int64) (func(), error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		return l.acquire(ctx, orgID)
+	}
+
+	t.Run("should not limit when the limits are not set", func(t *testing.T) {
+		l := newEvaluationLimiter(0, 0)
+		require.False(t, l.enabled())
+		for i := 0; i < 10; i++ {
+			_, err := tryAcquire(l, 1)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("should limit the evaluations of all the organisations", func(t *testing.T) {
+		l := newEvaluationLimiter(2, 0)
+		require.True(t, l.enabled())
+		release, err := tryAcquire(l, 1)
+		require.NoError(t, err)
+		_, err = tryAcquire(l, 2)
+		require.NoError(t, err)
+		_, err = tryAcquire(l, 3)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release()
+		_, err = tryAcquire(l, 3)
+		require.NoError(t, err)
+	})
+
+	t.Run("should limit the evaluations of each organisation", func(t *testing.T) {
+		l := newEvaluationLimiter(0, 1)
+		require.True(t, l.enabled())
+		release, err := tryAcquire(l, 1)
+		require.NoError(t, err)
+		_, err = tryAcquire(l, 1)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		_, err = tryAcquire(l, 2)
+		require.NoError(t, err)
+
+		release()
+		_, err = tryAcquire(l, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("should not hold the global limit while waiting for the limit of the organisation", func(t *testing.T) {
+		l := newEvaluationLimiter(2, 1)
+		_, err := tryAcquire(l, 1)
+		require.NoError(t, err)
+		_, err = tryAcquire(l, 1)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		_, err = tryAcquire(l, 2)
+		require.NoError(t, err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -50,6 +52,13 @@ type ScheduleService interface {
 	overrideCfg(cfg SchedulerCfg)
 }
 
+// RecordingWriter writes the series of recording rules.
+type RecordingWriter interface {
+	// Write writes a sample at time t for each series of the frames, as the metric with the
+	// labels of the series and the extra labels.
+	Write(ctx context.Context, metric string, extraLabels map[string]string, t time.Time, frames []*data.Frame) error
+}
+
 type schedule struct {
 	// base tick rate (fastest possible configured check)
 	baseInterval time.Duration
@@ -57,6 +66,9 @@ type schedule struct {
 	// each alert rule gets its own channel and routine
 	registry alertRuleRegistry
 
+	// alertRules is updated with the alert rules that changed since the previous tick.
+	alertRules *alertRulesIndex
+
 	maxAttempts int64
 
 	clock clock.Clock
@@ -98,6 +110,25 @@ type schedule struct {
 	adminConfigPollInterval time.Duration
 	disabledOrgs            map[int64]struct{}
 	minRuleInterval         time.Duration
+
+	// clusterMembership is used to evaluate only the alert rules that this replica owns.
+	// When it is nil, all the alert rules are evaluated.
+	clusterMembership ClusterMembership
+	// handedOff contains the alert rules whose routines were stopped because another
+	// replica owns them now.
+	handedOffMtx sync.Mutex
+	handedOff    map[models.AlertRuleKey]struct{}
+
+	// recordingWriter writes the series of recording rules. The recording rules are not
+	// evaluated when it is nil.
+	recordingWriter RecordingWriter
+
+	// sequentialRuleGroups is true when the alert rules of a rule group are evaluated one after
+	// another with the same evaluation time.
+	sequentialRuleGroups bool
+
+	// limiter limits the number of concurrent evaluations.
+	limiter *evaluationLimiter
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -118,6 +149,15 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	ClusterMembership       ClusterMembership
+	RecordingWriter         RecordingWriter
+	// SequentialRuleGroups makes the scheduler evaluate the alert rules of a rule group one after
+	// another, in the order of the rule group, at the same evaluation time.
+	SequentialRuleGroups bool
+	// MaxConcurrentEvaluations limits the number of alert rules evaluated at the same time, globally
+	// and for each organisation. There is no limit when it is 0.
+	MaxConcurrentEvaluations       int64
+	MaxConcurrentEvaluationsPerOrg int64
 }
 
 // NewScheduler returns a new schedule.
@@ -126,6 +166,7 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 
 	sch := schedule{
 		registry:                alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]*alertRuleInfo)},
+		alertRules:              newAlertRulesIndex(),
 		maxAttempts:             cfg.MaxAttempts,
 		clock:                   cfg.C,
 		baseInterval:            cfg.BaseInterval,
@@ -149,6 +190,11 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		clusterMembership:       cfg.ClusterMembership,
+		handedOff:               map[models.AlertRuleKey]struct{}{},
+		recordingWriter:         cfg.RecordingWriter,
+		sequentialRuleGroups:    cfg.SequentialRuleGroups,
+		limiter:                 newEvaluationLimiter(cfg.MaxConcurrentEvaluations, cfg.MaxConcurrentEvaluationsPerOrg),
 	}
 	return &sch
 }
@@ -333,6 +379,7 @@ func (sch *schedule) UpdateAlertRule(key models.AlertRuleKey) {
 
 // DeleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
 func (sch *schedule) DeleteAlertRule(key models.AlertRuleKey) {
+	sch.alertRules.delete(key)
 	ruleInfo, ok := sch.registry.del(key)
 	if !ok {
 		sch.log.Info("unable to delete alert rule routine information by key", "uid", key.UID, "org_id", key.OrgID)
@@ -342,6 +389,40 @@ func (sch *schedule) DeleteAlertRule(key models.AlertRuleKey) {
 	ruleInfo.stop()
 }
 
+// handOffAlertRule stops the evaluation of an alert rule that is owned by another
+// replica. Unlike DeleteAlertRule, the firing alerts of the rule are not expired:
+// the other replica continues from the states saved in the database.
+func (sch *schedule) handOffAlertRule(key models.AlertRuleKey) {
+	ruleInfo, ok := sch.registry.del(key)
+	if !ok {
+		return
+	}
+	sch.log.Info("alert rule is handed off to another replica", "uid", key.UID, "org_id", key.OrgID)
+	sch.handedOffMtx.Lock()
+	sch.handedOff[key] = struct{}{}
+	sch.handedOffMtx.Unlock()
+	ruleInfo.stop()
+}
+
+// isHandedOff returns true, only once, if the routine of the alert rule was stopped
+// by handOffAlertRule.
+func (sch *schedule) isHandedOff(key models.AlertRuleKey) bool {
+	sch.handedOffMtx.Lock()
+	defer sch.handedOffMtx.Unlock()
+	_, ok := sch.handedOff[key]
+	delete(sch.handedOff, key)
+	return ok
+}
+
+// clusterMembers returns this replica and the live replicas that share the evaluation
+// of the alert rules, or no members if the evaluation is not sharded.
+func (sch *schedule) clusterMembers() (string, []string) {
+	if sch.clusterMembership == nil {
+		return "", nil
+	}
+	return sch.clusterMembership.ClusterMembers()
+}
+
 func (sch *schedule) adminConfigSync(ctx context.Context) error {
 	for {
 		select {
@@ -365,6 +446,8 @@ func (sch *schedule) adminConfigSync(ctx context.Context) error {
 
 func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	// the state cache is warmed with all the alert instances on startup.
+	firstTick := true
 	for {
 		select {
 		case tick := <-sch.heartbeat.C:
@@ -380,22 +463,34 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			alertRules := sch.getAlertRules(ctx, disabledOrgs)
 			sch.log.Debug("alert rules fetched", "count", len(alertRules), "disabled_orgs", disabledOrgs)
 
+			self, members := sch.clusterMembers()
+			var ruleShardKeys map[models.AlertRuleKey]models.AlertRuleKey
+			if len(members) > 0 {
+				ruleShardKeys = shardKeys(alertRules)
+			}
+
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
 			// each alert rule found also in this cycle is removed
 			// so, at the end, the remaining registered alert rules are the deleted ones
 			registeredDefinitions := sch.registry.keyMap()
 
-			type readyToRunItem struct {
-				key      models.AlertRuleKey
-				ruleInfo *alertRuleInfo
-				version  int64
-			}
-
 			readyToRun := make([]readyToRunItem, 0)
 			for _, item := range alertRules {
 				key := item.GetKey()
 				itemVersion := item.Version
+
+				if !ownsRule(self, members, ruleShardKeys[key]) {
+					// another replica evaluates the alert rule.
+					if sch.registry.exists(key) {
+						sch.handOffAlertRule(key)
+					} else {
+						sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+					}
+					delete(registeredDefinitions, key)
+					continue
+				}
+
 				ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 				// enforce minimum evaluation interval
@@ -407,7 +502,13 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 				invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 				if newRoutine && !invalidInterval {
+					warm := len(members) > 0 && !firstTick
+					rule := item
 					dispatcherGroup.Go(func() error {
+						if warm {
+							// the alert rule could have been evaluated by another replica until now.
+							sch.stateManager.WarmRule(ruleInfo.ctx, rule)
+						}
 						return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh)
 					})
 				}
@@ -419,29 +520,41 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 					continue
 				}
 
+				if item.IsPaused {
+					// the routine and the state of the alert instances of a paused alert rule
+					// are kept, so that they are not lost when the alert rule is resumed.
+					sch.stateManager.MarkRulePaused(key.OrgID, key.UID)
+					delete(registeredDefinitions, key)
+					continue
+				}
+
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, version: itemVersion})
+					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, version: itemVersion, rule: item})
 				}
 
 				// remove the alert rule from the registered alert rules
 				delete(registeredDefinitions, key)
 			}
 
-			var step int64 = 0
-			if len(readyToRun) > 0 {
-				step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
-			}
+			if sch.sequentialRuleGroups {
+				sch.evalRuleGroupsSequentially(tick, readyToRun)
+			} else {
+				var step int64 = 0
+				if len(readyToRun) > 0 {
+					step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+				}
 
-			for i := range readyToRun {
-				item := readyToRun[i]
+				for i := range readyToRun {
+					item := readyToRun[i]
 
-				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					success := item.ruleInfo.eval(tick, item.version)
-					if !success {
-						sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", "uid", item.key.UID, "org", item.key.OrgID, "time", tick)
-					}
-				})
+					time.AfterFunc(time.Duration(int64(i)*step), func() {
+						success := item.ruleInfo.eval(tick, item.version)
+						if !success {
+							sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", "uid", item.key.UID, "org", item.key.OrgID, "time", tick)
+						}
+					})
+				}
 			}
 
 			// unregister and stop routines of the deleted alert rules
@@ -449,6 +562,7 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 				sch.DeleteAlertRule(key)
 			}
 
+			firstTick = false
 			sch.metrics.SchedulePeriodicDuration.Observe(time.Since(start).Seconds())
 		case <-ctx.Done():
 			waitErr := dispatcherGroup.Wait()
@@ -541,14 +655,74 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		return q.Result, nil
 	}
 
+	record := func(ctx context.Context, alertRule *models.AlertRule, evalCtx *evalContext, logger log.Logger) error {
+		if sch.recordingWriter == nil {
+			logger.Debug("recording rules are disabled, skipping evaluation")
+			return nil
+		}
+		start := sch.clock.Now()
+		resp, err := sch.evaluator.QueriesAndExpressionsEval(alertRule.OrgID, alertRule.Data, alertRule.EvaluationTimeout, evalCtx.now, sch.expressionService)
+		dur := sch.clock.Now().Sub(start)
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+		if err == nil {
+			if res, ok := resp.Responses[alertRule.Condition]; !ok {
+				err = fmt.Errorf("no result for the query or expression %s", alertRule.Condition)
+			} else if res.Error != nil {
+				err = res.Error
+			}
+		}
+		if err != nil {
+			evalTotalFailures.Inc()
+			logger.Error("failed to evaluate recording rule", "duration", dur, "err", err)
+		} else {
+			logger.Debug("recording rule evaluated", "duration", dur)
+			if err = sch.recordingWriter.Write(ctx, alertRule.Record, alertRule.Labels, evalCtx.now, resp.Responses[alertRule.Condition].Frames); err != nil {
+				logger.Error("failed to write the series of the recording rule", "metric", alertRule.Record, "err", err)
+			}
+		}
+		// recording rules have no alert instances, so the state manager keeps their last evaluation instead.
+		sch.stateManager.SetRecordingRuleEvaluation(key, state.RecordingRuleEvaluation{
+			EvaluationTime:     evalCtx.now,
+			EvaluationDuration: dur,
+			Error:              err,
+		})
+		return err
+	}
+
+	var hysteresis map[string]struct{}
+	hysteresisVersion := int64(-1)
 	evaluate := func(ctx context.Context, alertRule *models.AlertRule, attempt int64, evalCtx *evalContext) error {
 		logger := logger.New("version", alertRule.Version, "attempt", attempt, "now", evalCtx.now)
+		if alertRule.IsRecording() {
+			return record(ctx, alertRule, evalCtx, logger)
+		}
 		start := sch.clock.Now()
 
+		// the hysteresis expressions of the rule only change with its version.
+		if hysteresisVersion != alertRule.Version {
+			refIDs, err := hysteresisRefIDs(alertRule.Data)
+			if err != nil {
+				logger.Error("failed to find the hysteresis expressions of the alert rule", "err", err)
+				return err
+			}
+			hysteresis, hysteresisVersion = refIDs, alertRule.Version
+		}
+		queries := alertRule.Data
+		if len(hysteresis) > 0 {
+			var err error
+			queries, err = withLoadedDimensions(alertRule.Data, hysteresis, sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID))
+			if err != nil {
+				logger.Error("failed to set the firing dimensions of the alert rule", "err", err)
+				return err
+			}
+		}
 		condition := models.Condition{
 			Condition: alertRule.Condition,
 			OrgID:     alertRule.OrgID,
-			Data:      alertRule.Data,
+			Data:      queries,
+
+			EvaluationTimeout: alertRule.EvaluationTimeout,
 		}
 		results, err := sch.evaluator.ConditionEval(&condition, evalCtx.now, sch.expressionService)
 		dur := sch.clock.Now().Sub(start)
@@ -617,8 +791,17 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.now)
+					if ctx.done != nil {
+						close(ctx.done)
+					}
 				}()
 
+				release, ok := sch.waitForEvaluation(grafanaCtx, key, ctx, currentRule)
+				if !ok {
+					return
+				}
+				defer release()
+
 				err := retryIfError(func(attempt int64) error {
 					// fetch latest alert rule version
 					if currentRule == nil || currentRule.Version < ctx.version {
@@ -636,13 +819,90 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				}
 			}()
 		case <-grafanaCtx.Done():
-			clearState()
+			if sch.isHandedOff(key) {
+				// the states are saved after every evaluation, the replica that
+				// owns the alert rule now continues from them.
+				sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+			} else {
+				clearState()
+			}
 			logger.Debug("stopping alert rule routine")
 			return nil
 		}
 	}
 }
 
+// waitForEvaluation waits until the concurrency limits allow the evaluation of the alert rule, and
+// returns the function that releases the limits once the evaluation is done. The evaluation is
+// dropped if it is not allowed before the next evaluation of the alert rule. It returns false
+// if the evaluation is dropped or the routine of the alert rule is stopped.
+func (sch *schedule) waitForEvaluation(ctx context.Context, key models.AlertRuleKey, evalCtx *evalContext, rule *models.AlertRule) (func(), bool) {
+	if !sch.limiter.enabled() {
+		return func() {}, true
+	}
+
+	interval := sch.baseInterval
+	if rule != nil && rule.IntervalSeconds > 0 {
+		interval = time.Duration(rule.IntervalSeconds) * time.Second
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, interval-sch.clock.Now().Sub(evalCtx.now))
+	defer cancel()
+
+	orgID := fmt.Sprint(key.OrgID)
+	queued := sch.metrics.EvalQueued.WithLabelValues(orgID)
+	queued.Inc()
+	release, err := sch.limiter.acquire(waitCtx, key.OrgID)
+	queued.Dec()
+	if err != nil {
+		if ctx.Err() == nil {
+			sch.metrics.EvalDropped.WithLabelValues(orgID).Inc()
+			sch.log.Warn("evaluation dropped because the concurrency limits were reached until the next evaluation", "uid", key.UID, "org", key.OrgID, "now", evalCtx.now)
+		}
+		return nil, false
+	}
+	return release, true
+}
+
+// hysteresisRefIDs returns the refIDs of the hysteresis expressions in queries.
+func hysteresisRefIDs(queries []models.AlertQuery) (map[string]struct{}, error) {
+	refIDs := make(map[string]struct{})
+	for i := range queries {
+		ok, err := queries[i].IsHysteresisExpression()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			refIDs[queries[i].RefID] = struct{}{}
+		}
+	}
+	return refIDs, nil
+}
+
+// withLoadedDimensions returns a copy of queries where the hysteresis expressions with
+// the given refIDs know the labels of the alert instances that are currently alerting,
+// so that these keep alerting until the recovery threshold is met.
+func withLoadedDimensions(queries []models.AlertQuery, refIDs map[string]struct{}, states []*state.State) ([]models.AlertQuery, error) {
+	loaded := make([]data.Labels, 0, len(states))
+	for _, s := range states {
+		if s.State == eval.Alerting {
+			loaded = append(loaded, s.Labels)
+		}
+	}
+	result := make([]models.AlertQuery, 0, len(queries))
+	for _, q := range queries {
+		if _, ok := refIDs[q.RefID]; !ok {
+			result = append(result, q)
+			continue
+		}
+		withLoaded, err := q.WithLoadedDimensions(loaded)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, withLoaded)
+	}
+	return result, nil
+}
+
 func (sch *schedule) saveAlertStates(ctx context.Context, states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
@@ -765,6 +1025,27 @@ func (a *alertRuleInfo) eval(t time.Time, version int64) bool {
 	}
 }
 
+// evalAndWait signals the rule evaluation routine to perform the evaluation of the rule, and
+// waits until the evaluation is done. Returns false if the loop is stopped.
+func (a *alertRuleInfo) evalAndWait(t time.Time, version int64) bool {
+	done := make(chan struct{})
+	select {
+	case a.evalCh <- &evalContext{
+		now:     t,
+		version: version,
+		done:    done,
+	}:
+	case <-a.ctx.Done():
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	case <-a.ctx.Done():
+		return false
+	}
+}
+
 // update signals the rule evaluation routine to update the internal state. Does nothing if the loop is stopped
 func (a *alertRuleInfo) update() bool {
 	select {
@@ -778,6 +1059,73 @@ func (a *alertRuleInfo) update() bool {
 type evalContext struct {
 	now     time.Time
 	version int64
+	// done is closed when the evaluation is done, if it is not nil.
+	done chan struct{}
+}
+
+type readyToRunItem struct {
+	key      models.AlertRuleKey
+	ruleInfo *alertRuleInfo
+	version  int64
+	rule     *models.AlertRule
+}
+
+// evalRuleGroupsSequentially spreads the evaluations of the rule groups across the base interval.
+// The alert rules of a rule group are evaluated one after another, in the order of the rule group,
+// and all of them are evaluated at the time of the tick.
+func (sch *schedule) evalRuleGroupsSequentially(tick time.Time, readyToRun []readyToRunItem) {
+	groups := groupReadyToRun(readyToRun)
+
+	var step int64 = 0
+	if len(groups) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(groups))
+	}
+
+	for i := range groups {
+		group := groups[i]
+
+		time.AfterFunc(time.Duration(int64(i)*step), func() {
+			for _, item := range group {
+				success := item.ruleInfo.evalAndWait(tick, item.version)
+				if !success {
+					sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", "uid", item.key.UID, "org", item.key.OrgID, "time", tick)
+				}
+			}
+		})
+	}
+}
+
+// groupReadyToRun groups the alert rules that are ready to run by rule group. The rule groups are
+// in the order of their first alert rule, and the alert rules of a rule group are in its order.
+func groupReadyToRun(readyToRun []readyToRunItem) [][]readyToRunItem {
+	type groupKey struct {
+		orgID        int64
+		namespaceUID string
+		ruleGroup    string
+	}
+	index := make(map[groupKey]int)
+	groups := make([][]readyToRunItem, 0)
+	for _, item := range readyToRun {
+		key := groupKey{orgID: item.rule.OrgID, namespaceUID: item.rule.NamespaceUID, ruleGroup: item.rule.RuleGroup}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], item)
+	}
+
+	for _, group := range groups {
+		group := group
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].rule.RuleGroupIndex != group[j].rule.RuleGroupIndex {
+				return group[i].rule.RuleGroupIndex < group[j].rule.RuleGroupIndex
+			}
+			return group[i].key.UID < group[j].key.UID
+		})
+	}
+	return groups
 }
 
 // overrideCfg is only used on tests.
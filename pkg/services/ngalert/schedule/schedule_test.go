@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 
 	"github.com/benbjohnson/clock"
@@ -105,7 +106,7 @@ func TestWarmStateCache(t *testing.T) {
 		Metrics:                 testMetrics.GetSchedulerMetrics(),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, ng.SQLStore)
+	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, ng.SQLStore, nil)
 	st.Warm(ctx)
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -119,6 +120,25 @@ func TestWarmStateCache(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("warming a rule replaces its cached entries", func(t *testing.T) {
+		st.RemoveByRuleUID(rule.OrgID, rule.UID)
+		st.Put([]*state.State{{AlertRuleUID: rule.UID, OrgID: rule.OrgID, CacheId: "stale", State: eval.Alerting}})
+
+		st.WarmRule(ctx, rule)
+
+		_, err := st.Get(rule.OrgID, rule.UID, "stale")
+		require.Error(t, err)
+		for _, entry := range expectedEntries {
+			cacheEntry, err := st.Get(entry.OrgID, entry.AlertRuleUID, entry.CacheId)
+			require.NoError(t, err)
+
+			if diff := cmp.Diff(entry, cacheEntry, cmpopts.IgnoreFields(state.State{}, "Results")); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				t.FailNow()
+			}
+		}
+	})
 }
 
 func TestAlertingTicker(t *testing.T) {
@@ -157,7 +177,7 @@ func TestAlertingTicker(t *testing.T) {
 			disabledOrgID: {},
 		},
 	}
-	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, ng.SQLStore)
+	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, ng.SQLStore, nil)
 	appUrl := &url.URL{
 		Scheme: "http",
 		Host:   "localhost",
@@ -237,6 +257,42 @@ func TestAlertingTicker(t *testing.T) {
 		tick := advanceClock(t, mockedClock)
 		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
 	})
+
+	// pause alert rule with one second interval
+	setPaused := func(rule *models.AlertRule, paused bool) {
+		q := models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(ctx, &q))
+		updated := *q.Result
+		updated.IsPaused = paused
+		require.NoError(t, dbstore.UpsertAlertRules(ctx, []store.UpsertRule{{Existing: q.Result, New: updated}}))
+	}
+	// the evaluations of the test fail, so the state is added to the cache.
+	st.Put([]*state.State{{AlertRuleUID: alerts[2].UID, OrgID: alerts[2].OrgID, CacheId: "test", State: eval.Alerting}})
+	setPaused(alerts[2], true)
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[1].GetKey()}
+	t.Run(fmt.Sprintf("on 9th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
+	t.Run("on 9th tick paused alert rules should not be stopped", func(t *testing.T) {
+		assertStopRun(t, stopAppliedCh)
+	})
+	t.Run("on 9th tick the states of paused alert rules should be kept", func(t *testing.T) {
+		states := st.GetStatesForRuleUID(alerts[2].OrgID, alerts[2].UID)
+		require.NotEmpty(t, states)
+		for _, s := range states {
+			require.Equal(t, models.StateReasonPaused, s.StateReason)
+		}
+	})
+
+	setPaused(alerts[2], false)
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[2].GetKey()}
+	t.Run(fmt.Sprintf("on 10th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
 }
 
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
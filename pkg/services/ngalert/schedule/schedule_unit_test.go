@@ -816,6 +816,96 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		// TODO needs some mocking/stubbing for Alertmanager and Sender to make sure it was not called
 		t.Skip()
 	})
+
+	t.Run("when the rule is a recording rule", func(t *testing.T) {
+		t.Run("it should write the series of the condition instead of alerting", func(t *testing.T) {
+			evalChan := make(chan *evalContext)
+			evalAppliedChan := make(chan time.Time)
+
+			sch, ruleStore, instanceStore, _, _ := createSchedule(evalAppliedChan)
+			writer := &fakeRecordingWriter{}
+			sch.recordingWriter = writer
+			rule := CreateTestAlertRule(t, ruleStore, 10, rand.Int63(), eval.Alerting)
+			rule.Record = "recorded"
+			rule.Labels = map[string]string{"team": "a"}
+
+			go func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan struct{}))
+			}()
+
+			expectedTime := time.UnixMicro(rand.Int63())
+			evalChan <- &evalContext{
+				now:     expectedTime,
+				version: rule.Version,
+			}
+			waitForTimeChannel(t, evalAppliedChan)
+
+			require.Len(t, writer.writes, 1)
+			require.Equal(t, "recorded", writer.writes[0].metric)
+			require.Equal(t, rule.Labels, writer.writes[0].extraLabels)
+			require.Equal(t, expectedTime, writer.writes[0].t)
+			require.Len(t, writer.writes[0].frames, 1)
+			value, err := writer.writes[0].frames[0].Fields[0].FloatAt(0)
+			require.NoError(t, err)
+			require.Equal(t, 1.0, value)
+
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			require.Empty(t, instanceStore.RecordedOps)
+
+			evaluation, ok := sch.stateManager.GetRecordingRuleEvaluation(rule.OrgID, rule.UID)
+			require.True(t, ok)
+			require.Equal(t, expectedTime, evaluation.EvaluationTime)
+			require.NoError(t, evaluation.Error)
+		})
+
+		t.Run("it should keep the error of the last evaluation", func(t *testing.T) {
+			evalChan := make(chan *evalContext)
+			evalAppliedChan := make(chan time.Time)
+
+			sch, ruleStore, _, _, _ := createSchedule(evalAppliedChan)
+			writer := &fakeRecordingWriter{err: errors.New("remote write failed")}
+			sch.recordingWriter = writer
+			rule := CreateTestAlertRule(t, ruleStore, 10, rand.Int63(), eval.Alerting)
+			rule.Record = "recorded"
+
+			go func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan struct{}))
+			}()
+
+			expectedTime := time.UnixMicro(rand.Int63())
+			evalChan <- &evalContext{
+				now:     expectedTime,
+				version: rule.Version,
+			}
+			waitForTimeChannel(t, evalAppliedChan)
+
+			evaluation, ok := sch.stateManager.GetRecordingRuleEvaluation(rule.OrgID, rule.UID)
+			require.True(t, ok)
+			require.Equal(t, expectedTime, evaluation.EvaluationTime)
+			require.EqualError(t, evaluation.Error, "remote write failed")
+		})
+	})
+}
+
+type fakeRecordingWrite struct {
+	metric      string
+	extraLabels map[string]string
+	t           time.Time
+	frames      []*data.Frame
+}
+
+type fakeRecordingWriter struct {
+	writes []fakeRecordingWrite
+	err    error
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, metric string, extraLabels map[string]string, t time.Time, frames []*data.Frame) error {
+	w.writes = append(w.writes, fakeRecordingWrite{metric: metric, extraLabels: extraLabels, t: t, frames: frames})
+	return w.err
 }
 
 func TestSchedule_alertRuleInfo(t *testing.T) {
@@ -865,6 +955,44 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 				t.Fatal("No message was received on eval channel")
 			}
 		})
+		t.Run("evalAndWait should wait until the evaluation is done", func(t *testing.T) {
+			r := newAlertRuleInfo(context.Background())
+			expected := time.Now()
+			resultCh := make(chan bool)
+			version := rand.Int63()
+			go func() {
+				resultCh <- r.evalAndWait(expected, version)
+			}()
+			select {
+			case ctx := <-r.evalCh:
+				require.Equal(t, version, ctx.version)
+				require.Equal(t, expected, ctx.now)
+				select {
+				case <-resultCh:
+					t.Fatal("evalAndWait returned before the evaluation was done")
+				case <-time.After(10 * time.Millisecond):
+				}
+				close(ctx.done)
+				require.True(t, <-resultCh)
+			case <-time.After(5 * time.Second):
+				t.Fatal("No message was received on eval channel")
+			}
+		})
+		t.Run("evalAndWait should exit when context is cancelled during the evaluation", func(t *testing.T) {
+			r := newAlertRuleInfo(context.Background())
+			resultCh := make(chan bool)
+			go func() {
+				resultCh <- r.evalAndWait(time.Now(), rand.Int63())
+			}()
+			<-r.evalCh
+			r.stop()
+			select {
+			case result := <-resultCh:
+				require.False(t, result)
+			case <-time.After(5 * time.Second):
+				t.Fatal("evalAndWait did not exit")
+			}
+		})
 	})
 	t.Run("when rule evaluation is stopped", func(t *testing.T) {
 		t.Run("Update should do nothing", func(t *testing.T) {
@@ -924,6 +1052,56 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 	})
 }
 
+func TestGroupReadyToRun(t *testing.T) {
+	item := func(uid, ruleGroup string, idx int) readyToRunItem {
+		rule := &models.AlertRule{OrgID: 1, UID: uid, NamespaceUID: "namespace", RuleGroup: ruleGroup, RuleGroupIndex: idx}
+		return readyToRunItem{key: rule.GetKey(), rule: rule}
+	}
+	uids := func(groups [][]readyToRunItem) [][]string {
+		result := make([][]string, 0, len(groups))
+		for _, group := range groups {
+			groupUIDs := make([]string, 0, len(group))
+			for _, item := range group {
+				groupUIDs = append(groupUIDs, item.key.UID)
+			}
+			result = append(result, groupUIDs)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name       string
+		readyToRun []readyToRunItem
+		expected   [][]string
+	}{
+		{
+			name:       "no alert rules",
+			readyToRun: nil,
+			expected:   [][]string{},
+		},
+		{
+			name:       "alert rules are sorted by their index in the rule group",
+			readyToRun: []readyToRunItem{item("c", "group", 3), item("a", "group", 1), item("b", "group", 2)},
+			expected:   [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:       "alert rules with the same index are sorted by UID",
+			readyToRun: []readyToRunItem{item("b", "group", 1), item("a", "group", 1)},
+			expected:   [][]string{{"a", "b"}},
+		},
+		{
+			name:       "alert rules are grouped by rule group",
+			readyToRun: []readyToRunItem{item("a", "group-1", 2), item("b", "group-2", 1), item("c", "group-1", 1)},
+			expected:   [][]string{{"c", "a"}, {"b"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, uids(groupReadyToRun(tc.readyToRun)))
+		})
+	}
+}
+
 func TestSchedule_UpdateAlertRule(t *testing.T) {
 	t.Run("when rule exists", func(t *testing.T) {
 		t.Run("it should call Update", func(t *testing.T) {
@@ -957,6 +1135,67 @@ func TestSchedule_UpdateAlertRule(t *testing.T) {
 	})
 }
 
+func TestSchedule_waitForEvaluation(t *testing.T) {
+	sch := setupSchedulerWithFakeStores(t)
+	sch.limiter = newEvaluationLimiter(1, 0)
+	key := models.AlertRuleKey{OrgID: 1, UID: "test"}
+	rule := &models.AlertRule{OrgID: key.OrgID, UID: key.UID, IntervalSeconds: 1}
+
+	release, ok := sch.waitForEvaluation(context.Background(), key, &evalContext{now: sch.clock.Now()}, rule)
+	require.True(t, ok)
+
+	t.Run("should drop the evaluation when the limits are reached until the next evaluation", func(t *testing.T) {
+		evalCtx := &evalContext{now: sch.clock.Now().Add(-900 * time.Millisecond)}
+		_, ok := sch.waitForEvaluation(context.Background(), key, evalCtx, rule)
+		require.False(t, ok)
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalDropped.WithLabelValues("1")))
+		require.Equal(t, 0.0, testutil.ToFloat64(sch.metrics.EvalQueued.WithLabelValues("1")))
+	})
+
+	t.Run("should not drop the evaluation when the routine is stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, ok := sch.waitForEvaluation(ctx, key, &evalContext{now: sch.clock.Now()}, rule)
+		require.False(t, ok)
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalDropped.WithLabelValues("1")))
+	})
+
+	t.Run("should wait until the limits are released", func(t *testing.T) {
+		queued := make(chan bool)
+		go func() {
+			_, ok := sch.waitForEvaluation(context.Background(), key, &evalContext{now: sch.clock.Now()}, rule)
+			queued <- ok
+		}()
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(sch.metrics.EvalQueued.WithLabelValues("1")) == 1
+		}, time.Second, time.Millisecond)
+		release()
+		require.True(t, <-queued)
+	})
+}
+
+func TestWithLoadedDimensions(t *testing.T) {
+	queries := []models.AlertQuery{
+		{RefID: "A", DatasourceUID: "abc", Model: json.RawMessage(`{"expr": "up"}`)},
+		{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type": "hysteresis", "expression": "$A"}`)},
+	}
+	states := []*state.State{
+		{State: eval.Alerting, Labels: data.Labels{"host": "a"}},
+		{State: eval.Normal, Labels: data.Labels{"host": "b"}},
+	}
+
+	refIDs, err := hysteresisRefIDs(queries)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"B": {}}, refIDs)
+
+	result, err := withLoadedDimensions(queries, refIDs, states)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, queries[0], result[0])
+	require.JSONEq(t, `{"type": "hysteresis", "expression": "$A", "loadedDimensions": [{"host": "a"}]}`, string(result[1].Model))
+	require.JSONEq(t, `{"type": "hysteresis", "expression": "$A"}`, string(queries[1].Model))
+}
+
 func TestSchedule_DeleteAlertRule(t *testing.T) {
 	t.Run("when rule exists", func(t *testing.T) {
 		t.Run("it should stop evaluation loop and remove the controller from registry", func(t *testing.T) {
@@ -988,6 +1227,47 @@ func TestSchedule_DeleteAlertRule(t *testing.T) {
 	})
 }
 
+func TestSchedule_handOffAlertRule(t *testing.T) {
+	t.Run("when rule exists", func(t *testing.T) {
+		t.Run("it should stop evaluation loop and keep the states saved in the database", func(t *testing.T) {
+			sch := setupSchedulerWithFakeStores(t)
+			rule := CreateTestAlertRule(t, sch.ruleStore.(*store.FakeRuleStore), 10, rand.Int63(), eval.Alerting)
+			key := rule.GetKey()
+			sch.stateManager.Put([]*state.State{{
+				AlertRuleUID: rule.UID,
+				OrgID:        rule.OrgID,
+				CacheId:      util.GenerateShortUID(),
+				State:        eval.Alerting,
+				StartsAt:     sch.clock.Now(),
+				EndsAt:       sch.clock.Now().Add(time.Minute),
+				Labels:       rule.Labels,
+			}})
+
+			info, _ := sch.registry.getOrCreateInfo(context.Background(), key)
+			stoppedChan := make(chan error)
+			go func() {
+				stoppedChan <- sch.ruleRoutine(info.ctx, key, info.evalCh, info.updateCh)
+			}()
+
+			sch.handOffAlertRule(key)
+			err := waitForErrChannel(t, stoppedChan)
+			require.NoError(t, err)
+
+			require.False(t, sch.registry.exists(key))
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			require.False(t, sch.isHandedOff(key), "the hand off should be consumed by the routine")
+		})
+	})
+	t.Run("when rule does not exist", func(t *testing.T) {
+		t.Run("should exit", func(t *testing.T) {
+			sch := setupSchedulerWithFakeStores(t)
+			key := generateRuleKey()
+			sch.handOffAlertRule(key)
+			require.False(t, sch.isHandedOff(key))
+		})
+	})
+}
+
 func generateRuleKey() models.AlertRuleKey {
 	return models.AlertRuleKey{
 		OrgID: rand.Int63(),
@@ -1017,7 +1297,7 @@ func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, ac
 	m := metrics.NewNGAlert(registry)
 	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
 	decryptFn := secretsService.GetDecryptedValue
-	moa, err := notifier.NewMultiOrgAlertmanager(&setting.Cfg{}, &notifier.FakeConfigStore{}, &notifier.FakeOrgStore{}, &notifier.FakeKVStore{}, decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"))
+	moa, err := notifier.NewMultiOrgAlertmanager(&setting.Cfg{}, &notifier.FakeConfigStore{}, &notifier.FakeOrgStore{}, nil, &notifier.FakeKVStore{}, decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"))
 	require.NoError(t, err)
 
 	schedCfg := SchedulerCfg{
@@ -1033,7 +1313,7 @@ func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, ac
 		Metrics:                 m.GetSchedulerMetrics(),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, m.GetStateMetrics(), nil, rs, is, mockstore.NewSQLStoreMock())
+	st := state.NewManager(schedCfg.Logger, m.GetStateMetrics(), nil, rs, is, mockstore.NewSQLStoreMock(), nil)
 	appUrl := &url.URL{
 		Scheme: "http",
 		Host:   "localhost",
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ClusterMembership provides the live replicas of a Grafana high availability setup.
+// It is used to shard the evaluation of alert rules across the replicas.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this replica and the names of all the live
+	// replicas, including this one. It returns no members when the membership is not
+	// known, in which case this replica evaluates all the alert rules.
+	ClusterMembers() (string, []string)
+}
+
+// ownsRule returns true if the alert rule with the shard key is evaluated by the replica
+// self (see shardKeys). Every rule is owned by exactly one of members, using rendezvous
+// hashing: when a member joins or leaves, only the rules owned by that member move to
+// other members.
+func ownsRule(self string, members []string, key models.AlertRuleKey) bool {
+	if len(members) == 0 {
+		return true
+	}
+	return ruleOwner(members, key) == self
+}
+
+// shardKeys returns the key that decides the owner of each alert rule. The alert rules that
+// are linked by their dependencies have the same key, so that they are evaluated by the same
+// replica: the state of the dependencies of an alert rule is only known to the replica that
+// evaluates them.
+func shardKeys(rules []*models.AlertRule) map[models.AlertRuleKey]models.AlertRuleKey {
+	parent := make(map[models.AlertRuleKey]models.AlertRuleKey, len(rules))
+	for _, rule := range rules {
+		key := rule.GetKey()
+		parent[key] = key
+	}
+	find := func(key models.AlertRuleKey) models.AlertRuleKey {
+		for parent[key] != key {
+			parent[key] = parent[parent[key]]
+			key = parent[key]
+		}
+		return key
+	}
+	for _, rule := range rules {
+		for _, dependency := range rule.Dependencies {
+			dependencyKey := models.AlertRuleKey{OrgID: rule.OrgID, UID: dependency.RuleUID}
+			if _, ok := parent[dependencyKey]; !ok {
+				continue
+			}
+			// the key with the lowest UID is the key of the linked alert rules, so that
+			// it does not depend on the order of the alert rules.
+			a, b := find(rule.GetKey()), find(dependencyKey)
+			if b.UID < a.UID {
+				a, b = b, a
+			}
+			parent[b] = a
+		}
+	}
+
+	keys := make(map[models.AlertRuleKey]models.AlertRuleKey, len(parent))
+	for key := range parent {
+		keys[key] = find(key)
+	}
+	return keys
+}
+
+// ruleOwner returns the member that has the highest score for the alert rule.
+func ruleOwner(members []string, key models.AlertRuleKey) string {
+	ruleID := strconv.FormatInt(key.OrgID, 10) + ":" + key.UID
+	var owner string
+	var ownerScore uint64
+	for _, member := range members {
+		score := rendezvousScore(member, ruleID)
+		if owner == "" || score > ownerScore || (score == ownerScore && member < owner) {
+			owner = member
+			ownerScore = score
+		}
+	}
+	return owner
+}
+
+func rendezvousScore(member, ruleID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(member))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(ruleID))
+	// FNV does not spread well names that differ only in the last characters, such
+	// as grafana-0 and grafana-1, so the hash is mixed with the finalizer of MurmurHash3.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestOwnsRule(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+	members := []string{"grafana-0", "grafana-1", "grafana-2"}
+
+	t.Run("all rules are owned without members", func(t *testing.T) {
+		for _, key := range keys {
+			require.True(t, ownsRule("", nil, key))
+		}
+	})
+
+	t.Run("every rule is owned by exactly one member", func(t *testing.T) {
+		owned := make(map[string]int)
+		for _, key := range keys {
+			var owners []string
+			for _, member := range members {
+				if ownsRule(member, members, key) {
+					owners = append(owners, member)
+				}
+			}
+			require.Len(t, owners, 1, "rule %v", key)
+			owned[owners[0]]++
+		}
+		for _, member := range members {
+			require.Greater(t, owned[member], 200, "member %s owns too few rules", member)
+		}
+	})
+
+	t.Run("ownership does not depend on the order of members", func(t *testing.T) {
+		reversed := []string{"grafana-2", "grafana-1", "grafana-0"}
+		for _, key := range keys {
+			require.Equal(t, ruleOwner(members, key), ruleOwner(reversed, key))
+		}
+	})
+
+	t.Run("only the rules of a member that leaves move", func(t *testing.T) {
+		remaining := []string{"grafana-0", "grafana-2"}
+		for _, key := range keys {
+			before := ruleOwner(members, key)
+			after := ruleOwner(remaining, key)
+			if before != "grafana-1" {
+				require.Equal(t, before, after, "rule %v", key)
+			}
+		}
+	})
+}
+
+func TestShardKeys(t *testing.T) {
+	rule := func(orgID int64, uid string, dependencies ...string) *models.AlertRule {
+		r := &models.AlertRule{OrgID: orgID, UID: uid}
+		for _, d := range dependencies {
+			r.Dependencies = append(r.Dependencies, models.RuleDependency{RuleUID: d})
+		}
+		return r
+	}
+	key := func(orgID int64, uid string) models.AlertRuleKey {
+		return models.AlertRuleKey{OrgID: orgID, UID: uid}
+	}
+
+	rules := []*models.AlertRule{
+		rule(1, "d", "c"),
+		rule(1, "c", "b"),
+		rule(1, "b"),
+		rule(1, "e", "b", "missing"),
+		rule(1, "f"),
+		// the dependencies are alert rules of the same organization.
+		rule(2, "a", "b"),
+		rule(2, "g"),
+	}
+
+	t.Run("the linked alert rules have the same key", func(t *testing.T) {
+		keys := shardKeys(rules)
+		require.Equal(t, map[models.AlertRuleKey]models.AlertRuleKey{
+			key(1, "b"): key(1, "b"),
+			key(1, "c"): key(1, "b"),
+			key(1, "d"): key(1, "b"),
+			key(1, "e"): key(1, "b"),
+			key(1, "f"): key(1, "f"),
+			key(2, "a"): key(2, "a"),
+			key(2, "g"): key(2, "g"),
+		}, keys)
+	})
+
+	t.Run("the keys do not depend on the order of the alert rules", func(t *testing.T) {
+		reversed := make([]*models.AlertRule, 0, len(rules))
+		for i := len(rules) - 1; i >= 0; i-- {
+			reversed = append(reversed, rules[i])
+		}
+		require.Equal(t, shardKeys(rules), shardKeys(reversed))
+	})
+
+	t.Run("the linked alert rules are owned by the same member", func(t *testing.T) {
+		members := []string{"grafana-0", "grafana-1", "grafana-2"}
+		keys := shardKeys(rules)
+		owner := ruleOwner(members, keys[key(1, "b")])
+		for _, uid := range []string{"c", "d", "e"} {
+			require.Equal(t, owner, ruleOwner(members, keys[key(1, uid)]))
+		}
+	})
+}
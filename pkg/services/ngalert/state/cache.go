@@ -17,19 +17,22 @@ import (
 )
 
 type cache struct {
-	states      map[int64]map[string]map[string]*State // orgID > alertRuleUID > stateID > state
-	mtxStates   sync.RWMutex
-	log         log.Logger
-	metrics     *metrics.State
-	externalURL *url.URL
+	states map[int64]map[string]map[string]*State // orgID > alertRuleUID > stateID > state
+	// recordingRules are the last evaluations of the recording rules.
+	recordingRules map[ngModels.AlertRuleKey]RecordingRuleEvaluation
+	mtxStates      sync.RWMutex
+	log            log.Logger
+	metrics        *metrics.State
+	externalURL    *url.URL
 }
 
 func newCache(logger log.Logger, metrics *metrics.State, externalURL *url.URL) *cache {
 	return &cache{
-		states:      make(map[int64]map[string]map[string]*State),
-		log:         logger,
-		metrics:     metrics,
-		externalURL: externalURL,
+		states:         make(map[int64]map[string]map[string]*State),
+		recordingRules: make(map[ngModels.AlertRuleKey]RecordingRuleEvaluation),
+		log:            logger,
+		metrics:        metrics,
+		externalURL:    externalURL,
 	}
 }
 
@@ -155,12 +158,36 @@ func (c *cache) removeByRuleUID(orgID int64, uid string) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
 	delete(c.states[orgID], uid)
+	delete(c.recordingRules, ngModels.AlertRuleKey{OrgID: orgID, UID: uid})
+}
+
+func (c *cache) setRecordingRuleEvaluation(key ngModels.AlertRuleKey, evaluation RecordingRuleEvaluation) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	c.recordingRules[key] = evaluation
+}
+
+func (c *cache) getRecordingRuleEvaluation(key ngModels.AlertRuleKey) (RecordingRuleEvaluation, bool) {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	evaluation, ok := c.recordingRules[key]
+	return evaluation, ok
+}
+
+// setReasonForRuleUID sets the reason of all entries in the state cache that match the given UID.
+func (c *cache) setReasonForRuleUID(orgID int64, uid string, reason string) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	for _, state := range c.states[orgID][uid] {
+		state.StateReason = reason
+	}
 }
 
 func (c *cache) reset() {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
 	c.states = make(map[int64]map[string]map[string]*State)
+	c.recordingRules = make(map[ngModels.AlertRuleKey]RecordingRuleEvaluation)
 }
 
 func (c *cache) recordMetrics() {
@@ -170,11 +197,12 @@ func (c *cache) recordMetrics() {
 	// Set default values to zero such that gauges are reset
 	// after all values from a single state disappear.
 	ct := map[eval.State]int{
-		eval.Normal:   0,
-		eval.Alerting: 0,
-		eval.Pending:  0,
-		eval.NoData:   0,
-		eval.Error:    0,
+		eval.Normal:     0,
+		eval.Alerting:   0,
+		eval.Pending:    0,
+		eval.NoData:     0,
+		eval.Error:      0,
+		eval.Suppressed: 0,
 	}
 
 	for org, orgMap := range c.states {
@@ -17,10 +17,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+
+	"github.com/prometheus/common/model"
 )
 
 var ResendDelay = 30 * time.Second
 
+// historyWriteTimeout is the maximum time to save the transitions of an evaluation.
+const historyWriteTimeout = 30 * time.Second
+
 type Manager struct {
 	log     log.Logger
 	metrics *metrics.State
@@ -32,10 +37,18 @@ type Manager struct {
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
 	sqlStore      sqlstore.Store
+	// historian records the transitions of alert instances between states.
+	// The transitions are not recorded when it is nil.
+	historian store.StateHistoryStore
+
+	// ephemeral is true when the state of alert instances is only kept in memory.
+	ephemeral bool
+	// now returns the time against which the stale alert instances are found.
+	now func() time.Time
 }
 
 func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore,
-	instanceStore store.InstanceStore, sqlStore sqlstore.Store) *Manager {
+	instanceStore store.InstanceStore, sqlStore sqlstore.Store, historian store.StateHistoryStore) *Manager {
 	manager := &Manager{
 		cache:         newCache(logger, metrics, externalURL),
 		quit:          make(chan struct{}),
@@ -45,12 +58,32 @@ func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
 		sqlStore:      sqlStore,
+		historian:     historian,
+		now:           time.Now,
 	}
 	go manager.recordMetrics()
 	return manager
 }
 
+// NewEphemeralManager returns a Manager that keeps the state of alert instances only in memory,
+// to evaluate alert rules outside of the scheduler such as in backtests. It does not create
+// annotations, delete alert instances from the database or record metrics. The stale alert
+// instances are found against the time returned by now.
+func NewEphemeralManager(logger log.Logger, externalURL *url.URL, now func() time.Time) *Manager {
+	return &Manager{
+		cache:       newCache(logger, nil, externalURL),
+		quit:        make(chan struct{}),
+		ResendDelay: ResendDelay,
+		log:         logger,
+		ephemeral:   true,
+		now:         now,
+	}
+}
+
 func (st *Manager) Close() {
+	if st.ephemeral {
+		return
+	}
 	st.quit <- struct{}{}
 }
 
@@ -92,25 +125,7 @@ func (st *Manager) Warm(ctx context.Context) {
 				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheId:              cacheId,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(entry, ruleForEntry))
 		}
 	}
 
@@ -119,6 +134,45 @@ func (st *Manager) Warm(ctx context.Context) {
 	}
 }
 
+// WarmRule replaces the cached states of the alert rule with the alert instances
+// that are saved in the database. It is used when the rule was evaluated by another
+// replica until now, so that its states are continued rather than started over.
+func (st *Manager) WarmRule(ctx context.Context, alertRule *ngModels.AlertRule) {
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "uid", alertRule.UID, "org", alertRule.OrgID, "msg", err.Error())
+		return
+	}
+
+	st.RemoveByRuleUID(alertRule.OrgID, alertRule.UID)
+	for _, entry := range cmd.Result {
+		st.set(st.stateFromInstance(entry, alertRule))
+	}
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.ListAlertInstancesQueryResult, alertRule *ngModels.AlertRule) *State {
+	lbs := map[string]string(entry.Labels)
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheId:              cacheId,
+		Labels:               lbs,
+		State:                translateInstanceState(entry.CurrentState),
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          alertRule.Annotations,
+	}
+}
+
 func (st *Manager) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
 	return st.cache.getOrCreate(ctx, alertRule, result)
 }
@@ -141,21 +195,73 @@ func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 	st.cache.removeByRuleUID(orgID, ruleUID)
 }
 
+// SetRecordingRuleEvaluation saves the last evaluation of a recording rule.
+func (st *Manager) SetRecordingRuleEvaluation(key ngModels.AlertRuleKey, evaluation RecordingRuleEvaluation) {
+	st.cache.setRecordingRuleEvaluation(key, evaluation)
+}
+
+// GetRecordingRuleEvaluation returns the last evaluation of a recording rule, if it was evaluated.
+func (st *Manager) GetRecordingRuleEvaluation(orgID int64, ruleUID string) (RecordingRuleEvaluation, bool) {
+	return st.cache.getRecordingRuleEvaluation(ngModels.AlertRuleKey{OrgID: orgID, UID: ruleUID})
+}
+
+// MarkRulePaused sets the reason of the state of the alert instances of a paused alert rule.
+// The states are kept as they are until the alert rule is resumed and evaluated again.
+func (st *Manager) MarkRulePaused(orgID int64, ruleUID string) {
+	st.cache.setReasonForRuleUID(orgID, ruleUID, ngModels.StateReasonPaused)
+}
+
 func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
 	var states []*State
+	var transitions []*ngModels.AlertStateHistoryEntry
 	processedResults := make(map[string]*State, len(results))
+	suppressedBy := st.firingDependency(alertRule)
 	for _, result := range results {
-		s := st.setNextState(ctx, alertRule, result)
+		s, oldState := st.setNextState(ctx, alertRule, result, suppressedBy)
 		states = append(states, s)
 		processedResults[s.CacheId] = s
+		if oldState != s.State {
+			reason := stateReason(result)
+			if s.State == eval.Suppressed {
+				reason = s.StateReason
+			}
+			transitions = append(transitions, newStateHistoryEntry(s, oldState, reason, NewEvaluationValues(result.Values), result.EvaluatedAt))
+		}
 	}
-	st.staleResultsHandler(ctx, alertRule, processedResults)
+	transitions = append(transitions, st.staleResultsHandler(ctx, alertRule, processedResults)...)
+	st.recordHistory(transitions)
 	return states
 }
 
-// Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
+// firingDependency returns the UID of the first dependency of the alert rule that has a firing
+// alert instance matching the matchers of the dependency, or an empty string if there is none.
+func (st *Manager) firingDependency(alertRule *ngModels.AlertRule) string {
+	for _, dependency := range alertRule.Dependencies {
+		matchers, err := dependency.LabelMatchers()
+		if err != nil {
+			st.log.Warn("ignoring the dependency of the alert rule", "uid", alertRule.UID, "dependency", dependency.RuleUID, "error", err)
+			continue
+		}
+		for _, s := range st.cache.getStatesForRuleUID(alertRule.OrgID, dependency.RuleUID) {
+			if s.State != eval.Alerting {
+				continue
+			}
+			lset := make(model.LabelSet, len(s.Labels))
+			for k, v := range s.Labels {
+				lset[model.LabelName(k)] = model.LabelValue(v)
+			}
+			if matchers.Matches(lset) {
+				return dependency.RuleUID
+			}
+		}
+	}
+	return ""
+}
+
+// Set the current state based on evaluation results, and return it with the previous state.
+// The alert instance is suppressed instead of Alerting or Pending if suppressedBy is not empty.
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, suppressedBy string) (*State, eval.State) {
 	currentState := st.getOrCreate(ctx, alertRule, result)
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
@@ -166,8 +272,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		Values:          NewEvaluationValues(result.Values),
 	})
 	currentState.LastEvaluationString = result.EvaluationString
+	// the alert rule is no longer paused once it is evaluated.
+	currentState.StateReason = ""
 	currentState.TrimResults(alertRule)
 	oldState := currentState.State
+	oldStartsAt := currentState.StartsAt
 
 	st.log.Debug("setting alert state", "uid", alertRule.UID)
 	switch result.State {
@@ -182,15 +291,24 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	case eval.Pending: // we do not emit results with this state
 	}
 
+	if suppressedBy != "" && (currentState.State == eval.Alerting || currentState.State == eval.Pending) {
+		since := result.EvaluatedAt
+		if oldState == eval.Suppressed {
+			since = oldStartsAt
+		}
+		currentState.suppress(result, ngModels.StateReasonSuppressedBy(suppressedBy), since)
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
-	// to Alertmanager.
-	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	// to Alertmanager. A firing alert instance that is suppressed is resolved too,
+	// as its notifications are no longer sent.
+	currentState.Resolved = oldState == eval.Alerting && (currentState.State == eval.Normal || currentState.State == eval.Suppressed)
 
 	st.set(currentState)
-	if oldState != currentState.State {
+	if oldState != currentState.State && !st.ephemeral {
 		go st.createAlertAnnotation(ctx, currentState.State, alertRule, result, oldState)
 	}
-	return currentState
+	return currentState, oldState
 }
 
 func (st *Manager) GetAll(orgID int64) []*State {
@@ -231,6 +349,8 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 		return eval.Alerting
 	case state == ngModels.InstanceStateNormal:
 		return eval.Normal
+	case state == ngModels.InstanceStateSuppressed:
+		return eval.Suppressed
 	default:
 		return eval.Error
 	}
@@ -282,26 +402,86 @@ func (st *Manager) createAlertAnnotation(ctx context.Context, new eval.State, al
 	}
 }
 
-func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.AlertRule, states map[string]*State) {
+// staleResultsHandler removes the states that are no longer returned by the evaluation,
+// and returns the transitions of the states that were not Normal.
+func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.AlertRule, states map[string]*State) []*ngModels.AlertStateHistoryEntry {
+	var transitions []*ngModels.AlertStateHistoryEntry
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(st.now(), s.LastEvaluationTime, alertRule.IntervalSeconds) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
-			ilbs := ngModels.InstanceLabels(s.Labels)
-			_, labelsHash, err := ilbs.StringAndHash()
-			if err != nil {
-				st.log.Error("unable to get labelsHash", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID)
+			if !st.ephemeral {
+				ilbs := ngModels.InstanceLabels(s.Labels)
+				_, labelsHash, err := ilbs.StringAndHash()
+				if err != nil {
+					st.log.Error("unable to get labelsHash", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID)
+				}
+
+				if err = st.instanceStore.DeleteAlertInstance(ctx, s.OrgID, s.AlertRuleUID, labelsHash); err != nil {
+					st.log.Error("unable to delete stale instance from database", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
+				}
 			}
 
-			if err = st.instanceStore.DeleteAlertInstance(ctx, s.OrgID, s.AlertRuleUID, labelsHash); err != nil {
-				st.log.Error("unable to delete stale instance from database", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
+			if s.State != eval.Normal {
+				transitions = append(transitions, newStateHistoryEntry(&State{
+					AlertRuleUID: s.AlertRuleUID,
+					OrgID:        s.OrgID,
+					Labels:       s.Labels,
+					State:        eval.Normal,
+				}, s.State, ngModels.StateReasonMissingSeries, nil, st.now()))
 			}
 		}
 	}
+	return transitions
+}
+
+// recordHistory saves the transitions of alert instances in the background, so that
+// the evaluation is not slowed down by the historian. The transitions are saved with their
+// own context, as the context of the evaluation is cancelled when the evaluation finishes.
+func (st *Manager) recordHistory(transitions []*ngModels.AlertStateHistoryEntry) {
+	if st.historian == nil || len(transitions) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), historyWriteTimeout)
+		defer cancel()
+		if err := st.historian.SaveAlertStateHistory(ctx, transitions); err != nil {
+			st.log.Error("failed to save alert state history", "count", len(transitions), "error", err.Error())
+		}
+	}()
+}
+
+func newStateHistoryEntry(s *State, previous eval.State, reason string, values map[string]*float64, evaluatedAt time.Time) *ngModels.AlertStateHistoryEntry {
+	return &ngModels.AlertStateHistoryEntry{
+		RuleOrgID:     s.OrgID,
+		RuleUID:       s.AlertRuleUID,
+		Labels:        ngModels.InstanceLabels(s.Labels),
+		PreviousState: ngModels.InstanceStateType(previous.String()),
+		State:         ngModels.InstanceStateType(s.State.String()),
+		Reason:        reason,
+		Values:        values,
+		EvaluatedAt:   evaluatedAt,
+	}
+}
+
+// stateReason returns the reason of the state of an alert instance that is not the
+// result of the condition.
+func stateReason(result eval.Result) string {
+	switch result.State {
+	case eval.Error:
+		if result.Error != nil {
+			return result.Error.Error()
+		}
+		return eval.Error.String()
+	case eval.NoData:
+		return eval.NoData.String()
+	default:
+		return ""
+	}
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+func isItStale(now time.Time, lastEval time.Time, intervalSeconds int64) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(now)
 }
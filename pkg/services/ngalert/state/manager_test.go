@@ -1482,7 +1482,7 @@ func TestProcessEvalResults(t *testing.T) {
 
 	for _, tc := range testCases {
 		ss := mockstore.NewSQLStoreMock()
-		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, ss)
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, ss, nil)
 		t.Run(tc.desc, func(t *testing.T) {
 			fakeAnnoRepo := store.NewFakeAnnotationsRepo()
 			annotations.SetRepository(fakeAnnoRepo)
@@ -1507,6 +1507,174 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 }
 
+func TestProcessEvalResults_StateHistory(t *testing.T) {
+	evaluationTime := time.Unix(1000, 0).UTC()
+	value := 42.0
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		Title:           "test_title",
+		IntervalSeconds: 10,
+		Labels:          map[string]string{"label": "test"},
+		NoDataState:     models.NoData,
+		ExecErrState:    models.ErrorErrState,
+	}
+	results := []eval.Results{
+		{eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: evaluationTime}},
+		{eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: evaluationTime.Add(10 * time.Second)}},
+		{eval.Result{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime.Add(20 * time.Second),
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Value: &value}},
+		}},
+		{eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Error, Error: errors.New("failed to query"), EvaluatedAt: evaluationTime.Add(30 * time.Second)}},
+	}
+
+	historian := &store.FakeStateHistoryStore{}
+	st := state.NewManager(log.New("test_state_history"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, mockstore.NewSQLStoreMock(), historian)
+	annotations.SetRepository(store.NewFakeAnnotationsRepo())
+	for _, res := range results {
+		// the context of the evaluation is cancelled before the transitions are saved.
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = st.ProcessEvalResults(ctx, rule, res)
+		cancel()
+	}
+
+	// the transitions are saved in the background
+	require.Eventually(t, func() bool { return historian.Len() == 2 }, time.Second, 10*time.Millisecond)
+	q := &models.GetAlertStateHistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID}
+	require.NoError(t, historian.GetAlertStateHistory(context.Background(), q))
+
+	// newest first
+	require.Equal(t, models.InstanceStateFiring, q.Result[0].PreviousState)
+	require.Equal(t, models.InstanceStateError, q.Result[0].State)
+	require.Equal(t, "failed to query", q.Result[0].Reason)
+	require.Equal(t, models.InstanceStateNormal, q.Result[1].PreviousState)
+	require.Equal(t, models.InstanceStateFiring, q.Result[1].State)
+	require.Empty(t, q.Result[1].Reason)
+	require.Equal(t, map[string]*float64{"B": &value}, q.Result[1].Values)
+	require.Equal(t, evaluationTime.Add(20*time.Second), q.Result[1].EvaluatedAt)
+	require.Equal(t, models.InstanceLabels{
+		"__alert_rule_namespace_uid__": "test_namespace_uid",
+		"__alert_rule_uid__":           "test_alert_rule_uid",
+		"alertname":                    "test_title",
+		"instance":                     "a",
+		"label":                        "test",
+	}, q.Result[1].Labels)
+}
+
+func TestProcessEvalResults_Dependencies(t *testing.T) {
+	evaluationTime := time.Unix(1000, 0).UTC()
+	newRule := func(uid string, dependencies ...models.RuleDependency) *models.AlertRule {
+		return &models.AlertRule{
+			OrgID:           1,
+			UID:             uid,
+			NamespaceUID:    "test_namespace_uid",
+			Title:           uid,
+			IntervalSeconds: 10,
+			NoDataState:     models.NoData,
+			ExecErrState:    models.ErrorErrState,
+			Dependencies:    dependencies,
+		}
+	}
+	result := func(state eval.State, labels data.Labels, i int) eval.Results {
+		return eval.Results{{Instance: labels, State: state, EvaluatedAt: evaluationTime.Add(time.Duration(i) * 10 * time.Second)}}
+	}
+
+	testCases := []struct {
+		name         string
+		dependency   models.RuleDependency
+		dependencies []eval.State
+		results      []eval.State
+		expected     []eval.State
+	}{
+		{
+			name:         "alert instances are suppressed while the dependency is firing",
+			dependency:   models.RuleDependency{RuleUID: "dependency"},
+			dependencies: []eval.State{eval.Alerting, eval.Alerting, eval.Normal, eval.Normal},
+			results:      []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting},
+			expected:     []eval.State{eval.Suppressed, eval.Suppressed, eval.Pending, eval.Alerting},
+		},
+		{
+			name:         "alert instances that are not firing are not suppressed",
+			dependency:   models.RuleDependency{RuleUID: "dependency"},
+			dependencies: []eval.State{eval.Alerting, eval.Alerting},
+			results:      []eval.State{eval.Normal, eval.Error},
+			expected:     []eval.State{eval.Normal, eval.Error},
+		},
+		{
+			name:         "alert instances are suppressed only by the alert instances matching the matchers",
+			dependency:   models.RuleDependency{RuleUID: "dependency", Matchers: []string{`instance="b"`}},
+			dependencies: []eval.State{eval.Alerting, eval.Alerting},
+			results:      []eval.State{eval.Alerting, eval.Alerting},
+			expected:     []eval.State{eval.Pending, eval.Alerting},
+		},
+		{
+			name:         "alert instances are not suppressed by a dependency with invalid matchers",
+			dependency:   models.RuleDependency{RuleUID: "dependency", Matchers: []string{`instance=~"(`}},
+			dependencies: []eval.State{eval.Alerting},
+			results:      []eval.State{eval.Alerting},
+			expected:     []eval.State{eval.Pending},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := state.NewEphemeralManager(log.New("test_dependencies"), nil, time.Now)
+			dependency := newRule("dependency")
+			rule := newRule("dependent", tc.dependency)
+			rule.For = 5 * time.Second
+			labels := data.Labels{"instance": "a"}
+
+			states := make([]eval.State, 0, len(tc.results))
+			for i := range tc.results {
+				_ = st.ProcessEvalResults(context.Background(), dependency, result(tc.dependencies[i], labels, i))
+				processed := st.ProcessEvalResults(context.Background(), rule, result(tc.results[i], labels, i))
+				require.Len(t, processed, 1)
+				states = append(states, processed[0].State)
+				if processed[0].State == eval.Suppressed {
+					require.Equal(t, "Suppressed by the alert rule dependency", processed[0].StateReason)
+					require.Equal(t, evaluationTime, processed[0].StartsAt)
+					require.False(t, processed[0].NeedsSending(st.ResendDelay))
+				} else {
+					require.Empty(t, processed[0].StateReason)
+				}
+			}
+			require.Equal(t, tc.expected, states)
+		})
+	}
+
+	t.Run("firing alert instances are resolved when they are suppressed", func(t *testing.T) {
+		st := state.NewEphemeralManager(log.New("test_dependencies"), nil, time.Now)
+		dependency := newRule("dependency")
+		rule := newRule("dependent", models.RuleDependency{RuleUID: "dependency"})
+		labels := data.Labels{"instance": "a"}
+
+		_ = st.ProcessEvalResults(context.Background(), dependency, result(eval.Normal, labels, 0))
+		processed := st.ProcessEvalResults(context.Background(), rule, result(eval.Alerting, labels, 0))
+		require.Len(t, processed, 1)
+		require.Equal(t, eval.Alerting, processed[0].State)
+
+		_ = st.ProcessEvalResults(context.Background(), dependency, result(eval.Alerting, labels, 1))
+		processed = st.ProcessEvalResults(context.Background(), rule, result(eval.Alerting, labels, 1))
+		require.Len(t, processed, 1)
+		require.Equal(t, eval.Suppressed, processed[0].State)
+		require.True(t, processed[0].Resolved)
+		require.True(t, processed[0].NeedsSending(st.ResendDelay))
+		require.Equal(t, processed[0].LastEvaluationTime, processed[0].EndsAt)
+		processed[0].LastSentAt = processed[0].LastEvaluationTime
+
+		// the resolved notification is sent only once.
+		processed = st.ProcessEvalResults(context.Background(), rule, result(eval.Alerting, labels, 2))
+		require.Len(t, processed, 1)
+		require.Equal(t, eval.Suppressed, processed[0].State)
+		require.False(t, processed[0].Resolved)
+		require.False(t, processed[0].NeedsSending(st.ResendDelay))
+	})
+}
+
 func TestStaleResultsHandler(t *testing.T) {
 	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
 	if err != nil {
@@ -1592,7 +1760,7 @@ func TestStaleResultsHandler(t *testing.T) {
 	for _, tc := range testCases {
 		ctx := context.Background()
 		sqlStore := mockstore.NewSQLStoreMock()
-		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, sqlStore)
+		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, sqlStore, nil)
 		st.Warm(ctx)
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
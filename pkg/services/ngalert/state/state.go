@@ -16,6 +16,7 @@ type State struct {
 	OrgID                int64
 	CacheId              string
 	State                eval.State
+	StateReason          string
 	Resolved             bool
 	Results              []Evaluation
 	LastEvaluationString string
@@ -24,6 +25,7 @@ type State struct {
 	LastEvaluationTime   time.Time
 	EvaluationDuration   time.Duration
 	LastSentAt           time.Time
+	KeepFiringSince      time.Time
 	Annotations          map[string]string
 	Labels               data.Labels
 	Error                error
@@ -47,9 +49,29 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]*float
 	return result
 }
 
+// RecordingRuleEvaluation is the last evaluation of a recording rule, which has no alert instances.
+type RecordingRuleEvaluation struct {
+	EvaluationTime     time.Time
+	EvaluationDuration time.Duration
+	// Error is the error of the evaluation or of the write of its series, if any.
+	Error error
+}
+
 func (a *State) resultNormal(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.Error = result.Error // should be nil since state is not error
 
+	// a firing alert instance keeps firing until it has been normal for KeepFiringFor.
+	if a.State == eval.Alerting && alertRule.KeepFiringFor > 0 {
+		if a.KeepFiringSince.IsZero() {
+			a.KeepFiringSince = result.EvaluatedAt
+		}
+		if result.EvaluatedAt.Sub(a.KeepFiringSince) < alertRule.KeepFiringFor {
+			a.setEndsAt(alertRule, result)
+			return
+		}
+	}
+	a.KeepFiringSince = time.Time{}
+
 	if a.State != eval.Normal {
 		a.EndsAt = result.EvaluatedAt
 		a.StartsAt = result.EvaluatedAt
@@ -59,6 +81,7 @@ func (a *State) resultNormal(alertRule *ngModels.AlertRule, result eval.Result)
 
 func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.Error = result.Error // should be nil since the state is not an error
+	a.KeepFiringSince = time.Time{}
 
 	switch a.State {
 	case eval.Alerting:
@@ -83,6 +106,7 @@ func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result
 
 func (a *State) resultError(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.Error = result.Error
+	a.KeepFiringSince = time.Time{}
 
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
@@ -114,6 +138,7 @@ func (a *State) resultError(alertRule *ngModels.AlertRule, result eval.Result) {
 
 func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.Error = result.Error
+	a.KeepFiringSince = time.Time{}
 
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
@@ -130,8 +155,19 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 	}
 }
 
+// suppress marks the alert instance suppressed since the given time instead of Alerting or
+// Pending. It is not sent to the Alertmanager, and it is Pending again when it is no longer
+// suppressed.
+func (a *State) suppress(result eval.Result, reason string, since time.Time) {
+	a.StartsAt = since
+	a.EndsAt = result.EvaluatedAt
+	a.KeepFiringSince = time.Time{}
+	a.State = eval.Suppressed
+	a.StateReason = reason
+}
+
 func (a *State) NeedsSending(resendDelay time.Duration) bool {
-	if a.State == eval.Pending || a.State == eval.Normal && !a.Resolved {
+	if a.State == eval.Pending || (a.State == eval.Normal || a.State == eval.Suppressed) && !a.Resolved {
 		return false
 	}
 	// if LastSentAt is before or equal to LastEvaluationTime + resendDelay, send again
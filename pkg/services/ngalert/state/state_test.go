@@ -100,6 +100,27 @@ func TestNeedsSending(t *testing.T) {
 				LastSentAt:         evaluationTime.Add(-1 * time.Minute),
 			},
 		},
+		{
+			name:        "state: suppressed + resolved sends after a minute",
+			resendDelay: 1 * time.Minute,
+			expected:    true,
+			testState: &State{
+				State:              eval.Suppressed,
+				Resolved:           true,
+				LastEvaluationTime: evaluationTime,
+				LastSentAt:         evaluationTime.Add(-1 * time.Minute),
+			},
+		},
+		{
+			name:        "state: suppressed but not resolved does not send after a minute",
+			resendDelay: 1 * time.Minute,
+			expected:    false,
+			testState: &State{
+				State:              eval.Suppressed,
+				LastEvaluationTime: evaluationTime,
+				LastSentAt:         evaluationTime.Add(-1 * time.Minute),
+			},
+		},
 		{
 			name:        "state: no-data, needs to be re-sent",
 			expected:    true,
@@ -230,3 +251,59 @@ func TestSetEndsAt(t *testing.T) {
 		})
 	}
 }
+
+func TestKeepFiringFor(t *testing.T) {
+	evaluationTime, _ := time.Parse("2006-01-02", "2021-03-25")
+	testCases := []struct {
+		name          string
+		keepFiringFor time.Duration
+		results       []eval.State
+		expected      []eval.State
+	}{
+		{
+			name:     "keep_firing_for=unset - resolves on the first normal evaluation",
+			results:  []eval.State{eval.Alerting, eval.Normal, eval.Normal},
+			expected: []eval.State{eval.Alerting, eval.Normal, eval.Normal},
+		},
+		{
+			name:          "keep_firing_for=20s,interval=10s - resolves after being normal for 20s",
+			keepFiringFor: 20 * time.Second,
+			results:       []eval.State{eval.Alerting, eval.Normal, eval.Normal, eval.Normal, eval.Normal},
+			expected:      []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.Normal},
+		},
+		{
+			name:          "keep_firing_for=20s,interval=10s - restarts when the condition is met again",
+			keepFiringFor: 20 * time.Second,
+			results:       []eval.State{eval.Alerting, eval.Normal, eval.Normal, eval.Alerting, eval.Normal, eval.Normal, eval.Normal},
+			expected:      []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal},
+		},
+		{
+			name:          "keep_firing_for=20s,interval=10s - does not apply to alerts that are not firing",
+			keepFiringFor: 20 * time.Second,
+			results:       []eval.State{eval.Normal, eval.Normal},
+			expected:      []eval.State{eval.Normal, eval.Normal},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &ngmodels.AlertRule{IntervalSeconds: 10, KeepFiringFor: tc.keepFiringFor}
+			s := &State{State: eval.Normal}
+			states := make([]eval.State, 0, len(tc.results))
+			for i, r := range tc.results {
+				result := eval.Result{State: r, EvaluatedAt: evaluationTime.Add(time.Duration(i) * 10 * time.Second)}
+				switch r {
+				case eval.Alerting:
+					s.resultAlerting(rule, result)
+				case eval.Normal:
+					s.resultNormal(rule, result)
+				}
+				states = append(states, s.State)
+			}
+			assert.Equal(t, tc.expected, states)
+			if s.State == eval.Normal {
+				assert.True(t, s.KeepFiringSince.IsZero())
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/services/guardian"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -24,15 +26,24 @@ const AlertRuleMaxTitleLength = 190
 // AlertRuleMaxRuleGroupNameLength is the maximum length of the alert rule group name
 const AlertRuleMaxRuleGroupNameLength = 190
 
+// alertRuleTombstoneRetention is how long the deletions of alert rules are recorded. It must be
+// longer than the interval at which the scheduler fetches all the alert rules.
+const alertRuleTombstoneRetention = time.Hour
+
 type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
 	RuleGroupConfig apimodels.PostableRuleGroupConfig
+	// KeepUIDs creates the rules whose UID does not exist yet with that UID, instead of failing.
+	// It is used by provisioning, where the UID identifies the rule across updates.
+	KeepUIDs bool
 }
 
 type UpsertRule struct {
 	Existing *ngmodels.AlertRule
 	New      ngmodels.AlertRule
+	// KeepUID creates the rule with its UID if no rule with that UID exists.
+	KeepUID bool
 }
 
 // Store is the interface for persisting alert rules and instances
@@ -42,7 +53,7 @@ type RuleStore interface {
 	DeleteRuleGroupAlertRules(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string) ([]string, error)
 	DeleteAlertInstancesByRuleUID(ctx context.Context, orgID int64, ruleUID string) error
 	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) error
-	GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error
+	GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.GetAlertRulesForSchedulingQuery) error
 	GetOrgAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error
 	GetNamespaceAlertRules(ctx context.Context, query *ngmodels.ListNamespaceAlertRulesQuery) error
 	GetRuleGroupAlertRules(ctx context.Context, query *ngmodels.ListRuleGroupAlertRulesQuery) error
@@ -74,6 +85,10 @@ func (st DBstore) DeleteAlertRuleByUID(ctx context.Context, orgID int64, ruleUID
 			return err
 		}
 
+		if err := addAlertRuleTombstones(sess, orgID, []string{ruleUID}); err != nil {
+			return err
+		}
+
 		_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
 
 		if err != nil {
@@ -84,10 +99,29 @@ func (st DBstore) DeleteAlertRuleByUID(ctx context.Context, orgID int64, ruleUID
 		if err != nil {
 			return err
 		}
+
+		_, err = sess.Exec("DELETE FROM alert_state_history WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 }
 
+// addAlertRuleTombstones records the deletion of the alert rules, so that the scheduler can
+// remove them without fetching all the alert rules. The tombstones that are older than
+// alertRuleTombstoneRetention are removed.
+func addAlertRuleTombstones(sess *sqlstore.DBSession, orgID int64, ruleUIDs []string) error {
+	now := TimeNow()
+	for _, uid := range ruleUIDs {
+		if _, err := sess.Exec("INSERT INTO alert_rule_tombstone (org_id, uid, deleted) VALUES (?, ?, ?)", orgID, uid, now.Unix()); err != nil {
+			return err
+		}
+	}
+	_, err := sess.Exec("DELETE FROM alert_rule_tombstone WHERE deleted < ?", now.Add(-alertRuleTombstoneRetention).Unix())
+	return err
+}
+
 // DeleteNamespaceAlertRules is a handler for deleting namespace alert rules. A list of deleted rule UIDs are returned.
 func (st DBstore) DeleteNamespaceAlertRules(ctx context.Context, orgID int64, namespaceUID string) ([]string, error) {
 	ruleUIDs := []string{}
@@ -109,12 +143,22 @@ func (st DBstore) DeleteNamespaceAlertRules(ctx context.Context, orgID int64, na
 			return err
 		}
 
+		if err := addAlertRuleTombstones(sess, orgID, ruleUIDs); err != nil {
+			return err
+		}
+
 		if _, err := sess.Exec(`DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid NOT IN (
 			SELECT uid FROM alert_rule where org_id = ?
 		)`, orgID, orgID); err != nil {
 			return err
 		}
 
+		if _, err := sess.Exec(`DELETE FROM alert_state_history WHERE rule_org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 	return ruleUIDs, err
@@ -146,12 +190,22 @@ func (st DBstore) DeleteRuleGroupAlertRules(ctx context.Context, orgID int64, na
 			return err
 		}
 
+		if err := addAlertRuleTombstones(sess, orgID, ruleUIDs); err != nil {
+			return err
+		}
+
 		if _, err := sess.Exec(`DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid NOT IN (
 			SELECT uid FROM alert_rule where org_id = ?
 		)`, orgID, orgID); err != nil {
 			return err
 		}
 
+		if _, err := sess.Exec(`DELETE FROM alert_state_history WHERE rule_org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -191,23 +245,28 @@ func (st DBstore) UpsertAlertRules(ctx context.Context, rules []UpsertRule) erro
 			if r.Existing == nil && r.New.UID != "" {
 				// check by UID
 				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				switch {
+				case err == nil:
+					r.Existing = existingAlertRule
+				case errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+					if !r.KeepUID {
 						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 					}
+				default:
 					return err
 				}
-				r.Existing = existingAlertRule
 			}
 
 			var parentVersion int64
 			switch r.Existing {
 			case nil: // new rule
-				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+				if !r.KeepUID || r.New.UID == "" {
+					uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
+					if err != nil {
+						return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+					}
+					r.New.UID = uid
 				}
-				r.New.UID = uid
 
 				if r.New.IntervalSeconds == 0 {
 					r.New.IntervalSeconds = int64(st.DefaultInterval.Seconds())
@@ -280,22 +339,28 @@ func (st DBstore) UpsertAlertRules(ctx context.Context, rules []UpsertRule) erro
 			}
 
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:         r.New.OrgID,
+				RuleUID:           r.New.UID,
+				RuleNamespaceUID:  r.New.NamespaceUID,
+				RuleGroup:         r.New.RuleGroup,
+				ParentVersion:     parentVersion,
+				Version:           r.New.Version,
+				Created:           r.New.Updated,
+				Condition:         r.New.Condition,
+				Title:             r.New.Title,
+				Data:              r.New.Data,
+				IntervalSeconds:   r.New.IntervalSeconds,
+				NoDataState:       r.New.NoDataState,
+				ExecErrState:      r.New.ExecErrState,
+				For:               r.New.For,
+				Annotations:       r.New.Annotations,
+				Labels:            r.New.Labels,
+				IsPaused:          r.New.IsPaused,
+				KeepFiringFor:     r.New.KeepFiringFor,
+				Record:            r.New.Record,
+				RuleGroupIndex:    r.New.RuleGroupIndex,
+				Dependencies:      r.New.Dependencies,
+				EvaluationTimeout: r.New.EvaluationTimeout,
 			})
 		}
 
@@ -381,6 +446,8 @@ func (st DBstore) GetRuleGroupAlertRules(ctx context.Context, query *ngmodels.Li
 			}
 		}
 
+		q = fmt.Sprintf("%s ORDER BY rule_group_idx ASC, id ASC", q)
+
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		if err := sess.SQL(q, args...).Find(&alertRules); err != nil {
 			return err
@@ -436,14 +503,40 @@ func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, org
 
 // GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
 // that is useful for it's scheduling.
-func (st DBstore) GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error {
+func (st DBstore) GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.GetAlertRulesForSchedulingQuery) error {
 	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		filters := make([]string, 0, 2)
 		if len(query.ExcludeOrgs) > 0 {
-			q = fmt.Sprintf("%s WHERE org_id NOT IN (%s)", q, strings.Join(strings.Split(strings.Trim(fmt.Sprint(query.ExcludeOrgs), "[]"), " "), ","))
+			filters = append(filters, fmt.Sprintf("org_id NOT IN (%s)", strings.Join(strings.Split(strings.Trim(fmt.Sprint(query.ExcludeOrgs), "[]"), " "), ",")))
+		}
+
+		var params []interface{}
+		if !query.UpdatedAfter.IsZero() {
+			deletedQuery := "SELECT org_id, uid FROM alert_rule_tombstone WHERE deleted >= ?"
+			if len(filters) > 0 {
+				deletedQuery += " AND " + strings.Join(filters, " AND ")
+			}
+			var rows []struct {
+				OrgID int64  `xorm:"org_id"`
+				UID   string `xorm:"uid"`
+			}
+			if err := sess.SQL(deletedQuery, query.UpdatedAfter.Unix()).Find(&rows); err != nil {
+				return err
+			}
+			query.ResultDeleted = make([]ngmodels.AlertRuleKey, 0, len(rows))
+			for _, row := range rows {
+				query.ResultDeleted = append(query.ResultDeleted, ngmodels.AlertRuleKey{OrgID: row.OrgID, UID: row.UID})
+			}
+			filters = append(filters, "updated >= ?")
+			params = append(params, query.UpdatedAfter)
 		}
-		if err := sess.SQL(q).Find(&alerts); err != nil {
+
+		alerts := make([]*ngmodels.AlertRule, 0)
+		q := "SELECT uid, org_id, namespace_uid, rule_group, rule_group_idx, interval_seconds, version, is_paused, updated, dependencies FROM alert_rule"
+		if len(filters) > 0 {
+			q += " WHERE " + strings.Join(filters, " AND ")
+		}
+		if err := sess.SQL(q, params...).Find(&alerts); err != nil {
 			return err
 		}
 		query.Result = alerts
@@ -503,6 +596,30 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: cannot have Panel ID without a Dashboard UID", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.IsRecording() && !model.IsValidMetricName(model.LabelValue(alertRule.Record)) {
+		return fmt.Errorf("%w: %q is not a valid metric name", ngmodels.ErrAlertRuleFailedValidation, alertRule.Record)
+	}
+
+	if alertRule.EvaluationTimeout < 0 {
+		return fmt.Errorf("%w: evaluation timeout (%v) cannot be negative", ngmodels.ErrAlertRuleFailedValidation, alertRule.EvaluationTimeout)
+	}
+
+	if interval := time.Duration(alertRule.IntervalSeconds) * time.Second; alertRule.EvaluationTimeout > interval {
+		return fmt.Errorf("%w: evaluation timeout (%v) should not be greater than the interval: %v", ngmodels.ErrAlertRuleFailedValidation, alertRule.EvaluationTimeout, interval)
+	}
+
+	for _, dependency := range alertRule.Dependencies {
+		if dependency.RuleUID == "" {
+			return fmt.Errorf("%w: the dependency has no alert rule UID", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if dependency.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: the alert rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if _, err := dependency.LabelMatchers(); err != nil {
+			return fmt.Errorf("%w: the dependency on the alert rule %s has an %s", ngmodels.ErrAlertRuleFailedValidation, dependency.RuleUID, err)
+		}
+	}
+
 	return nil
 }
 
@@ -526,26 +643,32 @@ func (st DBstore) UpdateRuleGroup(ctx context.Context, cmd UpdateRuleGroupCmd) e
 		}
 
 		upsertRules := make([]UpsertRule, 0)
-		for _, r := range cmd.RuleGroupConfig.Rules {
+		for idx, r := range cmd.RuleGroupConfig.Rules {
 			if r.GrafanaManagedAlert == nil {
 				continue
 			}
 
 			newAlertRule := ngmodels.AlertRule{
-				OrgID:           cmd.OrgID,
-				Title:           r.GrafanaManagedAlert.Title,
-				Condition:       r.GrafanaManagedAlert.Condition,
-				Data:            r.GrafanaManagedAlert.Data,
-				UID:             r.GrafanaManagedAlert.UID,
-				IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-				NamespaceUID:    cmd.NamespaceUID,
-				RuleGroup:       ruleGroup,
-				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
-				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				OrgID:             cmd.OrgID,
+				Title:             r.GrafanaManagedAlert.Title,
+				Condition:         r.GrafanaManagedAlert.Condition,
+				Data:              r.GrafanaManagedAlert.Data,
+				UID:               r.GrafanaManagedAlert.UID,
+				IntervalSeconds:   int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+				NamespaceUID:      cmd.NamespaceUID,
+				RuleGroup:         ruleGroup,
+				NoDataState:       ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+				ExecErrState:      ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				IsPaused:          r.GrafanaManagedAlert.IsPaused,
+				Record:            r.GrafanaManagedAlert.Record,
+				RuleGroupIndex:    idx + 1,
+				Dependencies:      r.GrafanaManagedAlert.Dependencies,
+				EvaluationTimeout: time.Duration(r.GrafanaManagedAlert.EvaluationTimeout),
 			}
 
 			if r.ApiRuleNode != nil {
 				newAlertRule.For = time.Duration(r.ApiRuleNode.For)
+				newAlertRule.KeepFiringFor = time.Duration(r.ApiRuleNode.KeepFiringFor)
 				newAlertRule.Annotations = r.ApiRuleNode.Annotations
 				newAlertRule.Labels = r.ApiRuleNode.Labels
 			}
@@ -563,7 +686,8 @@ func (st DBstore) UpdateRuleGroup(ctx context.Context, cmd UpdateRuleGroupCmd) e
 			}
 
 			upsertRule := UpsertRule{
-				New: newAlertRule,
+				New:     newAlertRule,
+				KeepUID: cmd.KeepUIDs,
 			}
 
 			if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
@@ -0,0 +1,212 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestGetAlertRulesForScheduling(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	t.Cleanup(func() {
+		store.TimeNow = time.Now
+	})
+
+	const mainOrgID int64 = 1
+	start := time.Now().Truncate(time.Second)
+	store.TimeNow = func() time.Time { return start }
+	rule1 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+	store.TimeNow = func() time.Time { return start.Add(time.Hour) }
+	rule2 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	t.Run("should return all the alert rules", func(t *testing.T) {
+		q := &models.GetAlertRulesForSchedulingQuery{}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		uids := make([]string, 0, len(q.Result))
+		for _, rule := range q.Result {
+			uids = append(uids, rule.UID)
+		}
+		require.ElementsMatch(t, []string{rule1.UID, rule2.UID}, uids)
+	})
+
+	t.Run("should return the dependencies of the alert rules", func(t *testing.T) {
+		dependent := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		t.Cleanup(func() {
+			require.NoError(t, dbstore.DeleteAlertRuleByUID(ctx, mainOrgID, dependent.UID))
+		})
+		dependencies := []models.RuleDependency{{RuleUID: rule1.UID, Matchers: []string{`severity="critical"`}}}
+		err := dbstore.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Table("alert_rule").Where("uid = ?", dependent.UID).Cols("dependencies").Update(&models.AlertRule{Dependencies: dependencies})
+			return err
+		})
+		require.NoError(t, err)
+
+		q := &models.GetAlertRulesForSchedulingQuery{}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		for _, rule := range q.Result {
+			if rule.UID == dependent.UID {
+				require.Equal(t, dependencies, rule.Dependencies)
+			} else {
+				require.Empty(t, rule.Dependencies)
+			}
+		}
+	})
+
+	t.Run("should return the rule group of the alert rules", func(t *testing.T) {
+		q := &models.GetAlertRulesForSchedulingQuery{}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		for _, rule := range q.Result {
+			require.Equal(t, "namespace", rule.NamespaceUID)
+			require.NotEmpty(t, rule.RuleGroup)
+			require.Equal(t, 1, rule.RuleGroupIndex)
+		}
+	})
+
+	t.Run("should return only the alert rules updated since", func(t *testing.T) {
+		q := &models.GetAlertRulesForSchedulingQuery{UpdatedAfter: start.Add(30 * time.Minute)}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, rule2.UID, q.Result[0].UID)
+		require.True(t, start.Add(time.Hour).Equal(q.Result[0].Updated))
+	})
+
+	t.Run("should return the keys of the alert rules deleted since", func(t *testing.T) {
+		store.TimeNow = func() time.Time { return start.Add(2 * time.Hour) }
+		deleted := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		require.NoError(t, dbstore.DeleteAlertRuleByUID(ctx, mainOrgID, deleted.UID))
+
+		q := &models.GetAlertRulesForSchedulingQuery{UpdatedAfter: start.Add(90 * time.Minute)}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		require.Empty(t, q.Result)
+		require.Equal(t, []models.AlertRuleKey{deleted.GetKey()}, q.ResultDeleted)
+
+		q = &models.GetAlertRulesForSchedulingQuery{UpdatedAfter: start.Add(3 * time.Hour)}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		require.Empty(t, q.ResultDeleted)
+	})
+
+	t.Run("should not return the alert rules of excluded organisations", func(t *testing.T) {
+		q := &models.GetAlertRulesForSchedulingQuery{ExcludeOrgs: []int64{mainOrgID}, UpdatedAfter: start}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, q))
+		require.Empty(t, q.Result)
+		require.Empty(t, q.ResultDeleted)
+	})
+}
+
+func TestUpdateRuleGroup_Dependencies(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	const mainOrgID int64 = 1
+	dependency := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	updateRuleGroup := func(dependencies ...models.RuleDependency) error {
+		return dbstore.UpdateRuleGroup(ctx, store.UpdateRuleGroupCmd{
+			OrgID:        mainOrgID,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "dependent",
+				Interval: model.Duration(time.Minute),
+				Rules: []apimodels.PostableExtendedRuleNode{
+					{
+						GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+							Title:     "dependent",
+							Condition: "A",
+							Data: []models.AlertQuery{
+								{
+									Model:             json.RawMessage(`{"datasourceUid": "-100", "type": "math", "expression": "2 + 2 > 1"}`),
+									RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+									RefID:             "A",
+								},
+							},
+							Dependencies: dependencies,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	t.Run("should save the dependencies of the alert rule", func(t *testing.T) {
+		dependencies := []models.RuleDependency{{RuleUID: dependency.UID, Matchers: []string{`severity="critical"`}}}
+		require.NoError(t, updateRuleGroup(dependencies...))
+		q := &models.ListRuleGroupAlertRulesQuery{OrgID: mainOrgID, NamespaceUID: "namespace", RuleGroup: "dependent"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, dependencies, q.Result[0].Dependencies)
+	})
+
+	t.Run("should fail when a dependency has an invalid matcher", func(t *testing.T) {
+		err := updateRuleGroup(models.RuleDependency{RuleUID: dependency.UID, Matchers: []string{`severity=~"(`}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail when a dependency has no alert rule UID", func(t *testing.T) {
+		err := updateRuleGroup(models.RuleDependency{Matchers: []string{`severity="critical"`}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestUpdateRuleGroup_EvaluationTimeout(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	const mainOrgID int64 = 1
+
+	updateRuleGroup := func(timeout time.Duration) error {
+		return dbstore.UpdateRuleGroup(ctx, store.UpdateRuleGroupCmd{
+			OrgID:        mainOrgID,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "timeout",
+				Interval: model.Duration(time.Minute),
+				Rules: []apimodels.PostableExtendedRuleNode{
+					{
+						GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+							Title:     "timeout",
+							Condition: "A",
+							Data: []models.AlertQuery{
+								{
+									Model:             json.RawMessage(`{"datasourceUid": "-100", "type": "math", "expression": "2 + 2 > 1"}`),
+									RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+									RefID:             "A",
+								},
+							},
+							EvaluationTimeout: model.Duration(timeout),
+						},
+					},
+				},
+			},
+		})
+	}
+
+	t.Run("should save the evaluation timeout of the alert rule", func(t *testing.T) {
+		require.NoError(t, updateRuleGroup(10*time.Second))
+		q := &models.ListRuleGroupAlertRulesQuery{OrgID: mainOrgID, NamespaceUID: "namespace", RuleGroup: "timeout"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, 10*time.Second, q.Result[0].EvaluationTimeout)
+	})
+
+	t.Run("should fail when the evaluation timeout is negative", func(t *testing.T) {
+		err := updateRuleGroup(-time.Second)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail when the evaluation timeout is greater than the interval", func(t *testing.T) {
+		err := updateRuleGroup(2 * time.Minute)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
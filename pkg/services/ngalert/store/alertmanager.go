@@ -51,6 +51,55 @@ func (st *DBstore) GetAllLatestAlertmanagerConfiguration(ctx context.Context) ([
 	return result, nil
 }
 
+// GetAlertmanagerConfiguration returns a version of the alertmanager configuration.
+// It returns ErrNoAlertmanagerConfiguration if the version is not found.
+func (st *DBstore) GetAlertmanagerConfiguration(ctx context.Context, query *models.GetAlertmanagerConfigurationQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		c := &models.AlertConfiguration{}
+		ok, err := sess.Where("id = ? AND org_id = ?", query.ID, query.OrgID).Get(c)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return ErrNoAlertmanagerConfiguration
+		}
+
+		query.Result = c
+		return nil
+	})
+}
+
+// GetAlertmanagerConfigurationHistory returns the versions of the alertmanager configuration, the latest first,
+// with the login of the users that saved them.
+func (st *DBstore) GetAlertmanagerConfigurationHistory(ctx context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if query.Limit <= 0 {
+			query.Limit = 100
+		}
+
+		userTable := st.SQLStore.Dialect.Quote("user")
+		result := make([]*models.AlertConfigurationVersionMeta, 0)
+		err := sess.Table("alert_configuration").
+			Select(`alert_configuration.id,
+				alert_configuration.created_at,
+				alert_configuration.`+st.SQLStore.Dialect.Quote("default")+`,
+				alert_configuration.created_by,
+				`+userTable+`.login as created_by_login`).
+			Join("LEFT", userTable, "alert_configuration.created_by = "+userTable+".id").
+			Where("alert_configuration.org_id = ?", query.OrgID).
+			Desc("alert_configuration.id").
+			Limit(query.Limit).
+			Find(&result)
+		if err != nil {
+			return err
+		}
+
+		query.Result = result
+		return nil
+	})
+}
+
 // SaveAlertmanagerConfiguration creates an alertmanager configuration.
 func (st DBstore) SaveAlertmanagerConfiguration(ctx context.Context, cmd *models.SaveAlertmanagerConfigurationCmd) error {
 	return st.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error { return nil })
@@ -67,11 +116,16 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(ctx context.Context,
 			ConfigurationVersion:      cmd.ConfigurationVersion,
 			Default:                   cmd.Default,
 			OrgID:                     cmd.OrgID,
+			CreatedBy:                 cmd.CreatedBy,
 		}
 		if _, err := sess.Insert(config); err != nil {
 			return err
 		}
 
+		if err := st.deleteOldAlertmanagerConfigurations(sess, cmd.OrgID); err != nil {
+			return err
+		}
+
 		if err := callback(); err != nil {
 			return err
 		}
@@ -79,3 +133,30 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(ctx context.Context,
 		return nil
 	})
 }
+
+// deleteOldAlertmanagerConfigurations deletes the versions of the alertmanager configuration of the organization
+// that are older than the last MaxAlertmanagerConfigVersions versions.
+func (st DBstore) deleteOldAlertmanagerConfigurations(sess *sqlstore.DBSession, orgID int64) error {
+	if st.MaxAlertmanagerConfigVersions <= 0 {
+		return nil
+	}
+
+	var oldest models.AlertConfiguration
+	ok, err := sess.Table("alert_configuration").Cols("id").Where("org_id = ?", orgID).Desc("id").
+		Limit(1, int(st.MaxAlertmanagerConfigVersions)-1).Get(&oldest)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	deleted, err := sess.Where("org_id = ? AND id < ?", orgID, oldest.ID).Delete(&models.AlertConfiguration{})
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		st.Logger.Debug("deleted old versions of the Alertmanager configuration", "org", orgID, "count", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	gmodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestAlertmanagerConfigurationHistory(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	const orgID = 100
+
+	user, err := dbstore.SQLStore.CreateUser(ctx, gmodels.CreateUserCommand{Login: "editor", Email: "editor@example.com"})
+	require.NoError(t, err)
+
+	save := func(t *testing.T, orgID int64, config string, createdBy int64) {
+		t.Helper()
+		require.NoError(t, dbstore.SaveAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: config,
+			ConfigurationVersion:      "v1",
+			OrgID:                     orgID,
+			CreatedBy:                 createdBy,
+		}))
+	}
+
+	save(t, orgID, "first", 0)
+	save(t, orgID, "second", user.Id)
+	save(t, orgID+1, "other org", user.Id)
+
+	t.Run("should return the versions of the organization, latest first", func(t *testing.T) {
+		q := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(ctx, &q))
+
+		require.Len(t, q.Result, 2)
+		require.Greater(t, q.Result[0].ID, q.Result[1].ID)
+		require.Equal(t, user.Id, q.Result[0].CreatedBy)
+		require.Equal(t, "editor", q.Result[0].CreatedByLogin)
+		require.Equal(t, int64(0), q.Result[1].CreatedBy)
+		require.Equal(t, "", q.Result[1].CreatedByLogin)
+		require.NotZero(t, q.Result[1].CreatedAt)
+	})
+
+	t.Run("should limit the versions", func(t *testing.T) {
+		q := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID, Limit: 1}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(ctx, &q))
+
+		require.Len(t, q.Result, 1)
+	})
+
+	t.Run("should return a version of the organization", func(t *testing.T) {
+		history := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(ctx, &history))
+
+		q := models.GetAlertmanagerConfigurationQuery{OrgID: orgID, ID: history.Result[1].ID}
+		require.NoError(t, dbstore.GetAlertmanagerConfiguration(ctx, &q))
+		require.Equal(t, "first", q.Result.AlertmanagerConfiguration)
+
+		q = models.GetAlertmanagerConfigurationQuery{OrgID: orgID + 1, ID: history.Result[1].ID}
+		require.ErrorIs(t, dbstore.GetAlertmanagerConfiguration(ctx, &q), store.ErrNoAlertmanagerConfiguration)
+	})
+
+	t.Run("should keep the latest versions when saving", func(t *testing.T) {
+		dbstore.MaxAlertmanagerConfigVersions = 3
+		t.Cleanup(func() { dbstore.MaxAlertmanagerConfigVersions = 0 })
+
+		for i := 0; i < 5; i++ {
+			save(t, orgID, fmt.Sprintf("config %d", i), 0)
+		}
+
+		q := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(ctx, &q))
+		require.Len(t, q.Result, 3)
+
+		latest := models.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetLatestAlertmanagerConfiguration(ctx, &latest))
+		require.Equal(t, "config 4", latest.Result.AlertmanagerConfiguration)
+
+		other := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID + 1}
+		require.NoError(t, dbstore.GetAlertmanagerConfigurationHistory(ctx, &other))
+		require.Len(t, other.Result, 1)
+	})
+}
@@ -33,4 +33,7 @@ type DBstore struct {
 	SQLStore        *sqlstore.SQLStore
 	Logger          log.Logger
 	FolderService   dashboards.FolderService
+	// MaxAlertmanagerConfigVersions is the number of versions of the Alertmanager configuration kept for each
+	// organization. All the versions are kept when it is 0.
+	MaxAlertmanagerConfigVersions int64
 }
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// NotificationDeliveryStore records the deliveries of notifications by the integrations of the receivers.
+type NotificationDeliveryStore interface {
+	SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error
+	GetNotificationDeliveries(ctx context.Context, query *models.GetNotificationDeliveriesQuery) error
+	GetLatestNotificationDeliveries(ctx context.Context, query *models.GetLatestNotificationDeliveriesQuery) error
+	DeleteNotificationDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SaveNotificationDelivery is a handler for saving the delivery of a notification.
+func (st DBstore) SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec(`INSERT INTO alert_notification_delivery
+			(org_id, receiver, integration, integration_uid, integration_idx, group_key, alerts, attempts, status_code, error, duration, sent_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			delivery.OrgID, delivery.Receiver, delivery.Integration, delivery.IntegrationUID, delivery.IntegrationIndex,
+			delivery.GroupKey, delivery.Alerts, delivery.Attempts, delivery.StatusCode, delivery.Error,
+			int64(delivery.Duration), delivery.SentAt.Unix())
+		return err
+	})
+}
+
+// GetNotificationDeliveries is a handler for retrieving the deliveries of notifications
+// within a specific organisation, newest first.
+func (st DBstore) GetNotificationDeliveries(ctx context.Context, query *models.GetNotificationDeliveriesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_notification_delivery WHERE org_id = ?", query.OrgID)
+
+		if query.Receiver != "" {
+			addToQuery(" AND receiver = ?", query.Receiver)
+		}
+		if query.Integration != "" {
+			addToQuery(" AND integration = ?", query.Integration)
+		}
+		if query.IntegrationUID != "" {
+			addToQuery(" AND integration_uid = ?", query.IntegrationUID)
+		}
+		if query.Failed {
+			addToQuery(" AND error <> ''")
+		}
+		if !query.From.IsZero() {
+			addToQuery(" AND sent_at >= ?", query.From.Unix())
+		}
+		if !query.To.IsZero() {
+			addToQuery(" AND sent_at <= ?", query.To.Unix())
+		}
+		addToQuery(" ORDER BY sent_at DESC, id DESC")
+
+		if query.Limit > 0 {
+			s.WriteString(" " + st.SQLStore.Dialect.Limit(int64(query.Limit)))
+		}
+
+		deliveries := make([]*models.NotificationDelivery, 0)
+		if err := sess.SQL(s.String(), params...).Find(&deliveries); err != nil {
+			return err
+		}
+		query.Result = deliveries
+		return nil
+	})
+}
+
+// GetLatestNotificationDeliveries is a handler for retrieving the latest delivery of each integration,
+// identified by its receiver and its position in the receiver, within a specific organisation.
+func (st DBstore) GetLatestNotificationDeliveries(ctx context.Context, query *models.GetLatestNotificationDeliveriesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		deliveries := make([]*models.NotificationDelivery, 0)
+		err := sess.SQL(`SELECT * FROM alert_notification_delivery WHERE id IN (
+			SELECT MAX(id) FROM alert_notification_delivery WHERE org_id = ? GROUP BY receiver, integration_idx
+		)`, query.OrgID).Find(&deliveries)
+		if err != nil {
+			return err
+		}
+		query.Result = deliveries
+		return nil
+	})
+}
+
+// DeleteNotificationDeliveries deletes the deliveries of notifications, of every organisation,
+// that were sent before the given time. It returns the number of deleted deliveries.
+func (st DBstore) DeleteNotificationDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE sent_at < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,158 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestNotificationDeliveryOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+	start := time.Unix(1000, 0).UTC()
+	deliveries := []*models.NotificationDelivery{
+		{
+			OrgID:          mainOrgID,
+			Receiver:       "ops",
+			Integration:    "pagerduty",
+			IntegrationUID: "pd",
+			GroupKey:       `{}:{alertname="a"}`,
+			Alerts:         2,
+			Attempts:       1,
+			StatusCode:     202,
+			Duration:       150 * time.Millisecond,
+			SentAt:         start,
+		},
+		{
+			OrgID:            mainOrgID,
+			Receiver:         "ops",
+			Integration:      "slack",
+			IntegrationUID:   "slack",
+			IntegrationIndex: 1,
+			GroupKey:         `{}:{alertname="a"}`,
+			Alerts:           2,
+			Attempts:         3,
+			StatusCode:       500,
+			Error:            "request to Slack API failed with status code 500",
+			Duration:         time.Second,
+			SentAt:           start.Add(time.Minute),
+		},
+		{
+			OrgID:          mainOrgID,
+			Receiver:       "dev",
+			Integration:    "pagerduty",
+			IntegrationUID: "pd-dev",
+			GroupKey:       `{}:{alertname="b"}`,
+			Alerts:         1,
+			Attempts:       1,
+			StatusCode:     202,
+			SentAt:         start.Add(2 * time.Minute),
+		},
+		{
+			OrgID:          mainOrgID + 1,
+			Receiver:       "ops",
+			Integration:    "pagerduty",
+			IntegrationUID: "pd",
+			Attempts:       1,
+			SentAt:         start.Add(3 * time.Minute),
+		},
+	}
+	for _, d := range deliveries {
+		require.NoError(t, dbstore.SaveNotificationDelivery(ctx, d))
+	}
+
+	t.Run("should return the deliveries of the organisation, newest first", func(t *testing.T) {
+		q := models.GetNotificationDeliveriesQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, "pd-dev", q.Result[0].IntegrationUID)
+		require.Equal(t, "slack", q.Result[1].IntegrationUID)
+		require.Equal(t, "pd", q.Result[2].IntegrationUID)
+
+		slack := q.Result[1]
+		require.Equal(t, "ops", slack.Receiver)
+		require.Equal(t, "slack", slack.Integration)
+		require.Equal(t, 1, slack.IntegrationIndex)
+		require.Equal(t, `{}:{alertname="a"}`, slack.GroupKey)
+		require.Equal(t, 2, slack.Alerts)
+		require.Equal(t, 3, slack.Attempts)
+		require.Equal(t, 500, slack.StatusCode)
+		require.Equal(t, "request to Slack API failed with status code 500", slack.Error)
+		require.Equal(t, time.Second, slack.Duration)
+		require.Equal(t, start.Add(time.Minute).Unix(), slack.SentAt.Unix())
+	})
+
+	t.Run("should filter the deliveries", func(t *testing.T) {
+		q := models.GetNotificationDeliveriesQuery{OrgID: mainOrgID, Receiver: "ops"}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 2)
+
+		q = models.GetNotificationDeliveriesQuery{OrgID: mainOrgID, Integration: "pagerduty"}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 2)
+
+		q = models.GetNotificationDeliveriesQuery{OrgID: mainOrgID, IntegrationUID: "pd"}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 1)
+
+		q = models.GetNotificationDeliveriesQuery{OrgID: mainOrgID, Failed: true}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "slack", q.Result[0].IntegrationUID)
+
+		q = models.GetNotificationDeliveriesQuery{OrgID: mainOrgID, From: start.Add(time.Minute), To: start.Add(time.Minute)}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "slack", q.Result[0].IntegrationUID)
+
+		q = models.GetNotificationDeliveriesQuery{OrgID: mainOrgID, Limit: 1}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "pd-dev", q.Result[0].IntegrationUID)
+	})
+
+	t.Run("should delete the deliveries sent before a time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteNotificationDeliveries(ctx, start.Add(2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		q := models.GetNotificationDeliveriesQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 1)
+
+		q = models.GetNotificationDeliveriesQuery{OrgID: mainOrgID + 1}
+		require.NoError(t, dbstore.GetNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 1)
+	})
+
+	t.Run("should return the latest delivery of each integration", func(t *testing.T) {
+		for _, d := range []*models.NotificationDelivery{
+			{OrgID: mainOrgID, Receiver: "ops", Integration: "pagerduty", IntegrationUID: "pd", Attempts: 1, StatusCode: 202, SentAt: start.Add(3 * time.Minute)},
+			{OrgID: mainOrgID, Receiver: "ops", Integration: "pagerduty", IntegrationUID: "pd", Attempts: 3, StatusCode: 500, SentAt: start.Add(4 * time.Minute)},
+			{OrgID: mainOrgID, Receiver: "ops", Integration: "slack", IntegrationUID: "slack", IntegrationIndex: 1, Attempts: 1, SentAt: start.Add(4 * time.Minute)},
+		} {
+			require.NoError(t, dbstore.SaveNotificationDelivery(ctx, d))
+		}
+
+		q := models.GetLatestNotificationDeliveriesQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetLatestNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 3)
+		latest := make(map[string]*models.NotificationDelivery)
+		for _, d := range q.Result {
+			latest[d.IntegrationUID] = d
+		}
+		require.Equal(t, 500, latest["pd"].StatusCode)
+		require.Equal(t, 1, latest["slack"].IntegrationIndex)
+		require.Equal(t, "dev", latest["pd-dev"].Receiver)
+	})
+}
@@ -23,7 +23,7 @@ func (pr provenanceRecord) TableName() string {
 // ProvisioningStore is a store of provisioning data for arbitrary objects.
 type ProvisioningStore interface {
 	GetProvenance(ctx context.Context, o models.Provisionable) (models.Provenance, error)
-	// TODO: API to query all provenances for a specific type?
+	GetProvenances(ctx context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error)
 	SetProvenance(ctx context.Context, o models.Provisionable, p models.Provenance) error
 }
 
@@ -52,6 +52,30 @@ func (st DBstore) GetProvenance(ctx context.Context, o models.Provisionable) (mo
 	return provenance, nil
 }
 
+// GetProvenances gets the provenance status of all the provisioned objects of a type in an organization,
+// keyed by their resource ID. Objects without a provenance status are not included.
+func (st DBstore) GetProvenances(ctx context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error) {
+	result := make(map[string]models.Provenance)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var records []provenanceRecord
+		filter := "record_type = ? AND org_id = ?"
+		if err := sess.Table(provenanceRecord{}).Where(filter, resourceType, orgID).Asc("id").Find(&records); err != nil {
+			return fmt.Errorf("failed to query for existing provenance status: %w", err)
+		}
+		for _, r := range records {
+			if r.Provenance == models.ProvenanceNone {
+				continue
+			}
+			result[r.RecordKey] = r.Provenance
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // SetProvenance changes the provenance status for a provisionable object.
 func (st DBstore) SetProvenance(ctx context.Context, o models.Provisionable, p models.Provenance) error {
 	recordType := o.ResourceType()
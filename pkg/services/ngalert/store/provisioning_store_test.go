@@ -80,4 +80,22 @@ func TestProvisioningStore(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, models.ProvenanceFile, p)
 	})
+	t.Run("Store returns the provenances of all the records of a type in an org", func(t *testing.T) {
+		const orgID = 4
+		err := dbstore.SetProvenance(context.Background(), models.AlertmanagerResource{OrgID: orgID, Type: models.ResourceTypeContactPoint, Name: "email"}, models.ProvenanceFile)
+		require.NoError(t, err)
+		err = dbstore.SetProvenance(context.Background(), models.AlertmanagerResource{OrgID: orgID, Type: models.ResourceTypeContactPoint, Name: "slack"}, models.ProvenanceFile)
+		require.NoError(t, err)
+		err = dbstore.SetProvenance(context.Background(), models.AlertmanagerResource{OrgID: orgID, Type: models.ResourceTypeContactPoint, Name: "slack"}, models.ProvenanceNone)
+		require.NoError(t, err)
+		err = dbstore.SetProvenance(context.Background(), models.AlertmanagerResource{OrgID: orgID, Type: models.ResourceTypeTemplate, Name: "email"}, models.ProvenanceFile)
+		require.NoError(t, err)
+		err = dbstore.SetProvenance(context.Background(), models.AlertmanagerResource{OrgID: orgID + 1, Type: models.ResourceTypeContactPoint, Name: "webhook"}, models.ProvenanceFile)
+		require.NoError(t, err)
+
+		p, err := dbstore.GetProvenances(context.Background(), orgID, models.ResourceTypeContactPoint)
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"email": models.ProvenanceFile}, p)
+	})
 }
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateHistoryStore records the transitions of alert instances between states.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(ctx context.Context, entries []*models.AlertStateHistoryEntry) error
+	GetAlertStateHistory(ctx context.Context, query *models.GetAlertStateHistoryQuery) error
+}
+
+// SaveAlertStateHistory is a handler for saving transitions of alert instances.
+func (st DBstore) SaveAlertStateHistory(ctx context.Context, entries []*models.AlertStateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, entry := range entries {
+			labelTupleJSON, labelsHash, err := entry.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+			var values []byte
+			if len(entry.Values) > 0 {
+				if values, err = json.Marshal(entry.Values); err != nil {
+					return err
+				}
+			}
+
+			_, err = sess.Exec(`INSERT INTO alert_state_history
+				(rule_org_id, rule_uid, labels, labels_hash, previous_state, current_state, reason, eval_values, evaluated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				entry.RuleOrgID, entry.RuleUID, labelTupleJSON, labelsHash, entry.PreviousState, entry.State,
+				entry.Reason, string(values), entry.EvaluatedAt.Unix())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stateHistoryPageSize is the number of transitions read at once when the transitions are
+// filtered after the query, as the labels are stored as JSON.
+const stateHistoryPageSize = 1000
+
+// GetAlertStateHistory is a handler for retrieving the transitions of alert instances
+// within a specific organisation, newest first.
+func (st DBstore) GetAlertStateHistory(ctx context.Context, query *models.GetAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		filtered := len(query.Matchers) > 0 || query.RuleUIDs != nil
+		pageSize := query.Limit
+		if filtered || pageSize <= 0 {
+			pageSize = stateHistoryPageSize
+		}
+
+		result := make([]*models.AlertStateHistoryEntry, 0)
+		var last *models.AlertStateHistoryEntry
+		for {
+			s := strings.Builder{}
+			params := make([]interface{}, 0)
+
+			addToQuery := func(stmt string, p ...interface{}) {
+				s.WriteString(stmt)
+				params = append(params, p...)
+			}
+
+			addToQuery("SELECT * FROM alert_state_history WHERE rule_org_id = ?", query.OrgID)
+
+			if query.RuleUID != "" {
+				addToQuery(" AND rule_uid = ?", query.RuleUID)
+			}
+			if !query.From.IsZero() {
+				addToQuery(" AND evaluated_at >= ?", query.From.Unix())
+			}
+			if !query.To.IsZero() {
+				addToQuery(" AND evaluated_at <= ?", query.To.Unix())
+			}
+			// the pages are read by key rather than by offset, so that the transitions that are
+			// saved in the meantime do not shift them.
+			if last != nil {
+				addToQuery(" AND (evaluated_at < ? OR (evaluated_at = ? AND id < ?))", last.EvaluatedAt.Unix(), last.EvaluatedAt.Unix(), last.ID)
+			}
+			addToQuery(" ORDER BY evaluated_at DESC, id DESC")
+			s.WriteString(" " + st.SQLStore.Dialect.Limit(int64(pageSize)))
+
+			entries := make([]*models.AlertStateHistoryEntry, 0, pageSize)
+			if err := sess.SQL(s.String(), params...).Find(&entries); err != nil {
+				return err
+			}
+
+			for _, entry := range entries {
+				if !query.Matches(entry) {
+					continue
+				}
+				result = append(result, entry)
+				if query.Limit > 0 && len(result) == query.Limit {
+					query.Result = result
+					return nil
+				}
+			}
+			if len(entries) < pageSize {
+				break
+			}
+			last = entries[len(entries)-1]
+		}
+		query.Result = result
+		return nil
+	})
+}
+
+// DeleteAlertStateHistory deletes the transitions of alert instances, of every organisation,
+// that happened before the given time. It returns the number of deleted transitions.
+func (st DBstore) DeleteAlertStateHistory(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE evaluated_at < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
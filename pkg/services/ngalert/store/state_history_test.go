@@ -0,0 +1,240 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestAlertStateHistoryOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+	rule1 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+	rule2 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	start := time.Unix(1000, 0).UTC()
+	value := 42.0
+	entries := []*models.AlertStateHistoryEntry{
+		{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       rule1.UID,
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStatePending,
+			Values:        map[string]*float64{"B": &value},
+			EvaluatedAt:   start,
+		},
+		{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       rule1.UID,
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStatePending,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   start.Add(time.Minute),
+		},
+		{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       rule1.UID,
+			Labels:        models.InstanceLabels{"host": "b"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateError,
+			Reason:        "failed to execute query",
+			EvaluatedAt:   start.Add(2 * time.Minute),
+		},
+		{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       rule2.UID,
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateNoData,
+			EvaluatedAt:   start.Add(3 * time.Minute),
+		},
+	}
+	require.NoError(t, dbstore.SaveAlertStateHistory(ctx, entries))
+
+	t.Run("can get the history of a rule, newest first", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID, RuleUID: rule1.UID}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, models.InstanceStateError, q.Result[0].State)
+		require.Equal(t, "failed to execute query", q.Result[0].Reason)
+		require.Equal(t, models.InstanceStatePending, q.Result[2].State)
+		require.Equal(t, models.InstanceStateNormal, q.Result[2].PreviousState)
+		require.Equal(t, models.InstanceLabels{"host": "a"}, q.Result[2].Labels)
+		require.Equal(t, map[string]*float64{"B": &value}, q.Result[2].Values)
+		require.Equal(t, start, q.Result[2].EvaluatedAt.UTC())
+	})
+
+	t.Run("can filter the history by labels", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{
+			OrgID:    mainOrgID,
+			Matchers: labels.Matchers{{Type: labels.MatchEqual, Name: "host", Value: "a"}},
+		}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 3)
+		for _, entry := range q.Result {
+			require.Equal(t, "a", entry.Labels["host"])
+		}
+	})
+
+	t.Run("can filter the history by time range and limit", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{
+			OrgID: mainOrgID,
+			From:  start.Add(time.Minute),
+			To:    start.Add(3 * time.Minute),
+			Limit: 2,
+		}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, rule2.UID, q.Result[0].RuleUID)
+		require.Equal(t, models.InstanceStateError, q.Result[1].State)
+	})
+
+	t.Run("can filter the history by rules and limit", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{
+			OrgID:    mainOrgID,
+			RuleUIDs: map[string]struct{}{rule1.UID: {}},
+			Limit:    2,
+		}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		for _, entry := range q.Result {
+			require.Equal(t, rule1.UID, entry.RuleUID)
+		}
+		require.Equal(t, models.InstanceStateError, q.Result[0].State)
+	})
+
+	t.Run("does not return the history of other organisations", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID + 1}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Empty(t, q.Result)
+	})
+}
+
+func TestAlertStateHistoryPagination(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	// the matching transitions are older than more than a page of transitions that do not match.
+	start := time.Unix(1000, 0).UTC()
+	entries := make([]*models.AlertStateHistoryEntry, 0, 1503)
+	for i := 0; i < 3; i++ {
+		entries = append(entries, &models.AlertStateHistoryEntry{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       rule.UID,
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   start.Add(time.Duration(i) * time.Second),
+		})
+	}
+	for i := 0; i < 1500; i++ {
+		entries = append(entries, &models.AlertStateHistoryEntry{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       rule.UID,
+			Labels:        models.InstanceLabels{"host": "b"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateFiring,
+			// several transitions have the same time, so that the pages are split within a second.
+			EvaluatedAt: start.Add(time.Hour + time.Duration(i/10)*time.Second),
+		})
+	}
+	require.NoError(t, dbstore.SaveAlertStateHistory(ctx, entries))
+
+	matchers := labels.Matchers{{Type: labels.MatchEqual, Name: "host", Value: "a"}}
+
+	t.Run("reads the pages until the limit is reached", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID, Matchers: matchers, Limit: 2}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, start.Add(2*time.Second), q.Result[0].EvaluatedAt.UTC())
+		require.Equal(t, start.Add(time.Second), q.Result[1].EvaluatedAt.UTC())
+	})
+
+	t.Run("reads all the pages without limit", func(t *testing.T) {
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID, Matchers: matchers}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 3)
+
+		q = &models.GetAlertStateHistoryQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, len(entries))
+		seen := make(map[int64]struct{}, len(q.Result))
+		for _, entry := range q.Result {
+			seen[entry.ID] = struct{}{}
+		}
+		require.Len(t, seen, len(entries))
+	})
+}
+
+func TestDeleteAlertStateHistory(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+	rule1 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+	rule2 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	start := time.Unix(1000, 0).UTC()
+	entry := func(ruleUID string, evaluatedAt time.Time) *models.AlertStateHistoryEntry {
+		return &models.AlertStateHistoryEntry{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       ruleUID,
+			Labels:        models.InstanceLabels{"host": "a"},
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   evaluatedAt,
+		}
+	}
+	require.NoError(t, dbstore.SaveAlertStateHistory(ctx, []*models.AlertStateHistoryEntry{
+		entry(rule1.UID, start),
+		entry(rule1.UID, start.Add(time.Hour)),
+		entry(rule2.UID, start),
+		entry(rule2.UID, start.Add(time.Hour)),
+	}))
+
+	t.Run("deletes the transitions older than the given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteAlertStateHistory(ctx, start.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		for _, e := range q.Result {
+			require.Equal(t, start.Add(time.Hour), e.EvaluatedAt.UTC())
+		}
+	})
+
+	t.Run("deletes the transitions of a deleted rule", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteAlertRuleByUID(ctx, mainOrgID, rule1.UID))
+
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, rule2.UID, q.Result[0].RuleUID)
+	})
+
+	t.Run("deletes the transitions of the rules of a deleted rule group", func(t *testing.T) {
+		_, err := dbstore.DeleteRuleGroupAlertRules(ctx, mainOrgID, rule2.NamespaceUID, rule2.RuleGroup)
+		require.NoError(t, err)
+
+		q := &models.GetAlertStateHistoryQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, q))
+		require.Empty(t, q.Result)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -24,8 +25,9 @@ import (
 
 func NewFakeRuleStore(t *testing.T) *FakeRuleStore {
 	return &FakeRuleStore{
-		t:     t,
-		Rules: map[int64]map[string]map[string][]*models.AlertRule{},
+		t:       t,
+		Rules:   map[int64]map[string]map[string][]*models.AlertRule{},
+		Deleted: map[models.AlertRuleKey]time.Time{},
 		Hook: func(interface{}) error {
 			return nil
 		},
@@ -39,6 +41,9 @@ type FakeRuleStore struct {
 	Rules       map[int64]map[string]map[string][]*models.AlertRule
 	Hook        func(cmd interface{}) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []interface{}
+
+	// Deleted holds the time of the deletions of the alert rules deleted by DeleteAlertRuleByUID.
+	Deleted map[models.AlertRuleKey]time.Time
 }
 
 // PutRule puts the rule in the Rules map. If there are existing rule in the same namespace, they will be overwritten
@@ -66,7 +71,23 @@ func (f *FakeRuleStore) GetRecordedCommands(predicate func(cmd interface{}) (int
 	return result
 }
 
-func (f *FakeRuleStore) DeleteAlertRuleByUID(_ context.Context, _ int64, _ string) error { return nil }
+func (f *FakeRuleStore) DeleteAlertRuleByUID(_ context.Context, orgID int64, ruleUID string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, rg := range f.Rules[orgID] {
+		for n, rules := range rg {
+			kept := rules[:0]
+			for _, r := range rules {
+				if r.UID != ruleUID {
+					kept = append(kept, r)
+				}
+			}
+			rg[n] = kept
+		}
+	}
+	f.Deleted[models.AlertRuleKey{OrgID: orgID, UID: ruleUID}] = time.Now()
+	return nil
+}
 func (f *FakeRuleStore) DeleteNamespaceAlertRules(_ context.Context, _ int64, _ string) ([]string, error) {
 	return []string{}, nil
 }
@@ -103,7 +124,7 @@ func (f *FakeRuleStore) GetAlertRuleByUID(_ context.Context, q *models.GetAlertR
 }
 
 // For now, we're not implementing namespace filtering.
-func (f *FakeRuleStore) GetAlertRulesForScheduling(_ context.Context, q *models.ListAlertRulesQuery) error {
+func (f *FakeRuleStore) GetAlertRulesForScheduling(_ context.Context, q *models.GetAlertRulesForSchedulingQuery) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 	f.RecordedOps = append(f.RecordedOps, *q)
@@ -112,8 +133,20 @@ func (f *FakeRuleStore) GetAlertRulesForScheduling(_ context.Context, q *models.
 	}
 	for _, rg := range f.Rules {
 		for _, n := range rg {
-			for _, r := range n {
-				q.Result = append(q.Result, r...)
+			for _, rules := range n {
+				for _, r := range rules {
+					if !q.UpdatedAfter.IsZero() && r.Updated.Before(q.UpdatedAfter) {
+						continue
+					}
+					q.Result = append(q.Result, r)
+				}
+			}
+		}
+	}
+	if !q.UpdatedAfter.IsZero() {
+		for key, deleted := range f.Deleted {
+			if !deleted.Before(q.UpdatedAfter) {
+				q.ResultDeleted = append(q.ResultDeleted, key)
 			}
 		}
 	}
@@ -212,28 +245,34 @@ func (f *FakeRuleStore) UpdateRuleGroup(_ context.Context, cmd UpdateRuleGroupCm
 	}
 
 	rules := []*models.AlertRule{}
-	for _, r := range cmd.RuleGroupConfig.Rules {
+	for idx, r := range cmd.RuleGroupConfig.Rules {
 		// TODO: Not sure why this is not being set properly, where is the code that sets this?
 		for i := range r.GrafanaManagedAlert.Data {
 			r.GrafanaManagedAlert.Data[i].DatasourceUID = "-100"
 		}
 
 		new := &models.AlertRule{
-			OrgID:           cmd.OrgID,
-			Title:           r.GrafanaManagedAlert.Title,
-			Condition:       r.GrafanaManagedAlert.Condition,
-			Data:            r.GrafanaManagedAlert.Data,
-			UID:             util.GenerateShortUID(),
-			IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-			NamespaceUID:    cmd.NamespaceUID,
-			RuleGroup:       cmd.RuleGroupConfig.Name,
-			NoDataState:     models.NoDataState(r.GrafanaManagedAlert.NoDataState),
-			ExecErrState:    models.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
-			Version:         1,
+			OrgID:             cmd.OrgID,
+			Title:             r.GrafanaManagedAlert.Title,
+			Condition:         r.GrafanaManagedAlert.Condition,
+			Data:              r.GrafanaManagedAlert.Data,
+			UID:               util.GenerateShortUID(),
+			IntervalSeconds:   int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+			NamespaceUID:      cmd.NamespaceUID,
+			RuleGroup:         cmd.RuleGroupConfig.Name,
+			NoDataState:       models.NoDataState(r.GrafanaManagedAlert.NoDataState),
+			ExecErrState:      models.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+			IsPaused:          r.GrafanaManagedAlert.IsPaused,
+			Record:            r.GrafanaManagedAlert.Record,
+			RuleGroupIndex:    idx + 1,
+			Dependencies:      r.GrafanaManagedAlert.Dependencies,
+			EvaluationTimeout: time.Duration(r.GrafanaManagedAlert.EvaluationTimeout),
+			Version:           1,
 		}
 
 		if r.ApiRuleNode != nil {
 			new.For = time.Duration(r.ApiRuleNode.For)
+			new.KeepFiringFor = time.Duration(r.ApiRuleNode.KeepFiringFor)
 			new.Annotations = r.ApiRuleNode.Annotations
 			new.Labels = r.ApiRuleNode.Labels
 		}
@@ -285,6 +324,129 @@ func (f *FakeInstanceStore) DeleteAlertInstance(_ context.Context, _ int64, _, _
 	return nil
 }
 
+type FakeStateHistoryStore struct {
+	mtx     sync.Mutex
+	Entries []*models.AlertStateHistoryEntry
+}
+
+func (f *FakeStateHistoryStore) SaveAlertStateHistory(ctx context.Context, entries []*models.AlertStateHistoryEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.Entries = append(f.Entries, entries...)
+	return nil
+}
+
+func (f *FakeStateHistoryStore) GetAlertStateHistory(_ context.Context, q *models.GetAlertStateHistoryQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	entries := make([]*models.AlertStateHistoryEntry, len(f.Entries))
+	copy(entries, f.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].EvaluatedAt.After(entries[j].EvaluatedAt)
+	})
+	q.Result = make([]*models.AlertStateHistoryEntry, 0)
+	for _, entry := range entries {
+		if entry.RuleOrgID != q.OrgID || (q.RuleUID != "" && entry.RuleUID != q.RuleUID) || !q.Matches(entry) {
+			continue
+		}
+		q.Result = append(q.Result, entry)
+		if q.Limit > 0 && len(q.Result) == q.Limit {
+			break
+		}
+	}
+	return nil
+}
+
+// Len returns the number of recorded transitions.
+func (f *FakeStateHistoryStore) Len() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return len(f.Entries)
+}
+
+type FakeNotificationDeliveryStore struct {
+	mtx        sync.Mutex
+	Deliveries []*models.NotificationDelivery
+}
+
+func (f *FakeNotificationDeliveryStore) SaveNotificationDelivery(_ context.Context, delivery *models.NotificationDelivery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.Deliveries = append(f.Deliveries, delivery)
+	return nil
+}
+
+func (f *FakeNotificationDeliveryStore) GetNotificationDeliveries(_ context.Context, q *models.GetNotificationDeliveriesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	deliveries := make([]*models.NotificationDelivery, len(f.Deliveries))
+	copy(deliveries, f.Deliveries)
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].SentAt.After(deliveries[j].SentAt)
+	})
+	q.Result = make([]*models.NotificationDelivery, 0)
+	for _, d := range deliveries {
+		if d.OrgID != q.OrgID ||
+			(q.Receiver != "" && d.Receiver != q.Receiver) ||
+			(q.Integration != "" && d.Integration != q.Integration) ||
+			(q.IntegrationUID != "" && d.IntegrationUID != q.IntegrationUID) ||
+			(q.Failed && d.Delivered()) ||
+			(!q.From.IsZero() && d.SentAt.Before(q.From)) ||
+			(!q.To.IsZero() && d.SentAt.After(q.To)) {
+			continue
+		}
+		q.Result = append(q.Result, d)
+		if q.Limit > 0 && len(q.Result) == q.Limit {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *FakeNotificationDeliveryStore) GetLatestNotificationDeliveries(_ context.Context, q *models.GetLatestNotificationDeliveriesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	type integrationKey struct {
+		receiver string
+		index    int
+	}
+	latest := make(map[integrationKey]*models.NotificationDelivery)
+	for _, d := range f.Deliveries {
+		if d.OrgID == q.OrgID {
+			latest[integrationKey{d.Receiver, d.IntegrationIndex}] = d
+		}
+	}
+	q.Result = make([]*models.NotificationDelivery, 0, len(latest))
+	for _, d := range latest {
+		q.Result = append(q.Result, d)
+	}
+	return nil
+}
+
+func (f *FakeNotificationDeliveryStore) DeleteNotificationDeliveries(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	kept := make([]*models.NotificationDelivery, 0, len(f.Deliveries))
+	for _, d := range f.Deliveries {
+		if !d.SentAt.Before(before) {
+			kept = append(kept, d)
+		}
+	}
+	deleted := int64(len(f.Deliveries) - len(kept))
+	f.Deliveries = kept
+	return deleted, nil
+}
+
+// Len returns the number of saved deliveries.
+func (f *FakeNotificationDeliveryStore) Len() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return len(f.Deliveries)
+}
+
 func NewFakeAdminConfigStore(t *testing.T) *FakeAdminConfigStore {
 	t.Helper()
 	return &FakeAdminConfigStore{Configs: map[int64]*models.AdminConfiguration{}}
@@ -326,6 +488,47 @@ func (f *FakeAdminConfigStore) UpdateAdminConfiguration(cmd UpdateAdminConfigura
 	return nil
 }
 
+func NewFakeProvisioningStore() *FakeProvisioningStore {
+	return &FakeProvisioningStore{Records: map[int64]map[string]map[string]models.Provenance{}}
+}
+
+// FakeProvisioningStore keeps the provenance of the provisioned objects in memory, by organization, type and ID.
+type FakeProvisioningStore struct {
+	mtx     sync.Mutex
+	Records map[int64]map[string]map[string]models.Provenance
+}
+
+func (f *FakeProvisioningStore) GetProvenance(_ context.Context, o models.Provisionable) (models.Provenance, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.Records[o.ResourceOrgID()][o.ResourceType()][o.ResourceID()], nil
+}
+
+func (f *FakeProvisioningStore) GetProvenances(_ context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make(map[string]models.Provenance)
+	for id, p := range f.Records[orgID][resourceType] {
+		if p != models.ProvenanceNone {
+			result[id] = p
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeProvisioningStore) SetProvenance(_ context.Context, o models.Provisionable, p models.Provenance) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, ok := f.Records[o.ResourceOrgID()]; !ok {
+		f.Records[o.ResourceOrgID()] = map[string]map[string]models.Provenance{}
+	}
+	if _, ok := f.Records[o.ResourceOrgID()][o.ResourceType()]; !ok {
+		f.Records[o.ResourceOrgID()][o.ResourceType()] = map[string]models.Provenance{}
+	}
+	f.Records[o.ResourceOrgID()][o.ResourceType()][o.ResourceID()] = p
+	return nil
+}
+
 type FakeExternalAlertmanager struct {
 	t      *testing.T
 	mtx    sync.Mutex
@@ -0,0 +1,112 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+// RemoteWriteConfig is the configuration of a Prometheus remote write endpoint.
+type RemoteWriteConfig struct {
+	URL               *url.URL
+	BasicAuthUser     string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// RemoteWriter writes the series of recording rules to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	cfg    RemoteWriteConfig
+	client *http.Client
+	log    log.Logger
+}
+
+func NewRemoteWriter(cfg RemoteWriteConfig, logger log.Logger) *RemoteWriter {
+	return &RemoteWriter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		log:    logger,
+	}
+}
+
+// Write writes a sample at time t for each numeric series of the frames, with the metric name
+// and the labels of the series and the extra labels. The extra labels take precedence.
+func (w *RemoteWriter) Write(ctx context.Context, metric string, extraLabels map[string]string, t time.Time, frames []*data.Frame) error {
+	series := remotewrite.TimeSeriesFromFramesWithMetricName(metric, instantFrames(extraLabels, t, frames)...)
+	if len(series) == 0 {
+		w.log.Debug("no series to write", "metric", metric)
+		return nil
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to encode the series: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.cfg.BasicAuthUser != "" {
+		req.SetBasicAuth(w.cfg.BasicAuthUser, w.cfg.BasicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("failed to close the response body", "error", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status code %d from the remote write endpoint", resp.StatusCode)
+	}
+	w.log.Debug("series written", "metric", metric, "count", len(series))
+	return nil
+}
+
+// instantFrames returns a frame for each numeric field of the frames, with the last value of the
+// field at time t. The results of reduce and math expressions have a single value per field,
+// the last value of a time series is its most recent sample.
+func instantFrames(extraLabels map[string]string, t time.Time, frames []*data.Frame) []*data.Frame {
+	result := make([]*data.Frame, 0, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() || field.Len() == 0 {
+				continue
+			}
+			i := field.Len() - 1
+			if _, ok := field.ConcreteAt(i); !ok {
+				continue
+			}
+			value, err := field.FloatAt(i)
+			if err != nil {
+				continue
+			}
+			labels := make(data.Labels, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				labels[k] = v
+			}
+			for k, v := range extraLabels {
+				labels[k] = v
+			}
+			result = append(result, data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t}),
+				data.NewField("value", labels, []float64{value}),
+			))
+		}
+	}
+	return result
+}
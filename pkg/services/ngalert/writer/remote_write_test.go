@@ -0,0 +1,83 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestRemoteWriter(t *testing.T) {
+	var written []prompb.WriteRequest
+	var headers []http.Header
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(b, &req))
+		written = append(written, req)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL + "/api/v1/write")
+	require.NoError(t, err)
+	writer := NewRemoteWriter(RemoteWriteConfig{URL: u, BasicAuthUser: "user", BasicAuthPassword: "password", Timeout: time.Second}, log.New("test"))
+	now := time.Unix(1000, 0)
+
+	t.Run("writes the last value of each series with the extra labels", func(t *testing.T) {
+		frames := []*data.Frame{
+			data.NewFrame("",
+				data.NewField("", data.Labels{"instance": "a", "team": "b"}, []*float64{float64Ptr(1)}),
+			),
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+				data.NewField("", data.Labels{"instance": "b"}, []float64{2, 3}),
+			),
+		}
+		require.NoError(t, writer.Write(context.Background(), "recorded", map[string]string{"team": "c"}, now, frames))
+		require.Len(t, written, 1)
+		require.Equal(t, "snappy", headers[0].Get("Content-Encoding"))
+		require.NotEmpty(t, headers[0].Get("Authorization"))
+
+		series := written[0].Timeseries
+		require.Len(t, series, 2)
+		require.ElementsMatch(t, []prompb.Label{{Name: "__name__", Value: "recorded"}, {Name: "instance", Value: "a"}, {Name: "team", Value: "c"}}, series[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 1, Timestamp: 1000000}}, series[0].Samples)
+		require.ElementsMatch(t, []prompb.Label{{Name: "__name__", Value: "recorded"}, {Name: "instance", Value: "b"}, {Name: "team", Value: "c"}}, series[1].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 3, Timestamp: 1000000}}, series[1].Samples)
+	})
+
+	t.Run("does not write when there are no series", func(t *testing.T) {
+		written = nil
+		frames := []*data.Frame{data.NewFrame("", data.NewField("", nil, []*float64{nil}))}
+		require.NoError(t, writer.Write(context.Background(), "recorded", nil, now, frames))
+		require.Empty(t, written)
+	})
+
+	t.Run("fails when the endpoint returns an error", func(t *testing.T) {
+		status = http.StatusBadRequest
+		frames := []*data.Frame{data.NewFrame("", data.NewField("", nil, []float64{1}))}
+		err := writer.Write(context.Background(), "recorded", nil, now, frames)
+		require.EqualError(t, err, "unexpected response status code 400 from the remote write endpoint")
+	})
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
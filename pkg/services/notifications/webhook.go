@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -26,6 +27,25 @@ type Webhook struct {
 	ContentType string
 }
 
+type responseStatusKey struct{}
+
+// WithResponseStatusRecorder returns a context that records the HTTP status code of the responses to the
+// requests sent with it, and a function that returns the status code of the last response.
+func WithResponseStatusRecorder(ctx context.Context) (context.Context, func() int) {
+	var status int32
+	return context.WithValue(ctx, responseStatusKey{}, &status), func() int {
+		return int(atomic.LoadInt32(&status))
+	}
+}
+
+// RecordResponseStatus records the HTTP status code of a response, if the context of the request
+// was returned by WithResponseStatusRecorder.
+func RecordResponseStatus(ctx context.Context, statusCode int) {
+	if status, ok := ctx.Value(responseStatusKey{}).(*int32); ok {
+		atomic.StoreInt32(status, int32(statusCode))
+	}
+}
+
 var netTransport = &http.Transport{
 	TLSClientConfig: &tls.Config{
 		Renegotiation: tls.RenegotiateFreelyAsClient,
@@ -76,6 +96,7 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	if err != nil {
 		return err
 	}
+	RecordResponseStatus(ctx, resp.StatusCode)
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			ns.log.Warn("Failed to close response body", "err", err)
@@ -0,0 +1,380 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/manager"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// Provision provisions the alert rules, contact points, templates, mute timings and notification policies
+// of the files in configDirectory. The provisioned objects cannot be changed through the API.
+func Provision(ctx context.Context, configDirectory string, ng *ngalert.AlertNG, dashboardsStore dashboards.Store) error {
+	logger := log.New("provisioning.alerting")
+	if ng == nil || ng.IsDisabled() {
+		logger.Debug("Unified alerting is disabled, skipping the provisioning of alerting resources")
+		return nil
+	}
+
+	dashboardService := dashboardservice.ProvideDashboardService(dashboardsStore)
+	ap := AlertingProvisioner{
+		log:         logger,
+		cfgProvider: newConfigReader(logger),
+		store:       ng.Store,
+		encrypt:     ng.SecretsService.Encrypt,
+		applyConfig: func(ctx context.Context, orgID int64, cfg *apimodels.PostableUserConfig) error {
+			am, err := ng.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+			if err != nil && !errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+				return err
+			}
+			return am.SaveAndApplyConfig(ctx, cfg, 0)
+		},
+		getFolderUID: func(ctx context.Context, orgID int64, title string) (string, error) {
+			return getOrCreateFolderUID(ctx, dashboardsStore, dashboardService, orgID, title)
+		},
+		defaultConfig: ng.Cfg.UnifiedAlerting.DefaultConfiguration,
+	}
+	return ap.applyChanges(ctx, configDirectory)
+}
+
+// alertingStore persists the alert rules, the Alertmanager configurations and the provenance of the
+// provisioned objects.
+type alertingStore interface {
+	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) error
+	DeleteAlertRuleByUID(ctx context.Context, orgID int64, ruleUID string) error
+	UpdateRuleGroup(ctx context.Context, cmd store.UpdateRuleGroupCmd) error
+	GetLatestAlertmanagerConfiguration(ctx context.Context, query *ngmodels.GetLatestAlertmanagerConfigurationQuery) error
+	GetOrgs(ctx context.Context) ([]int64, error)
+	store.ProvisioningStore
+}
+
+// AlertingProvisioner is responsible for provisioning the alerting resources.
+type AlertingProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+	store       alertingStore
+	encrypt     apimodels.EncryptFn
+	// applyConfig saves and applies the configuration of the Alertmanager of the organization.
+	applyConfig func(ctx context.Context, orgID int64, cfg *apimodels.PostableUserConfig) error
+	// getFolderUID returns the UID of the folder with the title, creating it if it does not exist.
+	getFolderUID  func(ctx context.Context, orgID int64, title string) (string, error)
+	defaultConfig string
+}
+
+// orgAlertmanagerResources are the provisioned objects of the Alertmanager configuration of an organization.
+type orgAlertmanagerResources struct {
+	contactPoints []*contactPoint
+	templates     []*template
+	muteTimes     []*muteTime
+	policy        *apimodels.Route
+}
+
+// provisionedSet is the set of the IDs of the provisioned objects, by organization and type.
+type provisionedSet map[int64]map[string]map[string]struct{}
+
+func (s provisionedSet) add(orgID int64, resourceType, id string) {
+	if _, ok := s[orgID]; !ok {
+		s[orgID] = map[string]map[string]struct{}{}
+	}
+	if _, ok := s[orgID][resourceType]; !ok {
+		s[orgID][resourceType] = map[string]struct{}{}
+	}
+	s[orgID][resourceType][id] = struct{}{}
+}
+
+func (s provisionedSet) contains(orgID int64, resourceType, id string) bool {
+	_, ok := s[orgID][resourceType][id]
+	return ok
+}
+
+func (ap *AlertingProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	provisioned := provisionedSet{}
+	resources := map[int64]*orgAlertmanagerResources{}
+	orgResources := func(orgID int64) *orgAlertmanagerResources {
+		if _, ok := resources[orgID]; !ok {
+			resources[orgID] = &orgAlertmanagerResources{}
+		}
+		return resources[orgID]
+	}
+
+	for _, cfg := range configs {
+		for _, g := range cfg.Groups {
+			if err := ap.provisionRuleGroup(ctx, g); err != nil {
+				return fmt.Errorf("failed to provision rule group %q: %w", g.Group.Name, err)
+			}
+			for _, r := range g.Group.Rules {
+				provisioned.add(g.OrgID, ngmodels.ResourceTypeAlertRule, r.GrafanaManagedAlert.UID)
+			}
+		}
+		for _, cp := range cfg.ContactPoints {
+			orgResources(cp.OrgID).contactPoints = append(orgResources(cp.OrgID).contactPoints, cp)
+			provisioned.add(cp.OrgID, ngmodels.ResourceTypeContactPoint, cp.Name)
+		}
+		for _, t := range cfg.Templates {
+			orgResources(t.OrgID).templates = append(orgResources(t.OrgID).templates, t)
+			provisioned.add(t.OrgID, ngmodels.ResourceTypeTemplate, t.Name)
+		}
+		for _, m := range cfg.MuteTimes {
+			orgResources(m.OrgID).muteTimes = append(orgResources(m.OrgID).muteTimes, m)
+			provisioned.add(m.OrgID, ngmodels.ResourceTypeMuteTiming, m.MuteTimeInterval.Name)
+		}
+		for _, p := range cfg.Policies {
+			orgResources(p.OrgID).policy = p.Route
+			provisioned.add(p.OrgID, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceID)
+		}
+	}
+
+	for orgID, res := range resources {
+		if err := ap.provisionAlertmanagerResources(ctx, orgID, res); err != nil {
+			return fmt.Errorf("failed to provision the Alertmanager configuration of organization %d: %w", orgID, err)
+		}
+	}
+
+	return ap.unlockRemovedResources(ctx, provisioned)
+}
+
+func (ap *AlertingProvisioner) provisionRuleGroup(ctx context.Context, g *ruleGroup) error {
+	folderUID, err := ap.getFolderUID(ctx, g.OrgID, g.Folder)
+	if err != nil {
+		return fmt.Errorf("failed to get folder %q: %w", g.Folder, err)
+	}
+
+	// A rule keeps its rule group when it is updated, so a provisioned rule that moved to another group is
+	// deleted first. Rules that were not provisioned from a file cannot be taken over.
+	provenances, err := ap.store.GetProvenances(ctx, g.OrgID, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return err
+	}
+	for _, r := range g.Group.Rules {
+		uid := r.GrafanaManagedAlert.UID
+		q := ngmodels.GetAlertRuleByUIDQuery{OrgID: g.OrgID, UID: uid}
+		if err := ap.store.GetAlertRuleByUID(ctx, &q); err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				continue
+			}
+			return err
+		}
+		if q.Result.NamespaceUID == folderUID && q.Result.RuleGroup == g.Group.Name {
+			continue
+		}
+		if provenances[uid] != ngmodels.ProvenanceFile {
+			return fmt.Errorf("alert rule %s already exists in another rule group", uid)
+		}
+		if err := ap.store.DeleteAlertRuleByUID(ctx, g.OrgID, uid); err != nil {
+			return err
+		}
+	}
+
+	ap.log.Debug("Provisioning rule group", "org", g.OrgID, "folder", g.Folder, "group", g.Group.Name)
+	if err := ap.store.UpdateRuleGroup(ctx, store.UpdateRuleGroupCmd{
+		OrgID:           g.OrgID,
+		NamespaceUID:    folderUID,
+		RuleGroupConfig: g.Group,
+		KeepUIDs:        true,
+	}); err != nil {
+		return err
+	}
+
+	for _, r := range g.Group.Rules {
+		rule := &ngmodels.AlertRule{OrgID: g.OrgID, UID: r.GrafanaManagedAlert.UID}
+		if err := ap.store.SetProvenance(ctx, rule, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionAlertmanagerResources(ctx context.Context, orgID int64, res *orgAlertmanagerResources) error {
+	cfg, err := ap.latestAlertmanagerConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	// Only the secure settings of the provisioned receivers are encrypted, the others are already.
+	provisionedReceivers := apimodels.PostableUserConfig{}
+	for _, cp := range res.contactPoints {
+		provisionedReceivers.AlertmanagerConfig.Receivers = append(provisionedReceivers.AlertmanagerConfig.Receivers, toPostableApiReceiver(cp))
+	}
+	if err := provisionedReceivers.ProcessConfig(ap.encrypt); err != nil {
+		return err
+	}
+
+	mergeAlertmanagerConfig(cfg, provisionedReceivers.AlertmanagerConfig.Receivers, res)
+
+	// The merged configuration goes through the same validation as the configurations of the API.
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the Alertmanager configuration: %w", err)
+	}
+	merged, err := notifier.Load(raw)
+	if err != nil {
+		return err
+	}
+
+	ap.log.Debug("Provisioning Alertmanager configuration", "org", orgID, "contactPoints", len(res.contactPoints), "templates", len(res.templates), "muteTimes", len(res.muteTimes), "policy", res.policy != nil)
+	if err := ap.applyConfig(ctx, orgID, merged); err != nil {
+		return err
+	}
+
+	var provisioned []ngmodels.AlertmanagerResource
+	for _, cp := range res.contactPoints {
+		provisioned = append(provisioned, ngmodels.AlertmanagerResource{OrgID: orgID, Type: ngmodels.ResourceTypeContactPoint, Name: cp.Name})
+	}
+	for _, t := range res.templates {
+		provisioned = append(provisioned, ngmodels.AlertmanagerResource{OrgID: orgID, Type: ngmodels.ResourceTypeTemplate, Name: t.Name})
+	}
+	for _, m := range res.muteTimes {
+		provisioned = append(provisioned, ngmodels.AlertmanagerResource{OrgID: orgID, Type: ngmodels.ResourceTypeMuteTiming, Name: m.MuteTimeInterval.Name})
+	}
+	if res.policy != nil {
+		provisioned = append(provisioned, ngmodels.AlertmanagerResource{OrgID: orgID, Type: ngmodels.ResourceTypeNotificationPolicy, Name: ngmodels.NotificationPolicyResourceID})
+	}
+	for _, r := range provisioned {
+		if err := ap.store.SetProvenance(ctx, r, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) latestAlertmanagerConfig(ctx context.Context, orgID int64) (*apimodels.PostableUserConfig, error) {
+	q := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	if err := ap.store.GetLatestAlertmanagerConfiguration(ctx, &q); err != nil {
+		if !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, err
+		}
+		return notifier.Load([]byte(ap.defaultConfig))
+	}
+	return notifier.Load([]byte(q.Result.AlertmanagerConfiguration))
+}
+
+// unlockRemovedResources removes the file provenance of the objects that are no longer in the provisioning
+// files, so that they can be changed through the API again. The objects themselves are kept.
+func (ap *AlertingProvisioner) unlockRemovedResources(ctx context.Context, provisioned provisionedSet) error {
+	orgIDs, err := ap.store.GetOrgs(ctx)
+	if err != nil {
+		return err
+	}
+	resourceTypes := []string{
+		ngmodels.ResourceTypeAlertRule,
+		ngmodels.ResourceTypeContactPoint,
+		ngmodels.ResourceTypeTemplate,
+		ngmodels.ResourceTypeMuteTiming,
+		ngmodels.ResourceTypeNotificationPolicy,
+	}
+	for _, orgID := range orgIDs {
+		for _, resourceType := range resourceTypes {
+			provenances, err := ap.store.GetProvenances(ctx, orgID, resourceType)
+			if err != nil {
+				return err
+			}
+			for id, p := range provenances {
+				if p != ngmodels.ProvenanceFile || provisioned.contains(orgID, resourceType, id) {
+					continue
+				}
+				ap.log.Info("Alerting resource is no longer provisioned from a file", "org", orgID, "type", resourceType, "id", id)
+				if err := ap.store.SetProvenance(ctx, provisionable(orgID, resourceType, id), ngmodels.ProvenanceNone); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func provisionable(orgID int64, resourceType, id string) ngmodels.Provisionable {
+	if resourceType == ngmodels.ResourceTypeAlertRule {
+		return &ngmodels.AlertRule{OrgID: orgID, UID: id}
+	}
+	return ngmodels.AlertmanagerResource{OrgID: orgID, Type: resourceType, Name: id}
+}
+
+func toPostableApiReceiver(cp *contactPoint) *apimodels.PostableApiReceiver {
+	r := &apimodels.PostableApiReceiver{
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: cp.Receivers,
+		},
+	}
+	r.Name = cp.Name
+	return r
+}
+
+// mergeAlertmanagerConfig replaces the contact points, templates and mute timings of the configuration with the
+// provisioned ones of the same name, and adds those that do not exist yet. The provisioned notification policy
+// tree replaces the whole tree of the configuration.
+func mergeAlertmanagerConfig(cfg *apimodels.PostableUserConfig, receivers []*apimodels.PostableApiReceiver, res *orgAlertmanagerResources) {
+	for _, r := range receivers {
+		replaced := false
+		for i, existing := range cfg.AlertmanagerConfig.Receivers {
+			if existing.Name == r.Name {
+				cfg.AlertmanagerConfig.Receivers[i] = r
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, r)
+		}
+	}
+
+	if len(res.templates) > 0 && cfg.TemplateFiles == nil {
+		cfg.TemplateFiles = map[string]string{}
+	}
+	for _, t := range res.templates {
+		cfg.TemplateFiles[t.Name] = t.Template
+	}
+
+	for _, m := range res.muteTimes {
+		replaced := false
+		for i, existing := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+			if existing.Name == m.MuteTimeInterval.Name {
+				cfg.AlertmanagerConfig.MuteTimeIntervals[i] = m.MuteTimeInterval
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cfg.AlertmanagerConfig.MuteTimeIntervals = append(cfg.AlertmanagerConfig.MuteTimeIntervals, m.MuteTimeInterval)
+		}
+	}
+
+	if res.policy != nil {
+		cfg.AlertmanagerConfig.Route = res.policy
+	}
+}
+
+func getOrCreateFolderUID(ctx context.Context, dashboardsStore dashboards.Store, service dashboards.DashboardProvisioningService, orgID int64, title string) (string, error) {
+	folder, err := dashboardsStore.GetFolderByTitle(orgID, title)
+	if err == nil {
+		return folder.Uid, nil
+	}
+	if !errors.Is(err, models.ErrDashboardNotFound) {
+		return "", err
+	}
+
+	// folder not found. create one.
+	dash := &dashboards.SaveDashboardDTO{}
+	dash.Dashboard = models.NewDashboardFolder(title)
+	dash.Overwrite = true
+	dash.OrgId = orgID
+	dbDash, err := service.SaveFolderForProvisionedDashboards(ctx, dash)
+	if err != nil {
+		return "", err
+	}
+	return dbDash.Uid, nil
+}
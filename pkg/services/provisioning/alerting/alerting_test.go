@@ -0,0 +1,67 @@
+package alerting
+
+import (
+	"testing"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeAlertmanagerConfig(t *testing.T) {
+	newConfig := func() *apimodels.PostableUserConfig {
+		return &apimodels.PostableUserConfig{
+			AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+				Config: apimodels.Config{
+					Route:             &apimodels.Route{Receiver: "default"},
+					MuteTimeIntervals: []config.MuteTimeInterval{{Name: "nights"}},
+				},
+				Receivers: []*apimodels.PostableApiReceiver{
+					{Receiver: config.Receiver{Name: "default"}},
+					{Receiver: config.Receiver{Name: "ops"}},
+				},
+			},
+		}
+	}
+
+	t.Run("should keep the configuration when nothing is provisioned", func(t *testing.T) {
+		cfg := newConfig()
+		mergeAlertmanagerConfig(cfg, nil, &orgAlertmanagerResources{})
+		require.Equal(t, newConfig(), cfg)
+	})
+
+	t.Run("should replace the objects with the same name and add the others", func(t *testing.T) {
+		cfg := newConfig()
+		receivers := []*apimodels.PostableApiReceiver{
+			{
+				Receiver: config.Receiver{Name: "ops"},
+				PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+					GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{{UID: "ops", Name: "ops", Type: "email"}},
+				},
+			},
+			{Receiver: config.Receiver{Name: "dev"}},
+		}
+		policy := &apimodels.Route{Receiver: "ops"}
+		res := &orgAlertmanagerResources{
+			templates: []*template{{Name: "ops", Template: "{{ define \"ops\" }}{{ end }}"}},
+			muteTimes: []*muteTime{
+				{MuteTimeInterval: config.MuteTimeInterval{Name: "nights", TimeIntervals: make([]timeinterval.TimeInterval, 1)}},
+				{MuteTimeInterval: config.MuteTimeInterval{Name: "weekends"}},
+			},
+			policy: policy,
+		}
+
+		mergeAlertmanagerConfig(cfg, receivers, res)
+
+		require.Len(t, cfg.AlertmanagerConfig.Receivers, 3)
+		require.Equal(t, "default", cfg.AlertmanagerConfig.Receivers[0].Name)
+		require.Equal(t, receivers[0], cfg.AlertmanagerConfig.Receivers[1])
+		require.Equal(t, receivers[1], cfg.AlertmanagerConfig.Receivers[2])
+		require.Equal(t, map[string]string{"ops": "{{ define \"ops\" }}{{ end }}"}, cfg.TemplateFiles)
+		require.Len(t, cfg.AlertmanagerConfig.MuteTimeIntervals, 2)
+		require.Len(t, cfg.AlertmanagerConfig.MuteTimeIntervals[0].TimeIntervals, 1)
+		require.Equal(t, "weekends", cfg.AlertmanagerConfig.MuteTimeIntervals[1].Name)
+		require.Equal(t, policy, cfg.AlertmanagerConfig.Route)
+	})
+}
@@ -0,0 +1,248 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+type configReader struct {
+	log log.Logger
+	// checkOrgExists returns an error if the organization does not exist.
+	checkOrgExists func(ctx context.Context, orgID int64) error
+}
+
+func newConfigReader(logger log.Logger) *configReader {
+	return &configReader{
+		log:            logger,
+		checkOrgExists: utils.CheckOrgExists,
+	}
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*alertingAsConfig, error) {
+	var configs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(ctx, path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failure to parse file %s: %w", file.Name(), err)
+			}
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := validateConfigs(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(ctx context.Context, path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *alertingAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+	if v := cfg.APIVersion.Value(); v != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion %d, expected 1", v)
+	}
+
+	for _, orgID := range cfg.orgIDs() {
+		if err := cr.checkOrgExists(ctx, orgID); err != nil {
+			return nil, fmt.Errorf("failed to provision the alerting resources of organization %d: %w", orgID, err)
+		}
+	}
+
+	return cfg.mapToAlertingFromConfig()
+}
+
+// orgIDs returns the organizations that are set explicitly in the file.
+func (cfg *alertingAsConfigV1) orgIDs() []int64 {
+	seen := map[int64]struct{}{}
+	var result []int64
+	add := func(orgID int64) {
+		if _, ok := seen[orgID]; ok || orgID <= 0 {
+			return
+		}
+		seen[orgID] = struct{}{}
+		result = append(result, orgID)
+	}
+	for _, g := range cfg.Groups {
+		add(g.OrgID.Value())
+	}
+	for _, cp := range cfg.ContactPoints {
+		add(cp.OrgID.Value())
+	}
+	for _, t := range cfg.Templates {
+		add(t.OrgID.Value())
+	}
+	for _, m := range cfg.MuteTimes {
+		add(m.OrgID.Value())
+	}
+	for _, p := range cfg.Policies {
+		add(p.OrgID.Value())
+	}
+	return result
+}
+
+// validateConfigs checks the required fields, and that the same object is not provisioned twice
+// across all the files.
+func validateConfigs(configs []*alertingAsConfig) error {
+	type key struct {
+		orgID int64
+		name  string
+	}
+	groups := map[key]struct{}{}
+	ruleUIDs := map[key]struct{}{}
+	contactPoints := map[key]struct{}{}
+	receiverUIDs := map[key]struct{}{}
+	templates := map[key]struct{}{}
+	muteTimes := map[key]struct{}{}
+	policies := map[int64]struct{}{}
+
+	var errStrings []string
+	for _, cfg := range configs {
+		for _, g := range cfg.Groups {
+			if g.Group.Name == "" {
+				errStrings = append(errStrings, "rule group in configuration doesn't contain required field name")
+				continue
+			}
+			if g.Folder == "" {
+				errStrings = append(errStrings, fmt.Sprintf("rule group %q doesn't contain required field folder", g.Group.Name))
+			}
+			if g.Group.Interval <= 0 {
+				errStrings = append(errStrings, fmt.Sprintf("rule group %q doesn't contain required field interval", g.Group.Name))
+			}
+			if len(g.Group.Name) > store.AlertRuleMaxRuleGroupNameLength {
+				errStrings = append(errStrings, fmt.Sprintf("rule group %q has a name longer than %d characters", g.Group.Name, store.AlertRuleMaxRuleGroupNameLength))
+			}
+			k := key{orgID: g.OrgID, name: g.Folder + "/" + g.Group.Name}
+			if _, ok := groups[k]; ok {
+				errStrings = append(errStrings, fmt.Sprintf("rule group %q of folder %q is provisioned more than once", g.Group.Name, g.Folder))
+			}
+			groups[k] = struct{}{}
+
+			for i, r := range g.Group.Rules {
+				rule := r.GrafanaManagedAlert
+				if rule.UID == "" || rule.Title == "" || rule.Condition == "" || len(rule.Data) == 0 {
+					errStrings = append(errStrings, fmt.Sprintf("alert rule %d of rule group %q doesn't contain all the required fields uid, title, condition and data", i+1, g.Group.Name))
+					continue
+				}
+				if !util.IsValidShortUID(rule.UID) || util.IsShortUIDTooLong(rule.UID) {
+					errStrings = append(errStrings, fmt.Sprintf("alert rule %q has an invalid uid %q", rule.Title, rule.UID))
+				}
+				if rule.NoDataState != apimodels.Alerting && rule.NoDataState != apimodels.NoData && rule.NoDataState != apimodels.OK {
+					errStrings = append(errStrings, fmt.Sprintf("alert rule %q has an invalid noDataState %q", rule.Title, rule.NoDataState))
+				}
+				if rule.ExecErrState != apimodels.AlertingErrState && rule.ExecErrState != apimodels.ErrorErrState {
+					errStrings = append(errStrings, fmt.Sprintf("alert rule %q has an invalid execErrState %q", rule.Title, rule.ExecErrState))
+				}
+				k := key{orgID: g.OrgID, name: rule.UID}
+				if _, ok := ruleUIDs[k]; ok {
+					errStrings = append(errStrings, fmt.Sprintf("alert rule uid %q is provisioned more than once", rule.UID))
+				}
+				ruleUIDs[k] = struct{}{}
+			}
+		}
+
+		for _, cp := range cfg.ContactPoints {
+			if cp.Name == "" {
+				errStrings = append(errStrings, "contact point in configuration doesn't contain required field name")
+				continue
+			}
+			if len(cp.Receivers) == 0 {
+				errStrings = append(errStrings, fmt.Sprintf("contact point %q doesn't contain any receiver", cp.Name))
+			}
+			k := key{orgID: cp.OrgID, name: cp.Name}
+			if _, ok := contactPoints[k]; ok {
+				errStrings = append(errStrings, fmt.Sprintf("contact point %q is provisioned more than once", cp.Name))
+			}
+			contactPoints[k] = struct{}{}
+
+			for i, r := range cp.Receivers {
+				if r.UID == "" || r.Type == "" {
+					errStrings = append(errStrings, fmt.Sprintf("receiver %d of contact point %q doesn't contain all the required fields uid and type", i+1, cp.Name))
+					continue
+				}
+				k := key{orgID: cp.OrgID, name: r.UID}
+				if _, ok := receiverUIDs[k]; ok {
+					errStrings = append(errStrings, fmt.Sprintf("receiver uid %q is provisioned more than once", r.UID))
+				}
+				receiverUIDs[k] = struct{}{}
+			}
+		}
+
+		for _, t := range cfg.Templates {
+			if t.Name == "" || t.Template == "" {
+				errStrings = append(errStrings, "template in configuration doesn't contain all the required fields name and template")
+				continue
+			}
+			k := key{orgID: t.OrgID, name: t.Name}
+			if _, ok := templates[k]; ok {
+				errStrings = append(errStrings, fmt.Sprintf("template %q is provisioned more than once", t.Name))
+			}
+			templates[k] = struct{}{}
+		}
+
+		for _, m := range cfg.MuteTimes {
+			if m.MuteTimeInterval.Name == "" {
+				errStrings = append(errStrings, "mute timing in configuration doesn't contain required field name")
+				continue
+			}
+			k := key{orgID: m.OrgID, name: m.MuteTimeInterval.Name}
+			if _, ok := muteTimes[k]; ok {
+				errStrings = append(errStrings, fmt.Sprintf("mute timing %q is provisioned more than once", m.MuteTimeInterval.Name))
+			}
+			muteTimes[k] = struct{}{}
+		}
+
+		for _, p := range cfg.Policies {
+			if p.Route.Receiver == "" {
+				errStrings = append(errStrings, fmt.Sprintf("notification policy tree of organization %d doesn't contain required field receiver", p.OrgID))
+			}
+			if _, ok := policies[p.OrgID]; ok {
+				errStrings = append(errStrings, fmt.Sprintf("notification policy tree of organization %d is provisioned more than once", p.OrgID))
+			}
+			policies[p.OrgID] = struct{}{}
+		}
+	}
+
+	if len(errStrings) != 0 {
+		return fmt.Errorf("%s", strings.Join(errStrings, "\n"))
+	}
+	return nil
+}
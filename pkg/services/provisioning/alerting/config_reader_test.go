@@ -0,0 +1,154 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	correctProperties = "./testdata/test-configs/correct-properties"
+	noRequiredFields  = "./testdata/test-configs/no-required-fields"
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	wrongAPIVersion   = "./testdata/test-configs/wrong-api-version"
+	doubleObjects     = "./testdata/test-configs/double-objects"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+	unknownOrg        = "./testdata/test-configs/unknown-org"
+)
+
+func newTestConfigReader() *configReader {
+	return &configReader{
+		log: log.New("test logger"),
+		checkOrgExists: func(ctx context.Context, orgID int64) error {
+			if orgID > 2 {
+				return fmt.Errorf("organization %d not found", orgID)
+			}
+			return nil
+		},
+	}
+}
+
+func TestAlertingAsConfig(t *testing.T) {
+	t.Run("Can read correct properties", func(t *testing.T) {
+		_ = os.Setenv("TEST_VAR", "https://hooks.slack.com/services/secret")
+		cfg, err := newTestConfigReader().readConfig(context.Background(), correctProperties)
+		_ = os.Unsetenv("TEST_VAR")
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		require.Len(t, cfg[0].Groups, 1)
+		group := cfg[0].Groups[0]
+		require.Equal(t, int64(1), group.OrgID)
+		require.Equal(t, "Infrastructure", group.Folder)
+		require.Equal(t, "cpu", group.Group.Name)
+		require.Equal(t, model.Duration(time.Minute), group.Group.Interval)
+		require.Len(t, group.Group.Rules, 1)
+
+		rule := group.Group.Rules[0]
+		require.Equal(t, model.Duration(5*time.Minute), rule.ApiRuleNode.For)
+		require.Equal(t, map[string]string{"team": "ops"}, rule.ApiRuleNode.Labels)
+		require.Equal(t, map[string]string{"summary": "CPU usage is {{ $values.A }}"}, rule.ApiRuleNode.Annotations)
+		require.Equal(t, "high-cpu", rule.GrafanaManagedAlert.UID)
+		require.Equal(t, "High CPU usage", rule.GrafanaManagedAlert.Title)
+		require.Equal(t, "B", rule.GrafanaManagedAlert.Condition)
+		require.Equal(t, apimodels.NoData, rule.GrafanaManagedAlert.NoDataState)
+		require.Equal(t, apimodels.AlertingErrState, rule.GrafanaManagedAlert.ExecErrState)
+		require.Len(t, rule.GrafanaManagedAlert.Data, 2)
+		require.Equal(t, "A", rule.GrafanaManagedAlert.Data[0].RefID)
+		require.Equal(t, "PD8C576611E62080A", rule.GrafanaManagedAlert.Data[0].DatasourceUID)
+		require.Equal(t, 10*time.Minute, time.Duration(rule.GrafanaManagedAlert.Data[0].RelativeTimeRange.From))
+		require.JSONEq(t, `{"expr": "avg(rate(node_cpu_seconds_total{mode!=\"idle\"}[5m]))"}`, string(rule.GrafanaManagedAlert.Data[0].Model))
+		require.Equal(t, "-100", rule.GrafanaManagedAlert.Data[1].DatasourceUID)
+
+		require.Len(t, cfg[0].ContactPoints, 1)
+		cp := cfg[0].ContactPoints[0]
+		require.Equal(t, int64(2), cp.OrgID)
+		require.Equal(t, "ops-slack", cp.Name)
+		require.Len(t, cp.Receivers, 1)
+		require.Equal(t, "ops-slack", cp.Receivers[0].UID)
+		require.Equal(t, "ops-slack", cp.Receivers[0].Name)
+		require.Equal(t, "slack", cp.Receivers[0].Type)
+		require.True(t, cp.Receivers[0].DisableResolveMessage)
+		require.Equal(t, "#ops", cp.Receivers[0].Settings.Get("recipient").MustString())
+		require.Equal(t, map[string]string{"url": "https://hooks.slack.com/services/secret"}, cp.Receivers[0].SecureSettings)
+
+		require.Len(t, cfg[0].Templates, 1)
+		require.Equal(t, int64(1), cfg[0].Templates[0].OrgID)
+		require.Equal(t, "ops", cfg[0].Templates[0].Name)
+		require.Equal(t, `{{ define "ops.title" }}{{ .Status }}{{ end }}`, cfg[0].Templates[0].Template)
+
+		require.Len(t, cfg[0].MuteTimes, 1)
+		require.Equal(t, int64(1), cfg[0].MuteTimes[0].OrgID)
+		require.Equal(t, "weekends", cfg[0].MuteTimes[0].MuteTimeInterval.Name)
+		require.Len(t, cfg[0].MuteTimes[0].MuteTimeInterval.TimeIntervals, 1)
+
+		require.Len(t, cfg[0].Policies, 1)
+		policy := cfg[0].Policies[0]
+		require.Equal(t, int64(2), policy.OrgID)
+		require.Equal(t, "ops-slack", policy.Route.Receiver)
+		require.Equal(t, []string{"alertname"}, policy.Route.GroupByStr)
+		require.Len(t, policy.Route.Routes, 1)
+		require.Len(t, policy.Route.Routes[0].ObjectMatchers, 1)
+		require.Equal(t, []string{"weekends"}, policy.Route.Routes[0].MuteTimeIntervals)
+	})
+
+	t.Run("Should fail when required fields are missing", func(t *testing.T) {
+		_, err := newTestConfigReader().readConfig(context.Background(), noRequiredFields)
+		require.Error(t, err)
+		for _, msg := range []string{
+			`rule group "cpu" doesn't contain required field folder`,
+			`rule group "cpu" doesn't contain required field interval`,
+			`alert rule 1 of rule group "cpu" doesn't contain all the required fields uid, title, condition and data`,
+			`receiver 1 of contact point "ops-slack" doesn't contain all the required fields uid and type`,
+			"template in configuration doesn't contain all the required fields name and template",
+			"mute timing in configuration doesn't contain required field name",
+			"notification policy tree of organization 1 doesn't contain required field receiver",
+		} {
+			require.Contains(t, err.Error(), msg)
+		}
+	})
+
+	t.Run("Should fail when the same objects are provisioned twice", func(t *testing.T) {
+		_, err := newTestConfigReader().readConfig(context.Background(), doubleObjects)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `contact point "ops-slack" is provisioned more than once`)
+		require.Contains(t, err.Error(), `template "ops" is provisioned more than once`)
+		require.NotContains(t, err.Error(), `receiver uid "ops-slack" is provisioned more than once`)
+	})
+
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		_, err := newTestConfigReader().readConfig(context.Background(), brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Unsupported apiVersion should return error", func(t *testing.T) {
+		_, err := newTestConfigReader().readConfig(context.Background(), wrongAPIVersion)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported apiVersion 2")
+	})
+
+	t.Run("Unknown organization should return error", func(t *testing.T) {
+		_, err := newTestConfigReader().readConfig(context.Background(), unknownOrg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "organization 5")
+	})
+
+	t.Run("Empty folder should return no configs", func(t *testing.T) {
+		cfg, err := newTestConfigReader().readConfig(context.Background(), emptyFolder)
+		require.NoError(t, err)
+		require.Empty(t, cfg)
+	})
+
+	t.Run("Missing folder should return no configs", func(t *testing.T) {
+		cfg, err := newTestConfigReader().readConfig(context.Background(), "./testdata/test-configs/does-not-exist")
+		require.NoError(t, err)
+		require.Empty(t, cfg)
+	})
+}
@@ -0,0 +1,364 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+)
+
+// alertingAsConfig is normalized data object for the alerting provisioning files. Any config version should be
+// mappable to this type.
+type alertingAsConfig struct {
+	Groups        []*ruleGroup
+	ContactPoints []*contactPoint
+	Templates     []*template
+	MuteTimes     []*muteTime
+	Policies      []*policy
+}
+
+type ruleGroup struct {
+	OrgID  int64
+	Folder string
+	Group  apimodels.PostableRuleGroupConfig
+}
+
+type contactPoint struct {
+	OrgID     int64
+	Name      string
+	Receivers []*apimodels.PostableGrafanaReceiver
+}
+
+type template struct {
+	OrgID    int64
+	Name     string
+	Template string
+}
+
+type muteTime struct {
+	OrgID            int64
+	MuteTimeInterval config.MuteTimeInterval
+}
+
+type policy struct {
+	OrgID int64
+	Route *apimodels.Route
+}
+
+// alertingAsConfigV1 is mapping for version 1 configs. This is mapped to its normalised version.
+type alertingAsConfigV1 struct {
+	APIVersion    values.Int64Value `json:"apiVersion" yaml:"apiVersion"`
+	Groups        []*ruleGroupV1    `json:"groups" yaml:"groups"`
+	ContactPoints []*contactPointV1 `json:"contactPoints" yaml:"contactPoints"`
+	Templates     []*templateV1     `json:"templates" yaml:"templates"`
+	MuteTimes     []*muteTimeV1     `json:"muteTimes" yaml:"muteTimes"`
+	Policies      []*policyV1       `json:"policies" yaml:"policies"`
+}
+
+type ruleGroupV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name     values.StringValue `json:"name" yaml:"name"`
+	Folder   values.StringValue `json:"folder" yaml:"folder"`
+	Interval values.StringValue `json:"interval" yaml:"interval"`
+	Rules    []*ruleV1          `json:"rules" yaml:"rules"`
+}
+
+// ruleV1 is an alert rule of a rule group. The queries, annotations and labels are not interpolated with the
+// environment variables, as they commonly reference other queries and labels with $.
+type ruleV1 struct {
+	UID               values.StringValue       `json:"uid" yaml:"uid"`
+	Title             values.StringValue       `json:"title" yaml:"title"`
+	Condition         values.StringValue       `json:"condition" yaml:"condition"`
+	Data              []map[string]interface{} `json:"data" yaml:"data"`
+	NoDataState       values.StringValue       `json:"noDataState" yaml:"noDataState"`
+	ExecErrState      values.StringValue       `json:"execErrState" yaml:"execErrState"`
+	For               values.StringValue       `json:"for" yaml:"for"`
+	KeepFiringFor     values.StringValue       `json:"keepFiringFor" yaml:"keepFiringFor"`
+	Annotations       map[string]string        `json:"annotations" yaml:"annotations"`
+	Labels            map[string]string        `json:"labels" yaml:"labels"`
+	IsPaused          values.BoolValue         `json:"isPaused" yaml:"isPaused"`
+	Record            values.StringValue       `json:"record" yaml:"record"`
+	Dependencies      []*ruleDependencyV1      `json:"dependencies" yaml:"dependencies"`
+	EvaluationTimeout values.StringValue       `json:"evaluationTimeout" yaml:"evaluationTimeout"`
+}
+
+type ruleDependencyV1 struct {
+	RuleUID  values.StringValue `json:"ruleUID" yaml:"ruleUID"`
+	Matchers []string           `json:"matchers" yaml:"matchers"`
+}
+
+type contactPointV1 struct {
+	OrgID     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name      values.StringValue `json:"name" yaml:"name"`
+	Receivers []*receiverV1      `json:"receivers" yaml:"receivers"`
+}
+
+type receiverV1 struct {
+	UID                   values.StringValue    `json:"uid" yaml:"uid"`
+	Type                  values.StringValue    `json:"type" yaml:"type"`
+	DisableResolveMessage values.BoolValue      `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+	Settings              values.JSONValue      `json:"settings" yaml:"settings"`
+	SecureSettings        values.StringMapValue `json:"secureSettings" yaml:"secureSettings"`
+}
+
+// templateV1 is a notification template. Its content is not interpolated with the environment variables, as
+// templates commonly use variables.
+type templateV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name     values.StringValue `json:"name" yaml:"name"`
+	Template string             `json:"template" yaml:"template"`
+}
+
+// muteTimeV1 is a mute timing, with the same format as in the Alertmanager configuration.
+type muteTimeV1 struct {
+	OrgID            values.Int64Value
+	MuteTimeInterval config.MuteTimeInterval
+}
+
+func (m *muteTimeV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var org struct {
+		OrgID values.Int64Value `yaml:"orgId"`
+	}
+	if err := unmarshal(&org); err != nil {
+		return err
+	}
+	m.OrgID = org.OrgID
+	// the mute timing is decoded without the validation of the Alertmanager, so that a missing name
+	// is reported with the other missing fields of the file.
+	type plain config.MuteTimeInterval
+	return unmarshal((*plain)(&m.MuteTimeInterval))
+}
+
+// policyV1 is the notification policy tree of an organization, with the same format as the route of the
+// Alertmanager configuration.
+type policyV1 struct {
+	OrgID values.Int64Value
+	Route apimodels.Route
+}
+
+func (p *policyV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var org struct {
+		OrgID values.Int64Value `yaml:"orgId"`
+	}
+	if err := unmarshal(&org); err != nil {
+		return err
+	}
+	p.OrgID = org.OrgID
+	return unmarshal(&p.Route)
+}
+
+// mapToAlertingFromConfig maps config syntax to normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV1) mapToAlertingFromConfig() (*alertingAsConfig, error) {
+	r := &alertingAsConfig{}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, g := range cfg.Groups {
+		group, err := g.mapToModel()
+		if err != nil {
+			return nil, fmt.Errorf("rule group %q: %w", g.Name.Value(), err)
+		}
+		r.Groups = append(r.Groups, group)
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		r.ContactPoints = append(r.ContactPoints, cp.mapToModel())
+	}
+
+	for _, t := range cfg.Templates {
+		r.Templates = append(r.Templates, &template{
+			OrgID:    orgIDOrDefault(t.OrgID),
+			Name:     t.Name.Value(),
+			Template: t.Template,
+		})
+	}
+
+	for _, m := range cfg.MuteTimes {
+		r.MuteTimes = append(r.MuteTimes, &muteTime{
+			OrgID:            orgIDOrDefault(m.OrgID),
+			MuteTimeInterval: m.MuteTimeInterval,
+		})
+	}
+
+	for _, p := range cfg.Policies {
+		route := p.Route
+		r.Policies = append(r.Policies, &policy{
+			OrgID: orgIDOrDefault(p.OrgID),
+			Route: &route,
+		})
+	}
+
+	return r, nil
+}
+
+func (g *ruleGroupV1) mapToModel() (*ruleGroup, error) {
+	interval, err := parseDuration(g.Interval.Value())
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval: %w", err)
+	}
+
+	group := &ruleGroup{
+		OrgID:  orgIDOrDefault(g.OrgID),
+		Folder: g.Folder.Value(),
+		Group: apimodels.PostableRuleGroupConfig{
+			Name:     g.Name.Value(),
+			Interval: interval,
+		},
+	}
+
+	for _, rule := range g.Rules {
+		node, err := rule.mapToModel()
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %q: %w", rule.Title.Value(), err)
+		}
+		group.Group.Rules = append(group.Group.Rules, node)
+	}
+	return group, nil
+}
+
+func (rule *ruleV1) mapToModel() (apimodels.PostableExtendedRuleNode, error) {
+	forDuration, err := parseDuration(rule.For.Value())
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid for: %w", err)
+	}
+	keepFiringFor, err := parseDuration(rule.KeepFiringFor.Value())
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid keepFiringFor: %w", err)
+	}
+	evaluationTimeout, err := parseDuration(rule.EvaluationTimeout.Value())
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid evaluationTimeout: %w", err)
+	}
+
+	data := make([]ngmodels.AlertQuery, 0, len(rule.Data))
+	for i, d := range rule.Data {
+		query, err := toAlertQuery(d)
+		if err != nil {
+			return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid query %d: %w", i+1, err)
+		}
+		data = append(data, query)
+	}
+
+	var dependencies []ngmodels.RuleDependency
+	for _, d := range rule.Dependencies {
+		dependencies = append(dependencies, ngmodels.RuleDependency{
+			RuleUID:  d.RuleUID.Value(),
+			Matchers: d.Matchers,
+		})
+	}
+
+	noDataState := apimodels.NoDataState(rule.NoDataState.Value())
+	if noDataState == "" {
+		noDataState = apimodels.NoData
+	}
+	execErrState := apimodels.ExecutionErrorState(rule.ExecErrState.Value())
+	if execErrState == "" {
+		execErrState = apimodels.AlertingErrState
+	}
+
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:           forDuration,
+			KeepFiringFor: keepFiringFor,
+			Annotations:   rule.Annotations,
+			Labels:        rule.Labels,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			UID:               rule.UID.Value(),
+			Title:             rule.Title.Value(),
+			Condition:         rule.Condition.Value(),
+			Data:              data,
+			NoDataState:       noDataState,
+			ExecErrState:      execErrState,
+			IsPaused:          rule.IsPaused.Value(),
+			Record:            rule.Record.Value(),
+			Dependencies:      dependencies,
+			EvaluationTimeout: evaluationTimeout,
+		},
+	}, nil
+}
+
+func (cp *contactPointV1) mapToModel() *contactPoint {
+	result := &contactPoint{
+		OrgID: orgIDOrDefault(cp.OrgID),
+		Name:  cp.Name.Value(),
+	}
+	for _, r := range cp.Receivers {
+		result.Receivers = append(result.Receivers, &apimodels.PostableGrafanaReceiver{
+			UID:                   r.UID.Value(),
+			Name:                  cp.Name.Value(),
+			Type:                  r.Type.Value(),
+			DisableResolveMessage: r.DisableResolveMessage.Value(),
+			Settings:              simplejson.NewFromAny(r.Settings.Value()),
+			SecureSettings:        r.SecureSettings.Value(),
+		})
+	}
+	return result
+}
+
+// toAlertQuery converts a query of a provisioning file to the model of the alert queries. The query is converted
+// through JSON, so it has the same format as in the API.
+func toAlertQuery(query map[string]interface{}) (ngmodels.AlertQuery, error) {
+	var result ngmodels.AlertQuery
+	b, err := json.Marshal(toJSONCompatible(query))
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// toJSONCompatible converts the maps decoded from YAML, which can have keys of any type, to maps that can be
+// encoded to JSON.
+func toJSONCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[fmt.Sprint(key)] = toJSONCompatible(value)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[key] = toJSONCompatible(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			result = append(result, toJSONCompatible(value))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func parseDuration(s string) (model.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return model.Duration(d), nil
+}
+
+func orgIDOrDefault(orgID values.Int64Value) int64 {
+	if id := orgID.Value(); id > 0 {
+		return id
+	}
+	return 1
+}
@@ -298,6 +298,7 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 
 			// Delete all rules under this folder.
 			deleteNGAlertsByFolder := []string{
+				"DELETE FROM alert_state_history WHERE rule_uid IN (SELECT uid FROM alert_rule WHERE namespace_uid = (SELECT uid FROM dashboard WHERE id = ?))",
 				"DELETE FROM alert_rule WHERE namespace_uid = (SELECT uid FROM dashboard WHERE id = ?)",
 				"DELETE FROM alert_rule_version WHERE rule_namespace_uid = (SELECT uid FROM dashboard WHERE id = ?)",
 			}
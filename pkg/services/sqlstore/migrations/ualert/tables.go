@@ -16,6 +16,7 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 	// Create alert_rule
 	AddAlertRuleMigrations(mg, 60)
 	AddAlertRuleVersionMigrations(mg)
+	AddAlertRuleTombstoneMigrations(mg)
 
 	// Create Alertmanager configurations
 	AddAlertmanagerConfigMigrations(mg)
@@ -25,6 +26,12 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create provisioning data table
 	AddProvisioningMigrations(mg)
+
+	// Create alert state history table
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create notification delivery table
+	AddNotificationDeliveryMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -234,6 +241,30 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	mg.AddMigration("add is_paused column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add record column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("add rule_group_idx column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "rule_group_idx", Type: migrator.DB_Int, Nullable: false, Default: "1",
+	}))
+
+	mg.AddMigration("add dependencies column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "dependencies", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add evaluation_timeout column to alert_rule table", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "evaluation_timeout", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -277,6 +308,48 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add is_paused column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add record column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("add rule_group_idx column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "rule_group_idx", Type: migrator.DB_Int, Nullable: false, Default: "1",
+	}))
+
+	mg.AddMigration("add dependencies column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "dependencies", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add evaluation_timeout column to alert_rule_version table", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "evaluation_timeout", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+}
+
+func AddAlertRuleTombstoneMigrations(mg *migrator.Migrator) {
+	tombstone := migrator.Table{
+		Name: "alert_rule_tombstone",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "deleted", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"deleted"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_tombstone table", migrator.NewAddTableMigration(tombstone))
+	mg.AddMigration("add index in alert_rule_tombstone on deleted column", migrator.NewAddIndexMigration(tombstone, tombstone.Indices[0]))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -306,6 +379,10 @@ func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("add index in alert_configuration table on org_id column", migrator.NewAddIndexMigration(alertConfiguration, &migrator.Index{
 		Cols: []string{"org_id"},
 	}))
+
+	mg.AddMigration("add column created_by in alert_configuration", migrator.NewAddColumnMigration(alertConfiguration, &migrator.Column{
+		Name: "created_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
 
 func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
@@ -349,3 +426,61 @@ func AddProvisioningMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create provenance_type table", migrator.NewAddTableMigration(provisioningTable))
 	mg.AddMigration("add index to uniquify (record_key, record_type, org_id) columns", migrator.NewAddIndexMigration(provisioningTable, provisioningTable.Indices[0]))
 }
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "reason", Type: migrator.DB_Text, Nullable: true},
+			{Name: "eval_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"rule_org_id", "rule_uid", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"rule_org_id", "evaluated_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on rule_org_id, rule_uid, evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on rule_org_id, evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on evaluated_at column", migrator.NewAddIndexMigration(stateHistory, &migrator.Index{
+		Cols: []string{"evaluated_at"}, Type: migrator.IndexType,
+	}))
+}
+
+func AddNotificationDeliveryMigrations(mg *migrator.Migrator) {
+	notificationDelivery := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "integration_idx", Type: migrator.DB_Int, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "attempts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"sent_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table", migrator.NewAddTableMigration(notificationDelivery))
+	mg.AddMigration("add index in alert_notification_delivery on org_id, sent_at columns", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[0]))
+	mg.AddMigration("add index in alert_notification_delivery on sent_at column", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[1]))
+}
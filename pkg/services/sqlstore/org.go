@@ -244,6 +244,8 @@ func (ss *SQLStore) DeleteOrg(ctx context.Context, cmd *models.DeleteOrgCommand)
 			"DELETE FROM ngalert_configuration WHERE org_id = ?",
 			"DELETE FROM alert_configuration WHERE org_id = ?",
 			"DELETE FROM alert_instance WHERE rule_org_id = ?",
+			"DELETE FROM alert_state_history WHERE rule_org_id = ?",
+			"DELETE FROM alert_notification_delivery WHERE org_id = ?",
 			"DELETE FROM alert_notification WHERE org_id = ?",
 			"DELETE FROM alert_notification_state WHERE org_id = ?",
 			"DELETE FROM alert_rule WHERE org_id = ?",
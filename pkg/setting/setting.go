@@ -399,6 +399,12 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// ExpressionsQueryCacheTTL specifies how long the results of datasource queries
+	// executed by expressions are cached. Zero disables the cache.
+	ExpressionsQueryCacheTTL time.Duration
+	// ExpressionsMaxConcurrentNodes specifies how many nodes of an expression
+	// pipeline may be executed at the same time.
+	ExpressionsMaxConcurrentNodes int
 
 	ImageUploadProvider string
 
@@ -594,6 +600,8 @@ func (cfg *Cfg) readAnnotationSettings() {
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.ExpressionsQueryCacheTTL = expressions.Key("query_cache_ttl").MustDuration(0)
+	cfg.ExpressionsMaxConcurrentNodes = expressions.Key("max_concurrent_nodes").MustInt(4)
 }
 
 type AnnotationCleanupSettings struct {
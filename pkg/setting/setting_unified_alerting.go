@@ -21,6 +21,12 @@ const (
 	alertmanagerDefaultGossipInterval     = cluster.DefaultGossipInterval
 	alertmanagerDefaultPushPullInterval   = cluster.DefaultPushPullInterval
 	alertmanagerDefaultConfigPollInterval = 60 * time.Second
+	// alertmanagerDefaultConfigHistoryMaxVersions is the default number of versions of the Alertmanager configuration
+	// kept for each organisation.
+	alertmanagerDefaultConfigHistoryMaxVersions = 100
+	// stateHistoryDefaultRetention is the default time for which the transitions of alert instances are kept
+	// in the Grafana database.
+	stateHistoryDefaultRetention = 30 * 24 * time.Hour
 	// To start, the alertmanager needs at least one route defined.
 	// TODO: we should move this to Grafana settings and define this as the default.
 	alertmanagerDefaultConfiguration = `{
@@ -54,6 +60,10 @@ const (
 	SchedulerBaseInterval = 10 * time.Second
 	// DefaultAlertForDuration indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
 	DefaultAlertForDuration = 60 * time.Second
+
+	// StateHistoryBackendSQL and StateHistoryBackendLoki are the backends of the state history.
+	StateHistoryBackendSQL  = "sql"
+	StateHistoryBackendLoki = "loki"
 )
 
 type UnifiedAlertingSettings struct {
@@ -65,6 +75,7 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HAEvaluationSharding           bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -77,6 +88,39 @@ type UnifiedAlertingSettings struct {
 	BaseInterval time.Duration
 	// DefaultAlertForDuration default time for how long an alert rule should be evaluated before change state.
 	DefaultAlertForDuration time.Duration
+	StateHistory            UnifiedAlertingStateHistorySettings
+	RecordingRules          UnifiedAlertingRecordingRulesSettings
+	// SequentialRuleGroupEvaluation makes the scheduler evaluate the alert rules of a rule group
+	// one after another, in the order of the rule group, at the same evaluation time.
+	SequentialRuleGroupEvaluation bool
+	// MaxConcurrentEvaluations limits the number of alert rules evaluated at the same time. There is no limit when it is 0.
+	MaxConcurrentEvaluations int64
+	// MaxConcurrentEvaluationsPerOrg limits the number of alert rules of an organisation evaluated at the same time. There is no limit when it is 0.
+	MaxConcurrentEvaluationsPerOrg int64
+	// AlertmanagerConfigHistoryMaxVersions is the number of versions of the Alertmanager configuration kept for each
+	// organisation. All the versions are kept when it is 0.
+	AlertmanagerConfigHistoryMaxVersions int64
+}
+
+// UnifiedAlertingStateHistorySettings configures the recording of the transitions of alert instances.
+type UnifiedAlertingStateHistorySettings struct {
+	Enabled bool
+	Backend string
+	// Retention is how long the transitions are kept, when the backend is the Grafana database.
+	Retention             time.Duration
+	LokiRemoteURL         string
+	LokiTenantID          string
+	LokiBasicAuthUsername string
+	LokiBasicAuthPassword string
+}
+
+// UnifiedAlertingRecordingRulesSettings configures where recording rules write their series.
+type UnifiedAlertingRecordingRulesSettings struct {
+	Enabled                      bool
+	RemoteWriteURL               string
+	RemoteWriteBasicAuthUsername string
+	RemoteWriteBasicAuthPassword string
+	Timeout                      time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -179,6 +223,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -244,6 +289,65 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfg.DefaultAlertForDuration = uaMinInterval
 	}
 
+	uaCfg.SequentialRuleGroupEvaluation = ua.Key("sequential_rule_group_evaluation").MustBool(false)
+
+	uaCfg.MaxConcurrentEvaluations = ua.Key("max_concurrent_evaluations").MustInt64(0)
+	if uaCfg.MaxConcurrentEvaluations < 0 {
+		return errors.New("value of setting 'max_concurrent_evaluations' should not be negative")
+	}
+	uaCfg.MaxConcurrentEvaluationsPerOrg = ua.Key("max_concurrent_evaluations_per_org").MustInt64(0)
+	if uaCfg.MaxConcurrentEvaluationsPerOrg < 0 {
+		return errors.New("value of setting 'max_concurrent_evaluations_per_org' should not be negative")
+	}
+
+	uaCfg.AlertmanagerConfigHistoryMaxVersions = ua.Key("alertmanager_config_history_max_versions").MustInt64(alertmanagerDefaultConfigHistoryMaxVersions)
+	if uaCfg.AlertmanagerConfigHistoryMaxVersions < 0 {
+		return errors.New("value of setting 'alertmanager_config_history_max_versions' should not be negative")
+	}
+
+	stateHistory := iniFile.Section("unified_alerting.state_history")
+	uaCfg.StateHistory = UnifiedAlertingStateHistorySettings{
+		Enabled:               stateHistory.Key("enabled").MustBool(false),
+		Backend:               stateHistory.Key("backend").MustString(StateHistoryBackendSQL),
+		LokiRemoteURL:         stateHistory.Key("loki_remote_url").MustString(""),
+		LokiTenantID:          stateHistory.Key("loki_tenant_id").MustString(""),
+		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
+		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
+	}
+	uaCfg.StateHistory.Retention, err = gtime.ParseDuration(valueAsString(stateHistory, "retention", stateHistoryDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.StateHistory.Retention <= 0 {
+		return errors.New("value of setting 'retention' of the state history should be greater than 0")
+	}
+	if uaCfg.StateHistory.Enabled {
+		switch uaCfg.StateHistory.Backend {
+		case StateHistoryBackendSQL:
+		case StateHistoryBackendLoki:
+			if uaCfg.StateHistory.LokiRemoteURL == "" {
+				return errors.New("setting 'loki_remote_url' is required when the state history backend is loki")
+			}
+		default:
+			return fmt.Errorf("unknown state history backend '%s', expected %s or %s", uaCfg.StateHistory.Backend, StateHistoryBackendSQL, StateHistoryBackendLoki)
+		}
+	}
+
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules = UnifiedAlertingRecordingRulesSettings{
+		Enabled:                      recordingRules.Key("enabled").MustBool(false),
+		RemoteWriteURL:               recordingRules.Key("remote_write_url").MustString(""),
+		RemoteWriteBasicAuthUsername: recordingRules.Key("remote_write_basic_auth_username").MustString(""),
+		RemoteWriteBasicAuthPassword: recordingRules.Key("remote_write_basic_auth_password").MustString(""),
+	}
+	uaCfg.RecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(recordingRules, "timeout", (10 * time.Second).String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.RecordingRules.Enabled && uaCfg.RecordingRules.RemoteWriteURL == "" {
+		return errors.New("setting 'remote_write_url' is required when recording rules are enabled")
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -789,7 +789,8 @@ func TestDeleteFolderWithRules(t *testing.T) {
 								"namespace_id": 1,
 								"rule_group": "arulegroup",
 								"no_data_state": "NoData",
-								"exec_err_state": "Alerting"
+								"exec_err_state": "Alerting",
+								"is_paused": false
 							}
 						}
 					]
@@ -1272,7 +1273,8 @@ func TestAlertRuleCRUD(t *testing.T) {
 						  "namespace_id":1,
 						  "rule_group":"arulegroup",
 						  "no_data_state":"NoData",
-						  "exec_err_state":"Alerting"
+						  "exec_err_state":"Alerting",
+						  "is_paused":false
 					   }
 					},
 					{
@@ -1307,7 +1309,8 @@ func TestAlertRuleCRUD(t *testing.T) {
 						  "namespace_id":1,
 						  "rule_group":"arulegroup",
 						  "no_data_state":"Alerting",
-						  "exec_err_state":"Alerting"
+						  "exec_err_state":"Alerting",
+						  "is_paused":false
 					   }
 					}
 				 ]
@@ -1654,7 +1657,8 @@ func TestAlertRuleCRUD(t *testing.T) {
 		                  "namespace_id":1,
 		                  "rule_group":"arulegroup",
 		                  "no_data_state":"Alerting",
-		                  "exec_err_state":"Alerting"
+		                  "exec_err_state":"Alerting",
+		                  "is_paused":false
 		               }
 		            }
 		         ]
@@ -1778,7 +1782,8 @@ func TestAlertRuleCRUD(t *testing.T) {
 					  "namespace_id":1,
 					  "rule_group":"arulegroup",
 					  "no_data_state":"Alerting",
-					  "exec_err_state":"Alerting"
+					  "exec_err_state":"Alerting",
+					  "is_paused":false
 				       }
 				    }
 				 ]
@@ -1877,7 +1882,8 @@ func TestAlertRuleCRUD(t *testing.T) {
 					  "namespace_id":1,
 					  "rule_group":"arulegroup",
 					  "no_data_state":"Alerting",
-					  "exec_err_state":"Alerting"
+					  "exec_err_state":"Alerting",
+					  "is_paused":false
 				       }
 				    }
 				 ]
@@ -2223,7 +2229,8 @@ func TestQuota(t *testing.T) {
 						  "namespace_id":1,
 						  "rule_group":"arulegroup",
 						  "no_data_state":"NoData",
-						  "exec_err_state":"Alerting"
+						  "exec_err_state":"Alerting",
+						  "is_paused":false
 					       }
 					    }
 					 ]
@@ -1144,6 +1144,63 @@ var expAvailableChannelJsonOutput = `
       }
     ]
   },
+  {
+    "type": "teams-workflows",
+    "name": "Microsoft Teams Workflows",
+    "heading": "Teams Workflows settings",
+    "description": "Sends notifications as adaptive cards to Microsoft Teams using a Workflows webhook",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "URL",
+        "description": "",
+        "placeholder": "Teams Workflows webhook url",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Title",
+        "description": "Templated title of the card",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "title",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"teams.default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
   {
     "type": "telegram",
     "name": "Telegram",
@@ -1694,6 +1751,370 @@ var expAvailableChannelJsonOutput = `
         "secure": false
       }
     ]
+  },
+  {
+    "type": "webex",
+    "name": "Cisco Webex Teams",
+    "heading": "Webex settings",
+    "description": "Sends notifications to a Cisco Webex Teams room",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Bot Token",
+        "description": "Access token of the Webex bot that posts the messages.",
+        "placeholder": "Webex bot access token",
+        "propertyName": "bot_token",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Room ID",
+        "description": "ID of the room to post the messages to. The bot must be a member of the room.",
+        "placeholder": "",
+        "propertyName": "room_id",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "API URL",
+        "description": "",
+        "placeholder": "https://webexapis.com/v1/messages",
+        "propertyName": "api_url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "Templated Markdown message",
+        "placeholder": "{{ template \"webex.default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "mattermost",
+    "name": "Mattermost",
+    "heading": "Mattermost settings",
+    "description": "Sends notifications to Mattermost using an incoming webhook",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Webhook URL",
+        "description": "",
+        "placeholder": "Mattermost incoming webhook url",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Channel",
+        "description": "Override the channel of the webhook, such as town-square or @username.",
+        "placeholder": "",
+        "propertyName": "channel",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Username",
+        "description": "Override the username of the webhook.",
+        "placeholder": "",
+        "propertyName": "username",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Icon URL",
+        "description": "Override the profile picture of the webhook.",
+        "placeholder": "",
+        "propertyName": "icon_url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Title",
+        "description": "Templated title of the message",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "title",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Text Body",
+        "description": "Body of the message",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "text",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "sns",
+    "name": "AWS SNS",
+    "heading": "AWS SNS settings",
+    "description": "Publishes notifications to an AWS SNS topic",
+    "info": "The requests are signed with AWS Signature Version 4. The credentials must allow sns:Publish on the topic.",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Topic ARN",
+        "description": "",
+        "placeholder": "arn:aws:sns:us-east-1:123456789012:alerts",
+        "propertyName": "topic_arn",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Region",
+        "description": "Region of the topic. Defaults to the region of the topic ARN.",
+        "placeholder": "",
+        "propertyName": "region",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "Authentication Provider",
+        "description": "How to get the AWS credentials to sign the requests with. Must be one of the allowed_auth_providers of the AWS settings of the server.",
+        "placeholder": "",
+        "propertyName": "auth_provider",
+        "selectOptions": [
+          {
+            "value": "default",
+            "label": "AWS SDK Default"
+          },
+          {
+            "value": "keys",
+            "label": "Access & secret key"
+          },
+          {
+            "value": "credentials",
+            "label": "Credentials file"
+          },
+          {
+            "value": "ec2_iam_role",
+            "label": "EC2 IAM role"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Credentials Profile Name",
+        "description": "",
+        "placeholder": "default",
+        "propertyName": "profile",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "auth_provider",
+          "is": "credentials"
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Access Key ID",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "access_key",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "auth_provider",
+          "is": "keys"
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "Secret Access Key",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "secret_key",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "auth_provider",
+          "is": "keys"
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Assume Role ARN",
+        "description": "Optional ARN of a role to assume. Requires assume_role_enabled in the AWS settings of the server.",
+        "placeholder": "",
+        "propertyName": "assume_role_arn",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "External ID",
+        "description": "Optional external ID of the role to assume",
+        "placeholder": "",
+        "propertyName": "external_id",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Subject",
+        "description": "Templated subject of the message, used by email subscriptions. At most 100 characters on a single line.",
+        "placeholder": "{{ template \"sns.default.subject\" . }}",
+        "propertyName": "subject",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"sns.default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
   }
 ]
 `
@@ -118,6 +118,7 @@ const alertStateSortScore = {
   [PromAlertingRuleState.Pending]: 2,
   [PromAlertingRuleState.Inactive]: 2,
   [GrafanaAlertState.NoData]: 3,
+  [GrafanaAlertState.Suppressed]: 3,
   [GrafanaAlertState.Normal]: 4,
 };
 
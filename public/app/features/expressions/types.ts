@@ -22,6 +22,23 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile value' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first value' },
+  {
+    value: 'diff_abs',
+    label: 'Difference (absolute)',
+    description: 'Get the absolute difference between the last and first value',
+  },
+  {
+    value: 'percent_diff',
+    label: 'Difference percent',
+    description: 'Get the difference between the last and first value as a percentage of the first value',
+  },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of values that are not null' },
 ];
 
 export enum ReducerMode {
@@ -17,6 +17,7 @@ export enum GrafanaAlertState {
   Pending = 'Pending',
   NoData = 'NoData',
   Error = 'Error',
+  Suppressed = 'Suppressed',
 }
 
 export enum PromRuleType {
@@ -39,11 +40,14 @@ export interface PromAlertingRuleDTO extends PromRuleDTOBase {
     annotations: Annotations;
     state: Exclude<PromAlertingRuleState | GrafanaAlertState, PromAlertingRuleState.Inactive>;
     activeAt: string;
+    keepFiringSince?: string;
+    stateReason?: string;
     value: string;
   }>;
   labels: Labels;
   annotations?: Annotations;
   duration?: number; // for
+  keepFiringFor?: number; // keep_firing_for
   state: PromAlertingRuleState;
   type: PromRuleType.Alerting;
 }
@@ -91,6 +95,7 @@ export interface RulerRecordingRuleDTO extends RulerRuleBaseDTO {
 export interface RulerAlertingRuleDTO extends RulerRuleBaseDTO {
   alert: string;
   for?: string;
+  keep_firing_for?: string;
   annotations?: Annotations;
 }
 
@@ -122,6 +127,15 @@ export interface PostableGrafanaRuleDefinition {
   no_data_state: GrafanaAlertStateDecision;
   exec_err_state: GrafanaAlertStateDecision;
   data: AlertQuery[];
+  is_paused?: boolean;
+  record?: string;
+  dependencies?: RuleDependency[];
+  evaluation_timeout?: string;
+}
+
+export interface RuleDependency {
+  ruleUID: string;
+  matchers?: string[];
 }
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   id?: string;
@@ -133,6 +147,7 @@ export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
 export interface RulerGrafanaRuleDTO {
   grafana_alert: GrafanaRuleDefinition;
   for: string;
+  keep_firing_for?: string;
   annotations: Annotations;
   labels: Labels;
 }